@@ -0,0 +1,92 @@
+/*
+Copyright © 2026 Flamingoose Software Inc <eng@flamingoose.ca>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/check"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const defaultBaselinePath = "uda-baseline.json"
+
+// baselineCmd represents the baseline command
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Manage the baseline used by uda check",
+	Long: `Manage the baseline used by uda check --baseline.
+
+A baseline records the metrics of every package so uda check only reports
+packages that are new or got worse, allowing thresholds to be adopted on a
+codebase that already violates them. The baseline records whether it was
+analyzed with --first-party and uda check refuses to compare against a
+baseline analyzed differently.`,
+}
+
+// baselineUpdateCmd represents the baseline update command
+var baselineUpdateCmd = &cobra.Command{
+	Use:   "update [path]",
+	Short: "Record the current metrics of every package in the baseline file",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		baselinePath, err := cmd.Flags().GetString("baseline")
+		if err != nil {
+			return err
+		}
+
+		if !cmd.Flags().Changed("baseline") && viper.GetString("check.baseline") != "" {
+			baselinePath = viper.GetString("check.baseline")
+		}
+
+		firstParty, err := cmd.Flags().GetBool("first-party")
+		if err != nil {
+			return err
+		}
+
+		if !cmd.Flags().Changed("first-party") {
+			firstParty = viper.GetBool("check.first-party")
+		}
+
+		metrics, g, err := analyzeForCheck(cmd, path, firstParty)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Create(baselinePath)
+		if err != nil {
+			return err
+		}
+
+		if err := check.NewBaseline(metrics, g, firstParty).Write(f); err != nil {
+			_ = f.Close()
+			return err
+		}
+
+		if err := f.Close(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Recorded %d packages in %s\n", len(metrics), baselinePath)
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(baselineCmd)
+	baselineCmd.AddCommand(baselineUpdateCmd)
+
+	baselineUpdateCmd.Flags().
+		String("baseline", defaultBaselinePath, "baseline file to write, defaults to check.baseline of the config")
+	baselineUpdateCmd.Flags().
+		Bool("first-party", false, "only record coupling between packages of the analyzed modules, defaults to check.first-party of the config")
+}
@@ -0,0 +1,66 @@
+/*
+Copyright © 2026 Flamingoose Software Inc <eng@flamingoose.ca>
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/ts"
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the parse cache",
+	Long: `Manage the parse cache of $XDG_CACHE_HOME/uda.
+
+What is extracted from every file is cached so later runs only parse the files
+that changed. Entries no run read for 30 days are removed once a day.`,
+}
+
+// cacheCleanCmd represents the cache clean command
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove the entries of the parse cache",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		olderThan, err := cmd.Flags().GetDuration("older-than")
+		if err != nil {
+			return err
+		}
+
+		dir, err := ts.DefaultCacheDir()
+		if err != nil {
+			return err
+		}
+
+		c := ts.NewCache(dir)
+
+		if olderThan == 0 {
+			if err := c.Clean(); err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "removed %s\n", dir)
+			return err
+		}
+
+		removed, err := c.Prune(olderThan)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "removed %d entries of %s\n", removed, dir)
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheCleanCmd)
+
+	cacheCleanCmd.Flags().
+		Duration("older-than", 0, "only remove the entries no run read for this long e.g. 168h, every entry when unset")
+}
@@ -0,0 +1,161 @@
+/*
+Copyright © 2026 Flamingoose Software Inc <eng@flamingoose.ca>
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/check"
+	"github.com/flamingoosesoftwareinc/uda/internal/dispatch"
+	"github.com/flamingoosesoftwareinc/uda/internal/graph"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var ErrThresholdsViolated = errors.New("coupling thresholds violated")
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check [path]",
+	Short: "Fail when packages violate the coupling thresholds",
+	Long: `Check every package against the coupling thresholds and exit non-zero
+listing every violating package.
+
+Thresholds are read from the config file (default is $HOME/.uda.yaml) and can be
+overridden by flags. A threshold of 0 is disabled.
+
+	check:
+	  max-instability: 0.8
+	  max-efferent-coupling: 20
+	  max-distance: 0.7
+	  forbid-cycles: true
+	  first-party: true
+	  baseline: uda-baseline.json
+
+With a baseline only packages that are new or got worse since the baseline was
+recorded are reported. The baseline is created with uda baseline update.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		thresholds := check.Thresholds{
+			MaxInstability:      viper.GetFloat64("check.max-instability"),
+			MaxEfferentCoupling: viper.GetFloat64("check.max-efferent-coupling"),
+			MaxDistance:         viper.GetFloat64("check.max-distance"),
+			ForbidCycles:        viper.GetBool("check.forbid-cycles"),
+		}
+		slog.DebugContext(ctx, "checking thresholds", "thresholds", thresholds)
+
+		firstParty := viper.GetBool("check.first-party")
+
+		metrics, g, err := analyzeForCheck(cmd, path, firstParty)
+		if err != nil {
+			return err
+		}
+
+		violations := check.Run(thresholds, metrics, g)
+
+		if baselinePath := viper.GetString("check.baseline"); baselinePath != "" {
+			baseline, err := readBaseline(baselinePath)
+			if err != nil {
+				return err
+			}
+
+			if err := baseline.Matches(firstParty); err != nil {
+				return err
+			}
+
+			violations = baseline.Regressions(violations)
+		}
+
+		if len(violations) == 0 {
+			return nil
+		}
+
+		for _, v := range violations {
+			fmt.Fprintln(cmd.OutOrStdout(), v)
+		}
+
+		return fmt.Errorf("%w: %d violations", ErrThresholdsViolated, len(violations))
+	},
+}
+
+// analyzeForCheck analyzes path the same way for checks and baselines so they can be compared
+func analyzeForCheck(
+	cmd *cobra.Command,
+	path string,
+	firstParty bool,
+) ([]analyzer.Metrics, graph.Graph, error) {
+	ctx := cmd.Context()
+	dirFS := os.DirFS(path)
+
+	opts := fileOptions()
+	if firstParty {
+		opts = append(opts, dispatch.WithOrigins(analyzer.FirstParty, analyzer.Workspace))
+	}
+
+	a := dispatch.Dispatcher(opts...)
+
+	r, err := a.AnalyzeAll(ctx, dirFS)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return r.Metrics, graph.New(r.Imports), nil
+}
+
+func readBaseline(path string) (check.Baseline, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return check.Baseline{}, fmt.Errorf(
+			"baseline %s does not exist, create it with `uda baseline update`: %w",
+			path,
+			err,
+		)
+	}
+
+	if err != nil {
+		return check.Baseline{}, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	return check.ReadBaseline(f)
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().Float64("max-instability", 0, "maximum instability of a package")
+	checkCmd.Flags().Float64("max-efferent-coupling", 0, "maximum efferent coupling of a package")
+	checkCmd.Flags().Float64("max-distance", 0, "maximum distance from the main sequence of a package")
+	checkCmd.Flags().Bool("forbid-cycles", false, "fail on import cycles between packages")
+	checkCmd.Flags().
+		Bool("first-party", false, "only check coupling between packages of the analyzed modules")
+	checkCmd.Flags().
+		String("baseline", "", "only report packages that got worse since this baseline file")
+
+	for _, name := range []string{
+		"max-instability",
+		"max-efferent-coupling",
+		"max-distance",
+		"forbid-cycles",
+		"first-party",
+		"baseline",
+	} {
+		if err := viper.BindPFlag("check."+name, checkCmd.Flags().Lookup(name)); err != nil {
+			slog.Error("failed to bind", "error", err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright © 2026 Flamingoose Software Inc <eng@flamingoose.ca>
+*/
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/dispatch"
+	"github.com/flamingoosesoftwareinc/uda/internal/graph"
+	"github.com/spf13/cobra"
+)
+
+// cyclesCmd represents the cycles command
+var cyclesCmd = &cobra.Command{
+	Use:   "cycles [path]",
+	Short: "Report import cycles between packages",
+	Long: `Report import cycles between the analyzed packages.
+
+The package dependency graph is split into strongly connected components using
+Tarjan's algorithm. Every cycle of a component is reported as a chain of packages
+along with the files and lines of the imports creating each edge.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		dirFS := os.DirFS(path)
+
+		maxCycles, err := cmd.Flags().GetInt("max-cycles")
+		if err != nil {
+			return err
+		}
+
+		a := dispatch.Dispatcher(fileOptions()...)
+
+		r, err := a.AnalyzeAll(ctx, dirFS)
+		if err != nil {
+			return err
+		}
+
+		sources := edgeSources(r.Sources)
+		out := cmd.OutOrStdout()
+
+		g := graph.New(r.Imports)
+		components := g.StronglyConnectedComponents()
+		if len(components) == 0 {
+			fmt.Fprintln(out, "No cycles found")
+			return nil
+		}
+
+		for n, component := range components {
+			fmt.Fprintf(out, "Component %d: %d packages\n", n+1, len(component))
+
+			for _, cycle := range g.Cycles(component, maxCycles) {
+				fmt.Fprintf(out, "\tCycle: %v\n", cycle)
+
+				for _, edge := range cycle.Edges() {
+					for _, l := range sources[edge] {
+						fmt.Fprintf(out, "\t\t%v -> %v\t%v\n", edge[0], edge[1], l)
+					}
+				}
+			}
+		}
+
+		return nil
+	},
+}
+
+// edgeSources indexes the locations of the imports of every package by the edge they create
+// e.g. {"a", "b"} for every import of package b in package a
+func edgeSources(sources analyzer.ImportSources) map[[2]analyzer.Package][]analyzer.Location {
+	edges := make(map[[2]analyzer.Package][]analyzer.Location)
+
+	for from, imports := range sources {
+		for imp, locations := range imports {
+			edge := [2]analyzer.Package{from, imp.Package()}
+			edges[edge] = append(edges[edge], locations...)
+		}
+	}
+
+	for _, locations := range edges {
+		slices.SortFunc(locations, func(a, b analyzer.Location) int {
+			return cmp.Or(strings.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line))
+		})
+	}
+
+	return edges
+}
+
+func init() {
+	rootCmd.AddCommand(cyclesCmd)
+
+	cyclesCmd.Flags().
+		Int("max-cycles", 100, "maximum number of cycles reported per component, 0 is unlimited")
+}
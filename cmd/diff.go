@@ -0,0 +1,121 @@
+/*
+Copyright © 2026 Flamingoose Software Inc <eng@flamingoose.ca>
+*/
+package cmd
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"path"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/diff"
+	"github.com/flamingoosesoftwareinc/uda/internal/dispatch"
+	"github.com/flamingoosesoftwareinc/uda/internal/gitfs"
+	"github.com/flamingoosesoftwareinc/uda/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <base-rev> <head-rev> [path]",
+	Short: "Compare the dependencies and metrics of two git revisions",
+	Long: `Compare the dependencies and metrics of two git revisions.
+
+Both revisions are read directly from the local git object store so no
+checkout is needed. Added and removed edges between packages are reported
+along with the metric changes of every added, removed or changed package.
+
+	uda diff main HEAD
+	uda diff HEAD~1 HEAD --format json`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		dir := "."
+		if len(args) == 3 {
+			dir = args[2]
+		}
+
+		formatFlag, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		format, err := diff.ParseFormat(formatFlag)
+		if err != nil {
+			return err
+		}
+
+		firstParty, err := cmd.Flags().GetBool("first-party")
+		if err != nil {
+			return err
+		}
+
+		opts := fileOptions()
+		if firstParty {
+			opts = append(opts, dispatch.WithOrigins(analyzer.FirstParty, analyzer.Workspace))
+		}
+
+		a := dispatch.Dispatcher(opts...)
+
+		base, err := analyzeRevision(ctx, a, dir, args[0])
+		if err != nil {
+			return err
+		}
+
+		head, err := analyzeRevision(ctx, a, dir, args[1])
+		if err != nil {
+			return err
+		}
+
+		return diff.New(base, head).Write(cmd.OutOrStdout(), format)
+	},
+}
+
+// analyzeRevision analyzes dir as it is in rev
+func analyzeRevision(
+	ctx context.Context,
+	a analyzer.Analyzer,
+	dir, rev string,
+) (diff.Analysis, error) {
+	prefix, err := gitfs.Prefix(ctx, dir)
+	if err != nil {
+		return diff.Analysis{}, err
+	}
+
+	gfs, err := gitfs.New(ctx, dir, rev)
+	if err != nil {
+		return diff.Analysis{}, err
+	}
+
+	defer func() {
+		if err := gfs.Close(); err != nil {
+			slog.ErrorContext(ctx, "failed to close git fs", "rev", rev, "error", err)
+		}
+	}()
+
+	var dirFS fs.FS = gfs
+	if prefix != "" {
+		dirFS, err = fs.Sub(gfs, path.Clean(prefix))
+		if err != nil {
+			return diff.Analysis{}, err
+		}
+	}
+
+	r, err := analyzer.AnalyzeAll(ctx, a, dirFS)
+	if err != nil {
+		return diff.Analysis{}, err
+	}
+
+	return diff.Analysis{Imports: r.Imports, Metrics: r.Metrics}, nil
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringP("format", "f", string(report.Text), "output format, one of text|json")
+	diffCmd.Flags().
+		Bool("first-party", false, "only compare coupling between packages of the analyzed modules")
+}
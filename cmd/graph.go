@@ -0,0 +1,62 @@
+/*
+Copyright © 2026 Flamingoose Software Inc <eng@flamingoose.ca>
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/dispatch"
+	"github.com/flamingoosesoftwareinc/uda/internal/graph"
+	"github.com/spf13/cobra"
+)
+
+// graphCmd represents the graph command
+var graphCmd = &cobra.Command{
+	Use:   "graph [path]",
+	Short: "Export the package dependency graph as graphviz DOT or mermaid",
+	Long: `Export the dependency graph of the analyzed packages.
+
+Nodes are colored by instability from green (stable) to red (unstable) and
+clustered by parent package e.g. shop for the python package shop.models. Edges
+are weighted by the number of symbol uses.
+
+	uda graph --format dot | dot -Tsvg > deps.svg
+	uda graph --format mermaid`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		dirFS := os.DirFS(path)
+
+		formatFlag, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		format, err := graph.ParseFormat(formatFlag)
+		if err != nil {
+			return err
+		}
+
+		a := dispatch.Dispatcher(fileOptions()...)
+
+		r, err := a.AnalyzeAll(ctx, dirFS)
+		if err != nil {
+			return err
+		}
+
+		return graph.New(r.Imports).Write(cmd.OutOrStdout(), format, r.Metrics, r.Languages)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().StringP("format", "f", string(graph.DOT), "output format, one of dot|mermaid")
+}
@@ -0,0 +1,44 @@
+/*
+Copyright © 2026 Flamingoose Software Inc <eng@flamingoose.ca>
+*/
+package cmd
+
+import (
+	"os"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/mcp"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+// mcpCmd represents the mcp command
+var mcpCmd = &cobra.Command{
+	Use:   "mcp [path]",
+	Short: "Serve the analyses as tools of a local MCP server over stdin/stdout",
+	Long: `Serve the analyses of path as Model Context Protocol tools over stdin/stdout
+so coding agents can query coupling before they edit a package.
+
+Tools return structured JSON:
+  package_metrics    coupling metrics of every package or of a single package
+  dependency_path    shortest chain of imports from a package to another
+  cycles             import cycles grouped by strongly connected component
+  impact_of_change   packages importing a package directly and transitively
+
+Every call analyzes path again, unchanged files are read from the parse cache.
+
+	{"mcpServers": {"uda": {"command": "uda", "args": ["mcp", "/path/to/repo"]}}}`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		return mcp.NewServer(os.DirFS(path), fileOptions()...).
+			Run(cmd.Context(), &sdk.StdioTransport{})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
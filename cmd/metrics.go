@@ -4,23 +4,26 @@ Copyright © 2026 Flamingoose Software Inc <eng@flamingoose.ca>
 package cmd
 
 import (
-	"fmt"
 	"os"
 
-	"github.com/flamingoosesoftwareinc/uda/internal/analyzer/golang"
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/dispatch"
+	"github.com/flamingoosesoftwareinc/uda/internal/report"
 	"github.com/spf13/cobra"
 )
 
 // metricsCmd represents the metrics command
 var metricsCmd = &cobra.Command{
-	Use:   "metrics",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+	Use:   "metrics [path]",
+	Short: "Report the imports and coupling metrics of every package",
+	Long: `Report the imports and coupling metrics of every package.
+
+For every package the afferent coupling (Ca), efferent coupling (Ce),
+instability (I), abstractness (A) and distance from the main sequence (D)
+are reported.
+
+Use --format json for a stable, sorted and versioned output meant to be
+consumed by scripts.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
@@ -32,19 +35,44 @@ to quickly create a Cobra application.`,
 
 		dirFS := os.DirFS(path)
 
-		pi, err := golang.GoAnalyzer().Analyze(ctx, dirFS)
+		showSources, err := cmd.Flags().GetBool("sources")
+		if err != nil {
+			return err
+		}
+
+		firstParty, err := cmd.Flags().GetBool("first-party")
+		if err != nil {
+			return err
+		}
+
+		formatFlag, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+
+		format, err := report.ParseFormat(formatFlag)
+		if err != nil {
+			return err
+		}
+
+		opts := fileOptions()
+		if firstParty {
+			opts = append(opts, dispatch.WithOrigins(analyzer.FirstParty, analyzer.Workspace))
+		}
+
+		a := dispatch.Dispatcher(opts...)
+
+		r, err := a.AnalyzeAll(ctx, dirFS)
 		if err != nil {
 			return err
 		}
 
-		for p, i := range pi {
-			fmt.Printf("Package: %v imports\n", p)
-			for _, _i := range i {
-				fmt.Printf("\t%v\n", _i)
-			}
+		if !showSources {
+			r.Sources = nil
 		}
 
-		return nil
+		return report.New(r.Imports, r.Metrics, r.Origins, r.Sources, r.Languages, r.Platforms, r.Tests).
+			Write(cmd.OutOrStdout(), format)
 	},
 }
 
@@ -60,4 +88,8 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// metricsCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	metricsCmd.Flags().Bool("sources", false, "show the files introducing each import")
+	metricsCmd.Flags().
+		Bool("first-party", false, "only report coupling between packages of the analyzed modules")
+	metricsCmd.Flags().StringP("format", "f", string(report.Text), "output format, one of text|json")
 }
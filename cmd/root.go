@@ -6,10 +6,16 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"go/build"
 	"log/slog"
 	"os"
+	"runtime"
 
 	"github.com/charmbracelet/fang"
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer/golang"
+	"github.com/flamingoosesoftwareinc/uda/internal/dispatch"
+	"github.com/flamingoosesoftwareinc/uda/internal/files"
+	"github.com/flamingoosesoftwareinc/uda/internal/ts"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -38,6 +44,45 @@ to quickly create a Cobra application.`,
 		} else {
 			slog.SetLogLoggerLevel(logLevel)
 		}
+
+		if queries := viper.GetString("queries"); queries != "" {
+			if _, err := os.Stat(queries); err != nil {
+				return fmt.Errorf("invalid queries directory: %w", err)
+			}
+
+			ts.SetQueryDir(os.DirFS(queries))
+		}
+
+		if err := files.ValidatePatterns(viper.GetStringSlice("include")...); err != nil {
+			return fmt.Errorf("invalid --include: %w", err)
+		}
+
+		if err := files.ValidatePatterns(viper.GetStringSlice("exclude")...); err != nil {
+			return fmt.Errorf("invalid --exclude: %w", err)
+		}
+
+		ctx := ts.WithJobs(cmd.Context(), viper.GetInt("jobs"))
+		ctx = golang.WithBuildContext(ctx, golang.BuildContext{
+			GOOS:         viper.GetString("goos"),
+			GOARCH:       viper.GetString("goarch"),
+			Tags:         viper.GetStringSlice("tags"),
+			CgoEnabled:   cgoEnabled(cmd),
+			AllPlatforms: viper.GetBool("all-platforms"),
+			Tests:        viper.GetBool("include-tests"),
+		})
+
+		if !viper.GetBool("no-cache") {
+			cacheDir, err := ts.DefaultCacheDir()
+			if err != nil {
+				slog.Warn("parse cache disabled", "error", err)
+			} else {
+				c := ts.NewCache(cacheDir)
+				c.AutoPrune(ctx)
+				ctx = ts.WithCache(ctx, c)
+			}
+		}
+
+		cmd.SetContext(ctx)
 		return nil
 	},
 }
@@ -59,6 +104,18 @@ var slogLevel = map[string]slog.Level{
 	"debug": slog.LevelDebug,
 }
 
+// cgoEnabled reports whether the cgo build tag is satisfied
+// like the go command cgo is disabled by default when analyzing for another platform
+func cgoEnabled(cmd *cobra.Command) bool {
+	if cmd.Flags().Changed("cgo") {
+		return viper.GetBool("cgo")
+	}
+
+	native := viper.GetString("goos") == runtime.GOOS && viper.GetString("goarch") == runtime.GOARCH
+
+	return native && viper.GetBool("cgo")
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 	// Here you will define your flags and configuration settings.
@@ -76,11 +133,123 @@ func init() {
 	); err != nil {
 		slog.Error("failed to bind", "error", err)
 	}
+	rootCmd.PersistentFlags().
+		String("queries", "", "directory of .scm files overriding the tree-sitter queries e.g. go/analyze.scm")
+	if err := viper.BindPFlag(
+		"queries",
+		rootCmd.PersistentFlags().Lookup("queries"),
+	); err != nil {
+		slog.Error("failed to bind", "error", err)
+	}
+	rootCmd.PersistentFlags().
+		IntP("jobs", "j", 0, "number of files parsed at once (default GOMAXPROCS)")
+	if err := viper.BindPFlag(
+		"jobs",
+		rootCmd.PersistentFlags().Lookup("jobs"),
+	); err != nil {
+		slog.Error("failed to bind", "error", err)
+	}
+	rootCmd.PersistentFlags().
+		Bool(
+			"no-cache",
+			false,
+			"parse every file instead of reading unchanged files from the parse cache, rust files are never cached",
+		)
+	if err := viper.BindPFlag(
+		"no-cache",
+		rootCmd.PersistentFlags().Lookup("no-cache"),
+	); err != nil {
+		slog.Error("failed to bind", "error", err)
+	}
+	rootCmd.PersistentFlags().
+		StringSlice("include", nil, "only analyze the source files matching these globs e.g. 'services/**'")
+	if err := viper.BindPFlag(
+		"include",
+		rootCmd.PersistentFlags().Lookup("include"),
+	); err != nil {
+		slog.Error("failed to bind", "error", err)
+	}
+	rootCmd.PersistentFlags().
+		StringSlice("exclude", nil, "skip the files and directories matching these globs e.g. '**/*.pb.go'")
+	if err := viper.BindPFlag(
+		"exclude",
+		rootCmd.PersistentFlags().Lookup("exclude"),
+	); err != nil {
+		slog.Error("failed to bind", "error", err)
+	}
+	rootCmd.PersistentFlags().
+		String("goos", runtime.GOOS, "only analyze the .go files built for this operating system, $GOOS by default")
+	if err := viper.BindPFlag(
+		"goos",
+		rootCmd.PersistentFlags().Lookup("goos"),
+	); err != nil {
+		slog.Error("failed to bind", "error", err)
+	}
+	rootCmd.PersistentFlags().
+		String("goarch", runtime.GOARCH, "only analyze the .go files built for this architecture, $GOARCH by default")
+	if err := viper.BindPFlag(
+		"goarch",
+		rootCmd.PersistentFlags().Lookup("goarch"),
+	); err != nil {
+		slog.Error("failed to bind", "error", err)
+	}
+	rootCmd.PersistentFlags().
+		StringSlice("tags", nil, "build tags satisfied by the analyzed .go files e.g. integration")
+	if err := viper.BindPFlag(
+		"tags",
+		rootCmd.PersistentFlags().Lookup("tags"),
+	); err != nil {
+		slog.Error("failed to bind", "error", err)
+	}
+	rootCmd.PersistentFlags().
+		Bool("cgo", build.Default.CgoEnabled, "satisfy the cgo build tag, by default when $CGO_ENABLED or a C compiler is found and --goos and --goarch are this platform")
+	if err := viper.BindPFlag(
+		"cgo",
+		rootCmd.PersistentFlags().Lookup("cgo"),
+	); err != nil {
+		slog.Error("failed to bind", "error", err)
+	}
+	rootCmd.PersistentFlags().
+		Bool("all-platforms", false, "analyze the .go files of every platform and annotate imports with their build constraints")
+	if err := viper.BindPFlag(
+		"all-platforms",
+		rootCmd.PersistentFlags().Lookup("all-platforms"),
+	); err != nil {
+		slog.Error("failed to bind", "error", err)
+	}
+	rootCmd.PersistentFlags().
+		Bool("include-tests", false, "analyze _test.go files, external test packages get metrics of their own, test-only uses are reported apart and do not count in metrics")
+	if err := viper.BindPFlag(
+		"include-tests",
+		rootCmd.PersistentFlags().Lookup("include-tests"),
+	); err != nil {
+		slog.Error("failed to bind", "error", err)
+	}
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
+// languages are added to the built in languages by the binary embedding the commands
+var languages []dispatch.Language
+
+// AddLanguages makes every command analyze languages on top of the built in languages
+// a language replaces the built in language of the same ID, it is called before Execute
+// e.g. AddLanguages(uda.Language{ID: "dsl", Detected: []string{"HCL"}, New: newDSLAnalyzer})
+func AddLanguages(l ...dispatch.Language) {
+	languages = append(languages, l...)
+}
+
+// fileOptions restricts the analysis to the files selected by --include and --exclude
+// and routes files to the languages added with AddLanguages
+func fileOptions() []dispatch.Option {
+	return []dispatch.Option{
+		dispatch.WithInclude(viper.GetStringSlice("include")...),
+		dispatch.WithExclude(viper.GetStringSlice("exclude")...),
+		dispatch.WithLanguages(languages...),
+	}
+}
+
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
 	if cfgFile != "" {
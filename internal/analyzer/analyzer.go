@@ -2,7 +2,11 @@ package analyzer
 
 import (
 	"context"
+	"fmt"
 	"io/fs"
+	"math"
+	"slices"
+	"strings"
 )
 
 type Package string
@@ -11,10 +15,65 @@ type Package string
 // re-typed here to explicitly communicate the relationship in PackageImports
 type Import Package
 
+// Package returns the imported package without any quoting e.g. `"io/fs"` is "io/fs"
+func (i Import) Package() Package {
+	return Package(strings.Trim(string(i), "\"`'"))
+}
+
 // PackageImports is expected to contain a mapping of a package and its dependencies
 // e.g. {"analyzer":["context","io/fs"]}
 type PackageImports map[Package][]Import
 
+// ImportSources is expected to contain the locations that introduce each import of a package
+// e.g. {"analyzer":{"context":[{"analyzer.go",4}],"io/fs":[{"analyzer.go",5},{"fs.go",3}]}}
+type ImportSources map[Package]map[Import][]Location
+
+// Location is a line within a file relative to the analyzed directory
+type Location struct {
+	File string `json:"file"`
+	Line uint   `json:"line"`
+}
+
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d", l.File, l.Line)
+}
+
+// Origin classifies where an imported package comes from
+type Origin string
+
+const (
+	// Std packages belong to the standard library of the language
+	Std Origin = "std"
+	// FirstParty packages belong to the same module as the importing package
+	FirstParty Origin = "first-party"
+	// Workspace packages belong to another analyzed module e.g. a sibling module of a go workspace
+	Workspace Origin = "workspace"
+	// ThirdParty packages are not part of the analyzed code
+	ThirdParty Origin = "third-party"
+)
+
+// Internal reports whether the origin is part of the analyzed code
+func (o Origin) Internal() bool {
+	return o == FirstParty || o == Workspace
+}
+
+// PackageLanguages is expected to contain the language of every package of a polyglot analysis
+// e.g. {"github.com/f/uda/internal/analyzer":"go","shop.api":"python"}
+type PackageLanguages map[Package]string
+
+// ImportOrigins is expected to contain the origin of every import of a package
+// e.g. {"github.com/f/uda/internal/analyzer":{"context":"std","github.com/f/uda/internal/files":"first-party"}}
+type ImportOrigins map[Package]map[Import]Origin
+
+// ImportPlatforms is expected to contain the build constraints of the files introducing an import
+// when the import is only introduced on some platforms, imports introduced everywhere have no entry
+// e.g. {"github.com/f/uda/internal/files":{`"syscall"`:["windows"]}}
+type ImportPlatforms map[Package]map[Import][]string
+
+// TestImports is expected to contain the imports of a package only introduced by its tests
+// e.g. {"github.com/f/uda/internal/files":[`"github.com/stretchr/testify/require"`]}
+type TestImports map[Package][]Import
+
 /*
 * {
 *    "github.com/f/uda/internal/analyzer": {
@@ -43,6 +102,12 @@ type Metrics struct {
 	Inward PackageCouplingStats
 	// The number of other packages this package depends on
 	Outward PackageCouplingStats
+	// The number of abstract types e.g. interfaces declared by this package
+	AbstractTypes uint
+	// The number of types declared by this package, abstract or concrete
+	TotalTypes uint
+	// The symbols of other packages only used by the tests of this package, counted in no coupling
+	Tests PackageCouplingStats
 }
 
 // PackageCouplingStats is expected to contain a list of outward or inward dependencies
@@ -68,6 +133,63 @@ type CouplingStats map[string]struct {
 	// Eventually could have each instance with line number and position and length
 }
 
+// Add records a single use of symbol from pkg
+// e.g. stats.Add("io/fs", "fs.FS")
+func (p PackageCouplingStats) Add(pkg Package, symbol string) {
+	p.add(pkg, symbol, 1)
+}
+
+func (p PackageCouplingStats) add(pkg Package, symbol string, count uint) {
+	stats, ok := p[pkg]
+	if !ok {
+		stats = make(CouplingStats)
+		p[pkg] = stats
+	}
+
+	s := stats[symbol]
+	s.Count += count
+	stats[symbol] = s
+}
+
+// BuildMetrics derives the Metrics of every package in outward
+// Inward coupling is the inverse of the outward coupling of all other packages
+// so only packages present in outward can have inward coupling
+// The result is sorted by package
+func BuildMetrics(outward map[Package]PackageCouplingStats) []Metrics {
+	inward := make(map[Package]PackageCouplingStats, len(outward))
+	for pkg := range outward {
+		inward[pkg] = make(PackageCouplingStats)
+	}
+
+	for pkg, deps := range outward {
+		for dep, stats := range deps {
+			in, ok := inward[dep]
+			if !ok || dep == pkg {
+				continue
+			}
+
+			for symbol, s := range stats {
+				in.add(pkg, symbol, s.Count)
+			}
+		}
+	}
+
+	metrics := make([]Metrics, 0, len(outward))
+	for pkg, deps := range outward {
+		metrics = append(metrics, Metrics{
+			Package: pkg,
+			Inward:  inward[pkg],
+			Outward: deps,
+		})
+	}
+
+	slices.SortFunc(metrics, func(a, b Metrics) int {
+		return strings.Compare(string(a.Package), string(b.Package))
+	})
+
+	return metrics
+}
+
 func (m Metrics) InwardCoupling() float64 {
 	inwardCouplingCount := 0
 
@@ -90,13 +212,129 @@ func (m Metrics) OutwardCoupling() float64 {
 
 // Instability returns the ratio of outward coupling to inward coupling
 // It is an indicator of the packages resilience to change
+// A package without any coupling is considered stable
 func (m Metrics) Instability() float64 {
 	total := m.InwardCoupling() + m.OutwardCoupling()
+	if total == 0 {
+		return 0
+	}
+
 	return m.OutwardCoupling() / total
 }
 
+// Abstractness returns the ratio of abstract types to all types declared by the package
+// A package without any types is considered concrete
+func (m Metrics) Abstractness() float64 {
+	if m.TotalTypes == 0 {
+		return 0
+	}
+
+	return float64(m.AbstractTypes) / float64(m.TotalTypes)
+}
+
+// Distance returns the distance from the main sequence, the line where A + I = 1
+// 0 is on the main sequence, 1 is either in the zone of pain (stable and concrete)
+// or the zone of uselessness (unstable and abstract)
+func (m Metrics) Distance() float64 {
+	return math.Abs(m.Abstractness() + m.Instability() - 1)
+}
+
 // Analyzer is expected to walk dir and extract the PackageImports
 type Analyzer interface {
 	Analyze(ctx context.Context, dir fs.FS) (PackageImports, error)
 	AnalyzeV2(ctx context.Context, dir fs.FS) ([]Metrics, error)
 }
+
+// SourceAnalyzer is implemented by analyzers that can attribute imports to the files introducing them
+type SourceAnalyzer interface {
+	AnalyzeSources(ctx context.Context, dir fs.FS) (ImportSources, error)
+}
+
+// OriginAnalyzer is implemented by analyzers that can classify the origin of imports
+type OriginAnalyzer interface {
+	AnalyzeOrigins(ctx context.Context, dir fs.FS) (ImportOrigins, error)
+}
+
+// PlatformAnalyzer is implemented by analyzers that can tell on which platforms an import is introduced
+type PlatformAnalyzer interface {
+	AnalyzePlatforms(ctx context.Context, dir fs.FS) (ImportPlatforms, error)
+}
+
+// TestAnalyzer is implemented by analyzers that can tell which imports are only introduced by tests
+type TestAnalyzer interface {
+	AnalyzeTests(ctx context.Context, dir fs.FS) (TestImports, error)
+}
+
+// LanguageAnalyzer is implemented by analyzers that can tell the language of every package
+type LanguageAnalyzer interface {
+	AnalyzeLanguages(ctx context.Context, dir fs.FS) (PackageLanguages, error)
+}
+
+// Result is every analysis of a directory, the optional analyses are nil when the analyzer cannot tell
+type Result struct {
+	Imports   PackageImports
+	Metrics   []Metrics
+	Sources   ImportSources
+	Origins   ImportOrigins
+	Platforms ImportPlatforms
+	Tests     TestImports
+	Languages PackageLanguages
+}
+
+// AllAnalyzer is implemented by analyzers that derive every analysis from a single walk of dir
+// rather than walking and parsing dir again for every method
+type AllAnalyzer interface {
+	AnalyzeAll(ctx context.Context, dir fs.FS) (Result, error)
+}
+
+// AnalyzeAll runs every analysis a implements on dir, in a single walk when a is an AllAnalyzer
+func AnalyzeAll(ctx context.Context, a Analyzer, dir fs.FS) (Result, error) {
+	if all, ok := a.(AllAnalyzer); ok {
+		return all.AnalyzeAll(ctx, dir)
+	}
+
+	var (
+		r   Result
+		err error
+	)
+
+	if r.Imports, err = a.Analyze(ctx, dir); err != nil {
+		return Result{}, err
+	}
+
+	if r.Metrics, err = a.AnalyzeV2(ctx, dir); err != nil {
+		return Result{}, err
+	}
+
+	if sa, ok := a.(SourceAnalyzer); ok {
+		if r.Sources, err = sa.AnalyzeSources(ctx, dir); err != nil {
+			return Result{}, err
+		}
+	}
+
+	if oa, ok := a.(OriginAnalyzer); ok {
+		if r.Origins, err = oa.AnalyzeOrigins(ctx, dir); err != nil {
+			return Result{}, err
+		}
+	}
+
+	if pa, ok := a.(PlatformAnalyzer); ok {
+		if r.Platforms, err = pa.AnalyzePlatforms(ctx, dir); err != nil {
+			return Result{}, err
+		}
+	}
+
+	if ta, ok := a.(TestAnalyzer); ok {
+		if r.Tests, err = ta.AnalyzeTests(ctx, dir); err != nil {
+			return Result{}, err
+		}
+	}
+
+	if la, ok := a.(LanguageAnalyzer); ok {
+		if r.Languages, err = la.AnalyzeLanguages(ctx, dir); err != nil {
+			return Result{}, err
+		}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,135 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMetrics(t *testing.T) {
+	outward := map[analyzer.Package]analyzer.PackageCouplingStats{
+		"a": {
+			"b":       {"b.Do": {Count: 2}, "b.Type": {Count: 1}},
+			"context": {"context.Context": {Count: 1}},
+		},
+		"b": {
+			"c": {"c.Do": {Count: 1}},
+		},
+		"c": {},
+	}
+
+	got := analyzer.BuildMetrics(outward)
+
+	require.Equal(t, []analyzer.Metrics{
+		{
+			Package: "a",
+			Inward:  analyzer.PackageCouplingStats{},
+			Outward: outward["a"],
+		},
+		{
+			Package: "b",
+			Inward: analyzer.PackageCouplingStats{
+				"a": {"b.Do": {Count: 2}, "b.Type": {Count: 1}},
+			},
+			Outward: outward["b"],
+		},
+		{
+			Package: "c",
+			Inward: analyzer.PackageCouplingStats{
+				"b": {"c.Do": {Count: 1}},
+			},
+			Outward: outward["c"],
+		},
+	}, got)
+}
+
+func TestInstability(t *testing.T) {
+	tests := map[string]struct {
+		metrics analyzer.Metrics
+		want    float64
+	}{
+		"should be 0 without coupling": {
+			metrics: analyzer.Metrics{},
+			want:    0,
+		},
+		"should be 1 with only outward coupling": {
+			metrics: analyzer.Metrics{
+				Outward: analyzer.PackageCouplingStats{"fmt": {"fmt.Println": {Count: 3}}},
+			},
+			want: 1,
+		},
+		"should be ratio of outward to total coupling": {
+			metrics: analyzer.Metrics{
+				Inward: analyzer.PackageCouplingStats{
+					"a": {"b.Do": {Count: 1}},
+					"c": {"b.Do": {Count: 1}, "b.Type": {Count: 1}},
+				},
+				Outward: analyzer.PackageCouplingStats{"fmt": {"fmt.Println": {Count: 3}}},
+			},
+			want: 0.25,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.InDelta(t, tt.want, tt.metrics.Instability(), 0.0001)
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := map[string]struct {
+		metrics          analyzer.Metrics
+		wantAbstractness float64
+		wantDistance     float64
+	}{
+		"should be in the zone of pain when stable and concrete": {
+			metrics: analyzer.Metrics{
+				Inward:     analyzer.PackageCouplingStats{"a": {"b.Do": {Count: 1}}},
+				TotalTypes: 2,
+			},
+			wantAbstractness: 0,
+			wantDistance:     1,
+		},
+		"should be in the zone of uselessness when unstable and abstract": {
+			metrics: analyzer.Metrics{
+				Outward:       analyzer.PackageCouplingStats{"fmt": {"fmt.Println": {Count: 1}}},
+				AbstractTypes: 2,
+				TotalTypes:    2,
+			},
+			wantAbstractness: 1,
+			wantDistance:     1,
+		},
+		"should be on the main sequence when stable and abstract": {
+			metrics: analyzer.Metrics{
+				Inward:        analyzer.PackageCouplingStats{"a": {"b.Reader": {Count: 1}}},
+				AbstractTypes: 1,
+				TotalTypes:    1,
+			},
+			wantAbstractness: 1,
+			wantDistance:     0,
+		},
+		"should be halfway": {
+			metrics: analyzer.Metrics{
+				Inward:        analyzer.PackageCouplingStats{"a": {"b.Do": {Count: 1}}},
+				Outward:       analyzer.PackageCouplingStats{"fmt": {"fmt.Println": {Count: 1}}},
+				AbstractTypes: 1,
+				TotalTypes:    4,
+			},
+			wantAbstractness: 0.25,
+			wantDistance:     0.25,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.InDelta(t, tt.wantAbstractness, tt.metrics.Abstractness(), 0.0001)
+			require.InDelta(t, tt.wantDistance, tt.metrics.Distance(), 0.0001)
+		})
+	}
+}
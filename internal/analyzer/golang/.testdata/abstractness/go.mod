@@ -0,0 +1,3 @@
+module example.com/abstractness
+
+go 1.21
@@ -0,0 +1,9 @@
+package shape
+
+type Circle struct {
+	Radius float64
+}
+
+type Scaler interface {
+	Scale(factor float64) Shape
+}
@@ -0,0 +1,19 @@
+package shape
+
+type Shape interface {
+	Area() Area
+}
+
+type (
+	Area float64
+
+	Square struct {
+		Side float64
+	}
+)
+
+func (s Square) Area() Area {
+	type scaled struct{ side float64 }
+
+	return Area(scaled{side: s.Side}.side * s.Side)
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"example.com/aliases/lib/go-util"
+	"gopkg.in/yaml.v3"
+)
+
+// yaml is the only import whose name is not known, so it is the name yaml refers to
+func decode(in string, out any) error {
+	return yaml.Unmarshal([]byte(util.Trim(in)), out)
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	. "math"
+	str "strings"
+
+	"github.com/acme/api/v2"
+	"gopkg.in/yaml.v3"
+)
+
+func main() {
+	s := api.New()
+	out, _ := yaml.Marshal(s)
+	fmt.Println(str.ToUpper(string(out)), Pi)
+}
+
+// the api parameter shadows the api import in the body
+func describe(api *api.Server) string {
+	return api.Name
+}
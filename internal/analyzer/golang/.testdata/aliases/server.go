@@ -0,0 +1,14 @@
+package main
+
+import (
+	acme "github.com/acme/api/v2"
+	"strings"
+)
+
+type server struct {
+	api *acme.Server
+}
+
+func (s server) name() string {
+	return strings.ToUpper(s.api.Name)
+}
@@ -0,0 +1,7 @@
+package fs
+
+import "io/fs"
+
+func Open(fsys fs.FS, name string) (fs.File, error) {
+	return fsys.Open(name)
+}
@@ -0,0 +1,7 @@
+//go:build integration
+
+package fs
+
+import "testing/fstest"
+
+var testFS = fstest.MapFS{}
@@ -0,0 +1,9 @@
+//go:build unix
+
+package fs
+
+import "strings"
+
+func hidden(name string) (bool, error) {
+	return strings.HasPrefix(name, "."), nil
+}
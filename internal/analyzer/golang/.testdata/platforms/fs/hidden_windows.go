@@ -0,0 +1,13 @@
+package fs
+
+import "syscall"
+
+func hidden(name string) (bool, error) {
+	p, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return false, err
+	}
+
+	attributes, err := syscall.GetFileAttributes(p)
+	return attributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0, err
+}
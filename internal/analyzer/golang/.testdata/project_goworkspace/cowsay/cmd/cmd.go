@@ -4,8 +4,9 @@ import (
 	"fmt"
 
 	"example.com/cowsay/moo"
+	"example.com/foobarbaz/pkg/banner"
 )
 
 func Run() {
-	fmt.Println(moo.Say("moo"))
+	fmt.Println(banner.Wrap(moo.Say("moo")))
 }
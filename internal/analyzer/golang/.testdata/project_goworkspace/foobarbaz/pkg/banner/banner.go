@@ -0,0 +1,7 @@
+package banner
+
+import "strings"
+
+func Wrap(msg string) string {
+	return strings.Repeat("-", len(msg)) + "\n" + msg
+}
@@ -0,0 +1,11 @@
+package store
+
+import "database/sql"
+
+type Store struct {
+	db *sql.DB
+}
+
+func Open(db *sql.DB) *Store {
+	return &Store{db: db}
+}
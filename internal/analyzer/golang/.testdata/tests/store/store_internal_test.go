@@ -0,0 +1,12 @@
+package store
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	if Open(&sql.DB{}) == nil {
+		t.Fatal("nil store")
+	}
+}
@@ -0,0 +1,12 @@
+package store_test
+
+import (
+	"testing"
+
+	"example.com/tests/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	require.NotNil(t, store.Open(nil))
+}
@@ -0,0 +1,186 @@
+package golang
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"go/build"
+	"go/build/constraint"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// BuildContext selects the .go files built for a platform as the go command does
+// from their //go:build line and their _GOOS or _GOARCH file name suffix
+type BuildContext struct {
+	GOOS   string
+	GOARCH string
+	// build tags satisfied in addition to the platform e.g. integration
+	Tags []string
+	// satisfy the cgo build tag, the go command enables cgo when a C compiler is found
+	// for the platform it runs on, see build.Default.CgoEnabled
+	CgoEnabled bool
+	// analyze the files of every platform, their imports are annotated with the constraints of the files
+	AllPlatforms bool
+	// analyze _test.go files too, an external test package e.g. store_test is a package of its own
+	Tests bool
+}
+
+type buildContextKey struct{}
+
+// WithBuildContext makes the go analyses run with ctx only analyze the files built for b
+// e.g. WithBuildContext(ctx, BuildContext{GOOS: "windows", GOARCH: "amd64"})
+// an empty GOOS or GOARCH is the platform uda runs on
+func WithBuildContext(ctx context.Context, b BuildContext) context.Context {
+	return context.WithValue(ctx, buildContextKey{}, b)
+}
+
+func buildContextOf(ctx context.Context) BuildContext {
+	b, _ := ctx.Value(buildContextKey{}).(BuildContext)
+
+	if b.GOOS == "" {
+		b.GOOS = runtime.GOOS
+	}
+
+	if b.GOARCH == "" {
+		b.GOARCH = runtime.GOARCH
+	}
+
+	return b
+}
+
+// known platforms of the go command, a file name suffix of another word is not a constraint
+// e.g. hidden_world.go builds everywhere
+var (
+	knownOS = []string{
+		"aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "js", "linux", "nacl",
+		"netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows", "zos",
+	}
+	unixOS = []string{
+		"aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "linux", "netbsd",
+		"openbsd", "solaris",
+	}
+	knownArch = []string{
+		"386", "amd64", "amd64p32", "arm", "armbe", "arm64", "arm64be", "loong64", "mips", "mipsle",
+		"mips64", "mips64le", "mips64p32", "mips64p32le", "ppc", "ppc64", "ppc64le", "riscv", "riscv64",
+		"s390", "s390x", "sparc", "sparc64", "wasm",
+	}
+)
+
+// matchTag reports whether a build tag is satisfied as the go command does with the gc toolchain
+// e.g. linux is satisfied on android and unix on every unix platform
+func (b BuildContext) matchTag(tag string) bool {
+	switch tag {
+	case b.GOOS, b.GOARCH, "gc":
+		return true
+	case "cgo":
+		return b.CgoEnabled
+	case "unix":
+		return slices.Contains(unixOS, b.GOOS)
+	case "linux":
+		return b.GOOS == "android"
+	case "solaris":
+		return b.GOOS == "illumos"
+	case "darwin":
+		return b.GOOS == "ios"
+	}
+
+	return slices.Contains(b.Tags, tag) || slices.Contains(build.Default.ReleaseTags, tag)
+}
+
+// matches reports whether a file with the build constraint of goFile.constraint is built for b
+func (b BuildContext) matches(expr string) bool {
+	if b.AllPlatforms || expr == "" {
+		return true
+	}
+
+	x, err := constraint.Parse("//go:build " + expr)
+	if err != nil {
+		return true
+	}
+
+	return x.Eval(b.matchTag)
+}
+
+// fileConstraint combines the constraint of the file name suffix and the //go:build line of a .go file
+// e.g. "windows && amd64" for hidden_windows.go with //go:build amd64, empty when the file builds everywhere
+func fileConstraint(goFilepath string, text []byte) string {
+	var exprs []constraint.Expr
+
+	if x := nameConstraint(path.Base(goFilepath)); x != nil {
+		exprs = append(exprs, x)
+	}
+
+	// a //go:build line repeating the file name suffix e.g. //go:build windows in hidden_windows.go
+	if x := buildLine(text); x != nil && (len(exprs) == 0 || x.String() != exprs[0].String()) {
+		exprs = append(exprs, x)
+	}
+
+	if len(exprs) == 0 {
+		return ""
+	}
+
+	x := exprs[0]
+	for _, y := range exprs[1:] {
+		x = &constraint.AndExpr{X: x, Y: y}
+	}
+
+	return x.String()
+}
+
+// nameConstraint is the constraint of a _GOOS, _GOARCH or _GOOS_GOARCH file name suffix
+// the suffix of a test file comes before _test e.g. hidden_windows_test.go
+func nameConstraint(name string) constraint.Expr {
+	name, _, _ = strings.Cut(name, ".")
+
+	// the first element is never a constraint e.g. linux.go builds everywhere
+	_, name, ok := strings.Cut(name, "_")
+	if !ok {
+		return nil
+	}
+
+	l := strings.Split(name, "_")
+	if n := len(l); n > 0 && l[n-1] == "test" {
+		l = l[:n-1]
+	}
+
+	n := len(l)
+
+	switch {
+	case n >= 2 && slices.Contains(knownOS, l[n-2]) && slices.Contains(knownArch, l[n-1]):
+		return &constraint.AndExpr{X: &constraint.TagExpr{Tag: l[n-2]}, Y: &constraint.TagExpr{Tag: l[n-1]}}
+	case n >= 1 && slices.Contains(knownOS, l[n-1]), n >= 1 && slices.Contains(knownArch, l[n-1]):
+		return &constraint.TagExpr{Tag: l[n-1]}
+	}
+
+	return nil
+}
+
+// buildLine parses the //go:build line of the header of a .go file
+// the header is made of the blank lines and line comments before the package clause
+func buildLine(text []byte) constraint.Expr {
+	scanner := bufio.NewScanner(bytes.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "//") {
+			return nil
+		}
+
+		if constraint.IsGoBuild(line) {
+			x, err := constraint.Parse(line)
+			if err != nil {
+				return nil
+			}
+
+			return x
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package golang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileConstraint(t *testing.T) {
+	tests := map[string]struct {
+		goFilepath string
+		text       string
+		want       string
+	}{
+		"should be empty without suffix nor build line": {
+			goFilepath: "fs/fs.go",
+			text:       "package fs\n",
+			want:       "",
+		},
+		"should not treat the whole name as a suffix": {
+			goFilepath: "fs/linux.go",
+			text:       "package fs\n",
+			want:       "",
+		},
+		"should ignore unknown suffixes": {
+			goFilepath: "fs/hidden_world.go",
+			text:       "package fs\n",
+			want:       "",
+		},
+		"should return the GOOS suffix": {
+			goFilepath: "fs/hidden_windows.go",
+			text:       "package fs\n",
+			want:       "windows",
+		},
+		"should return the GOARCH suffix": {
+			goFilepath: "fs/asm_arm64.go",
+			text:       "package fs\n",
+			want:       "arm64",
+		},
+		"should return the GOOS and GOARCH suffix": {
+			goFilepath: "fs/mount_linux_amd64.go",
+			text:       "package fs\n",
+			want:       "linux && amd64",
+		},
+		"should return the suffix before _test": {
+			goFilepath: "fs/hidden_windows_test.go",
+			text:       "package fs\n",
+			want:       "windows",
+		},
+		"should return the build line": {
+			goFilepath: "fs/hidden_unix.go",
+			text:       "// Copyright\n\n//go:build unix || (js && wasm)\n\npackage fs\n",
+			want:       "unix || (js && wasm)",
+		},
+		"should ignore a build line after the package clause": {
+			goFilepath: "fs/fs.go",
+			text:       "package fs\n\n//go:build ignore\n",
+			want:       "",
+		},
+		"should combine the suffix and the build line": {
+			goFilepath: "fs/hidden_windows.go",
+			text:       "//go:build amd64\n\npackage fs\n",
+			want:       "windows && amd64",
+		},
+		"should not repeat a build line matching the suffix": {
+			goFilepath: "fs/hidden_windows.go",
+			text:       "//go:build windows\n\npackage fs\n",
+			want:       "windows",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, fileConstraint(tt.goFilepath, []byte(tt.text)))
+		})
+	}
+}
+
+func TestBuildContextMatches(t *testing.T) {
+	tests := map[string]struct {
+		build BuildContext
+		expr  string
+		want  bool
+	}{
+		"unconstrained": {
+			build: BuildContext{GOOS: "linux", GOARCH: "amd64"},
+			expr:  "",
+			want:  true,
+		},
+		"other GOOS": {
+			build: BuildContext{GOOS: "linux", GOARCH: "amd64"},
+			expr:  "windows",
+			want:  false,
+		},
+		"GOOS and GOARCH": {
+			build: BuildContext{GOOS: "linux", GOARCH: "amd64"},
+			expr:  "linux && amd64",
+			want:  true,
+		},
+		"unix on darwin": {
+			build: BuildContext{GOOS: "darwin", GOARCH: "arm64"},
+			expr:  "unix",
+			want:  true,
+		},
+		"unix on windows": {
+			build: BuildContext{GOOS: "windows", GOARCH: "amd64"},
+			expr:  "unix",
+			want:  false,
+		},
+		"linux on android": {
+			build: BuildContext{GOOS: "android", GOARCH: "arm64"},
+			expr:  "linux",
+			want:  true,
+		},
+		"negated GOOS": {
+			build: BuildContext{GOOS: "linux", GOARCH: "amd64"},
+			expr:  "!windows",
+			want:  true,
+		},
+		"release tag": {
+			build: BuildContext{GOOS: "linux", GOARCH: "amd64"},
+			expr:  "go1.21",
+			want:  true,
+		},
+		"missing tag": {
+			build: BuildContext{GOOS: "linux", GOARCH: "amd64"},
+			expr:  "integration",
+			want:  false,
+		},
+		"tag": {
+			build: BuildContext{GOOS: "linux", GOARCH: "amd64", Tags: []string{"integration"}},
+			expr:  "integration && linux",
+			want:  true,
+		},
+		"cgo disabled": {
+			build: BuildContext{GOOS: "linux", GOARCH: "amd64"},
+			expr:  "cgo",
+			want:  false,
+		},
+		"cgo enabled": {
+			build: BuildContext{GOOS: "linux", GOARCH: "amd64", CgoEnabled: true},
+			expr:  "linux && cgo",
+			want:  true,
+		},
+		"all platforms": {
+			build: BuildContext{AllPlatforms: true},
+			expr:  "ignore",
+			want:  true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, tt.build.matches(tt.expr))
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package golang
+
+import (
+	"bytes"
+	"encoding/gob"
+	"maps"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+)
+
+// goFileEntry is a goFile as stored in the parse cache
+type goFileEntry struct {
+	Path          string
+	Module        modulePath
+	Pkg           analyzer.Package
+	ImportPath    analyzer.Package
+	Name          string
+	Imports       []analyzer.Import
+	ImportLines   map[analyzer.Import]uint
+	Aliases       map[string]analyzer.Import
+	Unnamed       []analyzer.Import
+	Uses          []string
+	Types         uint
+	AbstractTypes uint
+	Constraint    string
+}
+
+func (f goFile) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := gob.NewEncoder(&buf).Encode(goFileEntry{
+		Path:          f.path,
+		Module:        f.module,
+		Pkg:           f.pkg,
+		ImportPath:    f.importPath,
+		Name:          f.name,
+		Imports:       f.imports,
+		ImportLines:   f.importLines,
+		Aliases:       f.aliases,
+		Unnamed:       f.unnamed,
+		Uses:          f.uses,
+		Types:         f.types,
+		AbstractTypes: f.abstractTypes,
+		Constraint:    f.constraint,
+	})
+
+	return buf.Bytes(), err
+}
+
+func (f *goFile) GobDecode(data []byte) error {
+	var e goFileEntry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&e); err != nil {
+		return err
+	}
+
+	// gob drops empty slices and maps
+	*f = goFile{
+		path:          e.Path,
+		module:        e.Module,
+		pkg:           e.Pkg,
+		importPath:    e.ImportPath,
+		name:          e.Name,
+		imports:       append(make([]analyzer.Import, 0, len(e.Imports)), e.Imports...),
+		importLines:   make(map[analyzer.Import]uint, len(e.ImportLines)),
+		aliases:       make(map[string]analyzer.Import, len(e.Aliases)),
+		unnamed:       append(make([]analyzer.Import, 0, len(e.Unnamed)), e.Unnamed...),
+		uses:          append(make([]string, 0, len(e.Uses)), e.Uses...),
+		types:         e.Types,
+		abstractTypes: e.AbstractTypes,
+		constraint:    e.Constraint,
+	}
+
+	maps.Copy(f.importLines, e.ImportLines)
+	maps.Copy(f.aliases, e.Aliases)
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package golang
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoFileGob(t *testing.T) {
+	f := goFile{
+		path:          "app/go-util/util.go",
+		module:        "example.com/app",
+		pkg:           "example.com/app/go-util/util",
+		importPath:    "example.com/app/go-util",
+		name:          "util",
+		imports:       []analyzer.Import{`"fmt"`, `"embed"`},
+		importLines:   map[analyzer.Import]uint{`"fmt"`: 3, `"embed"`: 4},
+		aliases:       map[string]analyzer.Import{"f": `"fmt"`},
+		unnamed:       []analyzer.Import{`"embed"`},
+		uses:          []string{"f.Println"},
+		types:         2,
+		abstractTypes: 1,
+		constraint:    "linux && !cgo",
+	}
+
+	// a field missing from goFileEntry would be empty on every cache hit
+	v := reflect.ValueOf(f)
+	for i := range v.NumField() {
+		require.False(t, v.Field(i).IsZero(), "set %s", v.Type().Field(i).Name)
+	}
+
+	data, err := f.GobEncode()
+	require.NoError(t, err)
+
+	var got goFile
+	require.NoError(t, got.GobDecode(data))
+	require.Equal(t, f, got)
+}
@@ -2,10 +2,15 @@ package golang
 
 import (
 	"context"
-	"errors"
 	"io/fs"
 	"log/slog"
+	"maps"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
 
 	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
 	"github.com/flamingoosesoftwareinc/uda/internal/files"
@@ -15,19 +20,372 @@ import (
 	tsgomod "github.com/tree-sitter/tree-sitter-gomod/bindings/go"
 )
 
-type goAnalyzer struct{}
+type goAnalyzer struct {
+	origins map[analyzer.Origin]struct{}
+}
 
-var _ analyzer.Analyzer = &goAnalyzer{}
+// Option configures the go analyzer
+type Option func(*goAnalyzer)
 
-func GoAnalyzer() *goAnalyzer {
-	return &goAnalyzer{}
+// WithOrigins restricts the analysis to imports of the given origins
+// e.g. WithOrigins(analyzer.FirstParty, analyzer.Workspace) ignores coupling to std and third-party packages
+func WithOrigins(origins ...analyzer.Origin) Option {
+	return func(g *goAnalyzer) {
+		g.origins = make(map[analyzer.Origin]struct{}, len(origins))
+		for _, o := range origins {
+			g.origins[o] = struct{}{}
+		}
+	}
+}
+
+var (
+	_ analyzer.Analyzer         = &goAnalyzer{}
+	_ analyzer.SourceAnalyzer   = &goAnalyzer{}
+	_ analyzer.OriginAnalyzer   = &goAnalyzer{}
+	_ analyzer.PlatformAnalyzer = &goAnalyzer{}
+	_ analyzer.TestAnalyzer     = &goAnalyzer{}
+	_ analyzer.AllAnalyzer      = &goAnalyzer{}
+)
+
+func GoAnalyzer(opts ...Option) *goAnalyzer {
+	g := &goAnalyzer{}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// AnalyzeAll derives every analysis from a single extraction of the .go files of dir
+func (g *goAnalyzer) AnalyzeAll(ctx context.Context, dir fs.FS) (analyzer.Result, error) {
+	goFiles, gomodPaths, err := g.analyzeModules(ctx, dir)
+	if err != nil {
+		return analyzer.Result{}, err
+	}
+
+	return analyzer.Result{
+		Imports:   packageImports(goFiles),
+		Metrics:   buildMetrics(goFiles),
+		Sources:   importSources(goFiles),
+		Origins:   importOrigins(goFiles, gomodPaths),
+		Platforms: importPlatforms(goFiles),
+		Tests:     testImports(goFiles),
+	}, nil
 }
 
 func (g *goAnalyzer) AnalyzeV2(ctx context.Context, dir fs.FS) ([]analyzer.Metrics, error) {
-	return nil, errors.ErrUnsupported
+	goFiles, err := g.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildMetrics(goFiles), nil
+}
+
+func buildMetrics(goFiles []goFile) []analyzer.Metrics {
+	outward := make(map[analyzer.Package]analyzer.PackageCouplingStats)
+	// symbols used by the tests of a package built with the package e.g. store_test.go of package store
+	tested := make(map[analyzer.Package]analyzer.PackageCouplingStats)
+	// external test packages e.g. example.com/app/store_test
+	testPackages := make(map[analyzer.Package]struct{})
+	types := make(map[analyzer.Package]goFile)
+	names := packageNames(goFiles)
+
+	for _, f := range goFiles {
+		if f.external() {
+			testPackages[f.pkg] = struct{}{}
+		}
+
+		// test dependencies do not drive refactoring priority
+		// so the uses of tests built with their package are reported apart from its coupling
+		coupling := outward
+		if f.test() && !f.external() {
+			coupling = tested
+		} else {
+			t := types[f.pkg]
+			t.types += f.types
+			t.abstractTypes += f.abstractTypes
+			types[f.pkg] = t
+		}
+
+		stats, ok := coupling[f.pkg]
+		if !ok {
+			stats = make(analyzer.PackageCouplingStats)
+			coupling[f.pkg] = stats
+		}
+
+		qualifiers := importQualifiers(f.imports, f.aliases, f.unnamed, names)
+
+		for _, use := range f.uses {
+			qualifier, symbol, ok := strings.Cut(use, ".")
+			if !ok {
+				continue
+			}
+
+			importPath, ok := qualifiers[qualifier]
+			if !ok {
+				// not a package qualified reference e.g. a method call on a local variable
+				continue
+			}
+
+			// uses are counted by the name the package declares rather than the alias of the file
+			// so that f.Println and fmt.Println are both fmt.Println
+			stats.Add(importPath, packageName(importPath, names)+"."+symbol)
+		}
+	}
+
+	metrics := analyzer.BuildMetrics(outward)
+	for i := range metrics {
+		m := &metrics[i]
+		m.TotalTypes = types[m.Package].types
+		m.AbstractTypes = types[m.Package].abstractTypes
+		m.Tests = testOnly(tested[m.Package], m.Outward)
+
+		// an external test package is a node of its own but does not make the package it tests more stable
+		for pkg := range testPackages {
+			delete(m.Inward, pkg)
+		}
+	}
+
+	return metrics
+}
+
+// testOnly returns the symbols of tests not also used by the production code of their package
+func testOnly(tests, production analyzer.PackageCouplingStats) analyzer.PackageCouplingStats {
+	var only analyzer.PackageCouplingStats
+
+	for pkg, symbols := range tests {
+		for symbol, s := range symbols {
+			if _, ok := production[pkg][symbol]; ok {
+				continue
+			}
+
+			if only == nil {
+				only = make(analyzer.PackageCouplingStats)
+			}
+
+			for range s.Count {
+				only.Add(pkg, symbol)
+			}
+		}
+	}
+
+	return only
 }
 
 func (g *goAnalyzer) Analyze(ctx context.Context, dir fs.FS) (analyzer.PackageImports, error) {
+	goFiles, err := g.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return packageImports(goFiles), nil
+}
+
+func packageImports(goFiles []goFile) analyzer.PackageImports {
+	pi := make(analyzer.PackageImports)
+	seen := make(map[analyzer.Package]map[analyzer.Import]struct{})
+
+	// a package is made up of every file declaring it so imports are merged across files
+	for _, f := range goFiles {
+		pkgSeen, ok := seen[f.pkg]
+		if !ok {
+			pkgSeen = make(map[analyzer.Import]struct{}, len(f.imports))
+			seen[f.pkg] = pkgSeen
+			pi[f.pkg] = make([]analyzer.Import, 0, len(f.imports))
+		}
+
+		for _, i := range f.imports {
+			if _, ok := pkgSeen[i]; ok {
+				continue
+			}
+
+			pkgSeen[i] = struct{}{}
+			pi[f.pkg] = append(pi[f.pkg], i)
+		}
+	}
+
+	return pi
+}
+
+func (g *goAnalyzer) AnalyzeSources(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.ImportSources, error) {
+	goFiles, err := g.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return importSources(goFiles), nil
+}
+
+func importSources(goFiles []goFile) analyzer.ImportSources {
+	sources := make(analyzer.ImportSources)
+
+	for _, f := range goFiles {
+		pkgSources, ok := sources[f.pkg]
+		if !ok {
+			pkgSources = make(map[analyzer.Import][]analyzer.Location, len(f.imports))
+			sources[f.pkg] = pkgSources
+		}
+
+		for _, i := range f.imports {
+			loc := analyzer.Location{File: f.path, Line: f.importLines[i]}
+			if slices.Contains(pkgSources[i], loc) {
+				continue
+			}
+
+			pkgSources[i] = append(pkgSources[i], loc)
+		}
+	}
+
+	return sources
+}
+
+func (g *goAnalyzer) AnalyzeOrigins(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.ImportOrigins, error) {
+	goFiles, gomodPaths, err := g.analyzeModules(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return importOrigins(goFiles, gomodPaths), nil
+}
+
+func importOrigins(goFiles []goFile, gomodPaths map[directory]modulePath) analyzer.ImportOrigins {
+	origins := make(analyzer.ImportOrigins)
+
+	for _, f := range goFiles {
+		pkgOrigins, ok := origins[f.pkg]
+		if !ok {
+			pkgOrigins = make(map[analyzer.Import]analyzer.Origin, len(f.imports))
+			origins[f.pkg] = pkgOrigins
+		}
+
+		for _, i := range f.imports {
+			pkgOrigins[i] = importOrigin(i, f.module, gomodPaths)
+		}
+	}
+
+	return origins
+}
+
+// AnalyzePlatforms returns the build constraints of the files introducing every import
+// imports of a file building everywhere are not annotated
+func (g *goAnalyzer) AnalyzePlatforms(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.ImportPlatforms, error) {
+	goFiles, err := g.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return importPlatforms(goFiles), nil
+}
+
+func importPlatforms(goFiles []goFile) analyzer.ImportPlatforms {
+	constraints := make(map[analyzer.Package]map[analyzer.Import][]string)
+	everywhere := make(map[analyzer.Package]map[analyzer.Import]bool)
+
+	for _, f := range goFiles {
+		if _, ok := constraints[f.pkg]; !ok {
+			constraints[f.pkg] = make(map[analyzer.Import][]string, len(f.imports))
+			everywhere[f.pkg] = make(map[analyzer.Import]bool, len(f.imports))
+		}
+
+		for _, i := range f.imports {
+			if f.constraint == "" {
+				everywhere[f.pkg][i] = true
+				continue
+			}
+
+			if !slices.Contains(constraints[f.pkg][i], f.constraint) {
+				constraints[f.pkg][i] = append(constraints[f.pkg][i], f.constraint)
+			}
+		}
+	}
+
+	platforms := make(analyzer.ImportPlatforms)
+
+	for pkg, imports := range constraints {
+		for i, exprs := range imports {
+			if everywhere[pkg][i] {
+				continue
+			}
+
+			if _, ok := platforms[pkg]; !ok {
+				platforms[pkg] = make(map[analyzer.Import][]string)
+			}
+
+			slices.Sort(exprs)
+			platforms[pkg][i] = exprs
+		}
+	}
+
+	return platforms
+}
+
+// AnalyzeTests returns the imports only introduced by _test.go files
+// every import of an external test package e.g. store_test is only introduced by tests
+func (g *goAnalyzer) AnalyzeTests(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.TestImports, error) {
+	goFiles, err := g.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return testImports(goFiles), nil
+}
+
+func testImports(goFiles []goFile) analyzer.TestImports {
+	tested := make(map[analyzer.Package][]analyzer.Import)
+	production := make(map[analyzer.Package]map[analyzer.Import]bool)
+
+	for _, f := range goFiles {
+		if _, ok := production[f.pkg]; !ok {
+			production[f.pkg] = make(map[analyzer.Import]bool, len(f.imports))
+		}
+
+		for _, i := range f.imports {
+			if !f.test() {
+				production[f.pkg][i] = true
+				continue
+			}
+
+			if !slices.Contains(tested[f.pkg], i) {
+				tested[f.pkg] = append(tested[f.pkg], i)
+			}
+		}
+	}
+
+	tests := make(analyzer.TestImports)
+
+	for pkg, imports := range tested {
+		for _, i := range imports {
+			if !production[pkg][i] {
+				tests[pkg] = append(tests[pkg], i)
+			}
+		}
+	}
+
+	return tests
+}
+
+func (g *goAnalyzer) analyze(ctx context.Context, dir fs.FS) ([]goFile, error) {
+	goFiles, _, err := g.analyzeModules(ctx, dir)
+	return goFiles, err
+}
+
+func (g *goAnalyzer) analyzeModules(
+	ctx context.Context,
+	dir fs.FS,
+) ([]goFile, map[directory]modulePath, error) {
 	// find go.mod file(s) to get the module scope and path
 	// e.g. "." "github.com/flamingoosesoftwareinc/uda"
 	// e.g. "./go" "github.com/blahblahblah/asdf"
@@ -39,17 +397,58 @@ func (g *goAnalyzer) Analyze(ctx context.Context, dir fs.FS) (analyzer.PackageIm
 	// filter out file paths that are not .go
 	gomodFiles, err := listGomodFiles(ctx, dir)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	slog.DebugContext(ctx, "found go.mod files", "filepaths", gomodFiles)
 
 	gomodPaths, err := extractModulePaths(ctx, dir, gomodFiles)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	slog.DebugContext(ctx, "identified go module paths", "paths", gomodPaths)
 
+	build := buildContextOf(ctx)
+
+	goFilepaths, err := listGoFiles(ctx, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// tests are not part of the package the go command builds
+	if !build.Tests {
+		goFilepaths = slices.DeleteFunc(goFilepaths, isTestFile)
+	}
+
+	// a go.work file restricts the analysis to the modules listed by its use directives
+	// imports between the workspace modules are first-party as every module is analyzed
+	goworkFiles, err := listGoworkFiles(ctx, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(goworkFiles) > 0 {
+		workspaceModules, err := extractWorkspaceModules(ctx, dir, goworkFiles)
+		if err != nil {
+			return nil, nil, err
+		}
+		slog.DebugContext(
+			ctx,
+			"identified go workspace modules",
+			"gowork", goworkFiles,
+			"modules", workspaceModules,
+		)
+
+		goFilepaths = slices.DeleteFunc(goFilepaths, func(goFilepath string) bool {
+			return !inWorkspace(goFilepath, gomodPaths, workspaceModules)
+		})
+
+		maps.DeleteFunc(gomodPaths, func(d directory, _ modulePath) bool {
+			_, ok := workspaceModules[d]
+			return !ok
+		})
+	}
+
 	// per module scope, path
 	// read all files
 	// parse using treesitter
@@ -57,14 +456,192 @@ func (g *goAnalyzer) Analyze(ctx context.Context, dir fs.FS) (analyzer.PackageIm
 	// prefix package name based on module scope and package parent dirs
 	// e.g. if module is github.com/ahmedalhulaibi/foo and package is "cli", dir is "go/internal/cli" and it imports "treesitter", "context", "io/fs", "github.com/asdfasdf/aoiso"
 	// then the result would be "github.com/ahmedalhulaibi/foo/go/internal/cli": []string{"treesitter", "context", "io/fs", "github.com/asdfasdf/aoiso"}
+	//
+	// qualified types and selector expressions are captured per file so they can be
+	// resolved against the imports of the file they appear in
+	// e.g. "fmt.Println" in a file importing "fmt" is a use of the "fmt" package
+	goFiles, err := analyzeGoFiles(ctx, dir, goFilepaths, gomodPaths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// files are extracted whatever the platform so the parse cache serves every platform
+	goFiles = slices.DeleteFunc(goFiles, func(f goFile) bool {
+		return !build.matches(f.constraint)
+	})
+
+	if g.origins != nil {
+		for i := range goFiles {
+			goFiles[i].imports = slices.DeleteFunc(goFiles[i].imports, func(imp analyzer.Import) bool {
+				_, ok := g.origins[importOrigin(imp, goFiles[i].module, gomodPaths)]
+				return !ok
+			})
+		}
+	}
+
+	return goFiles, gomodPaths, nil
+}
+
+// importQualifiers maps the name a file refers to an import by to the import path
+// e.g. `"io/fs"` is referred to as "fs" and `f "fmt"` as "f"
+// imports no longer in imports e.g. filtered out by origin are not resolved, neither are blank imports
+// that are never referred to and dot imports whose uses are not qualified
+func importQualifiers(
+	imports []analyzer.Import,
+	aliases map[string]analyzer.Import,
+	unnamed []analyzer.Import,
+	names map[analyzer.Package]string,
+) map[string]analyzer.Package {
+	qualifiers := make(map[string]analyzer.Package, len(imports))
+	aliased := make(map[analyzer.Import]struct{}, len(aliases))
+
+	for name, i := range aliases {
+		if !slices.Contains(imports, i) {
+			continue
+		}
+
+		qualifiers[name] = i.Package()
+		aliased[i] = struct{}{}
+	}
+
+	for _, i := range imports {
+		if _, ok := aliased[i]; ok || slices.Contains(unnamed, i) {
+			continue
+		}
+
+		importPath := i.Package()
+		qualifiers[packageName(importPath, names)] = importPath
+	}
+
+	return qualifiers
+}
+
+// packageNames maps the packages of goFiles to the name of their package clause
+// and the imports of other packages to the name their importers refer to them by
+// e.g. "tree_sitter" for "github.com/tree-sitter/go-tree-sitter", see inferName
+func packageNames(goFiles []goFile) map[analyzer.Package]string {
+	names := make(map[analyzer.Package]string)
+	for _, f := range goFiles {
+		// an external test package can share the directory of the package it tests
+		if !f.test() {
+			names[f.importPath] = f.name
+		}
+	}
+
+	// a name inferred from a file can leave a single unknown import in another file
+	for inferred := true; inferred; {
+		inferred = false
+
+		for _, f := range goFiles {
+			if importPath, name, ok := inferName(f, names); ok {
+				names[importPath] = name
+				inferred = true
+			}
+		}
+	}
+
+	return names
+}
+
+// inferName returns the name of the only import of f whose name is neither declared nor the one of its path
+// an unaliased import has to be referred to by the name its package declares for the file to compile
+// so when a single qualifier of the uses of f resolves to no other import, it is that name
+// e.g. tree_sitter for "github.com/tree-sitter/go-tree-sitter" rather than tree
+func inferName(f goFile, names map[analyzer.Package]string) (analyzer.Package, string, bool) {
+	qualifiers := importQualifiers(f.imports, f.aliases, f.unnamed, names)
+
+	used := make(map[string]struct{}, len(f.uses))
+	for _, use := range f.uses {
+		if qualifier, _, ok := strings.Cut(use, "."); ok {
+			used[qualifier] = struct{}{}
+		}
+	}
+
+	aliased := slices.Collect(maps.Values(f.aliases))
+
+	var unknown []analyzer.Package
+	for _, i := range f.imports {
+		if slices.Contains(aliased, i) || slices.Contains(f.unnamed, i) {
+			continue
+		}
+
+		if _, ok := declaredName(i.Package(), names); ok {
+			continue
+		}
+
+		if _, ok := used[pathName(i.Package())]; ok {
+			continue
+		}
+
+		unknown = append(unknown, i.Package())
+	}
+
+	if len(unknown) != 1 {
+		return "", "", false
+	}
+
+	var unresolved []string
+	for qualifier := range used {
+		if _, ok := qualifiers[qualifier]; !ok {
+			unresolved = append(unresolved, qualifier)
+		}
+	}
+
+	if len(unresolved) != 1 {
+		return "", "", false
+	}
+
+	return unknown[0], unresolved[0], true
+}
+
+// packageName returns the name the package of importPath declares, or the name of its path when
+// neither its package clause nor its importers tell e.g. "cobra" for "github.com/spf13/cobra"
+func packageName(importPath analyzer.Package, names map[analyzer.Package]string) string {
+	if name, ok := declaredName(importPath, names); ok {
+		return name
+	}
+
+	return pathName(importPath)
+}
+
+// declaredName returns the name the package of importPath is known to declare
+// std packages are named after the last element of their path but its major version e.g. "rand"
+// for "math/rand/v2", the names of other packages are read from their package clause or inferred
+// from their importers by packageNames
+func declaredName(importPath analyzer.Package, names map[analyzer.Package]string) (string, bool) {
+	if name, ok := names[importPath]; ok {
+		return name, true
+	}
+
+	if _, ok := stdlib[string(importPath)]; !ok {
+		return "", false
+	}
+
+	return pathName(importPath), true
+}
+
+// pathName is the name a package is assumed to declare given its import path as goimports does
+// e.g. "fmt" for "fmt", "bar" for "example.com/bar/v2", "yaml" for "gopkg.in/yaml.v3"
+// and "color" for "github.com/fatih/go-color"
+func pathName(importPath analyzer.Package) string {
+	name := path.Base(string(importPath))
+
+	// major version suffix of a module e.g. example.com/bar/v2
+	if version, ok := strings.CutPrefix(name, "v"); ok && version != "" {
+		if _, err := strconv.Atoi(version); err == nil && path.Dir(string(importPath)) != "." {
+			name = path.Base(path.Dir(string(importPath)))
+		}
+	}
 
-	// TODO: count the number of imported types/functions and the number of exported types/functions
-	// wip query:
-	// (qualified_type (_ (package_identifier))) @package_type_use
-	// (selector_expression) @package_func_use
-	// need a query for capturing exported types
+	name = strings.TrimPrefix(name, "go-")
 
-	return analyzeGoFiles(ctx, dir, gomodPaths)
+	if i := strings.IndexFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}); i >= 0 {
+		name = name[:i]
+	}
+
+	return name
 }
 
 func listGomodFiles(ctx context.Context, dir fs.FS) ([]string, error) {
@@ -115,120 +692,212 @@ func extractModulePaths(
 	dir fs.FS,
 	gomodFilepaths []string,
 ) (map[directory]modulePath, error) {
-	tsparser := treesitter.NewParser()
-	defer tsparser.Close()
-
 	gomodLanguage := treesitter.NewLanguage(tsgomod.Language())
-	if err := tsparser.SetLanguage(gomodLanguage); err != nil {
+
+	query, err := ts.LoadQuery(ts.GOMOD, ts.ModuleQuery)
+	if err != nil {
 		return nil, err
 	}
 
-	query := `(module_directive (module_path) @module_path)`
-	gomodPaths := make(map[directory]modulePath, len(gomodFilepaths))
-
-	for _, gmFilepath := range gomodFilepaths {
-		tree, text, err := ts.Parse(ctx, tsparser, dir, gmFilepath)
-		if err != nil {
-			return nil, err
-		}
+	paths, err := ts.Map(
+		ctx,
+		gomodFilepaths,
+		func() (*ts.Worker, error) { return ts.NewWorker(gomodLanguage) },
+		func(ctx context.Context, w *ts.Worker, gmFilepath string) (modulePath, error) {
+			tree, text, err := w.Parse(ctx, dir, gmFilepath)
+			if err != nil {
+				return "", err
+			}
+			defer tree.Close()
 
-		q, qc, err := ts.Query(ctx, tsparser, gomodLanguage, tree, text, query)
-		if err != nil {
-			return nil, err
-		}
+			_, matches, err := w.Query(ctx, tree, text, query)
+			if err != nil {
+				return "", err
+			}
 
-		matches := qc.Matches(q, tree.RootNode(), text)
+			var module modulePath
 
-		for match := matches.Next(); match != nil; match = matches.Next() {
-			for _, capture := range match.Captures {
-				node := capture.Node
-				gomodPaths[directory(filepath.Dir(gmFilepath))] = modulePath(node.Utf8Text(text))
+			for match := matches.Next(); match != nil; match = matches.Next() {
+				for _, capture := range match.Captures {
+					node := capture.Node
+					module = modulePath(node.Utf8Text(text))
+				}
 			}
+
+			return module, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	gomodPaths := make(map[directory]modulePath, len(gomodFilepaths))
+	for i, gmFilepath := range gomodFilepaths {
+		if paths[i] != "" {
+			gomodPaths[directory(filepath.Dir(gmFilepath))] = paths[i]
 		}
 	}
 
 	return gomodPaths, nil
 }
 
+// goFile is what was extracted from a single .go file
+type goFile struct {
+	path string
+	// module path of the module the file belongs to, empty when there is no go.mod
+	module modulePath
+	pkg    analyzer.Package
+	// path the package is imported by e.g. example.com/app/go-util
+	// pkg differs when the package clause is not named after the directory e.g. example.com/app/go-util/util
+	importPath analyzer.Package
+	// name of the package clause e.g. "util" or "util_test"
+	name    string
+	imports []analyzer.Import
+	// line of the import spec of each import
+	importLines map[analyzer.Import]uint
+	// imports the file refers to by another name than the package name e.g. {"f": `"fmt"`}
+	// dot and blank imports are not aliases as their uses are not qualified
+	aliases map[string]analyzer.Import
+	// dot and blank imports e.g. `. "math"` and `_ "embed"`
+	unnamed []analyzer.Import
+	// qualified identifiers used in the file e.g. fmt.Println, analyzer.Package
+	uses []string
+	// number of top level type declarations and how many of them are interfaces
+	types, abstractTypes uint
+	// build constraint of the file e.g. "windows" or "linux && !cgo", empty when it builds everywhere
+	constraint string
+}
+
+// test reports whether the file is a test of its package
+func (f goFile) test() bool {
+	return isTestFile(f.path)
+}
+
+// external reports whether the file is a test of an external test package e.g. package store_test
+func (f goFile) external() bool {
+	return f.test() && strings.HasSuffix(f.name, "_test")
+}
+
+// isTestFile reports whether a .go file is only built by go test e.g. store_test.go
+func isTestFile(goFilepath string) bool {
+	return strings.HasSuffix(goFilepath, "_test.go")
+}
+
 func analyzeGoFiles(
 	ctx context.Context,
 	dir fs.FS,
+	goFilepaths []string,
 	gomodPaths map[directory]modulePath,
-) (analyzer.PackageImports, error) {
-	goFilepaths, err := listGoFiles(ctx, dir)
-	if err != nil {
-		return nil, err
-	}
-
-	tsparser := treesitter.NewParser()
-	defer tsparser.Close()
-
+) ([]goFile, error) {
 	goLanguage := treesitter.NewLanguage(tsgo.Language())
-	if err := tsparser.SetLanguage(goLanguage); err != nil {
+
+	query, err := ts.LoadQuery(ts.GO, ts.AnalyzeQuery)
+	if err != nil {
 		return nil, err
 	}
 
-	query := `
-(package_clause (package_identifier) @package) 
-(import_spec
-		path: (interpreted_string_literal) @import)
-(import_spec
-	  name: (package_identifier)
-	  path: (interpreted_string_literal)) @import_alias 
-(qualified_type 
-  package: (package_identifier)) @import_type_use
-(selector_expression) @import_func_use
-`
-
-	pi := make(analyzer.PackageImports)
-
-	for _, goFilepath := range goFilepaths {
-		pkgPathPrefix := getPkgPathPrefix(goFilepath, gomodPaths)
+	return ts.Map(
+		ctx,
+		goFilepaths,
+		func() (*ts.Worker, error) { return ts.NewWorker(goLanguage) },
+		func(ctx context.Context, w *ts.Worker, goFilepath string) (goFile, error) {
+			return analyzeGoFile(ctx, w, dir, goFilepath, gomodPaths, query)
+		},
+	)
+}
 
-		tree, text, err := ts.Parse(ctx, tsparser, dir, goFilepath)
-		if err != nil {
-			return nil, err
-		}
+// analyzeGoFile extracts the package, imports and uses of a single .go file
+func analyzeGoFile(
+	ctx context.Context,
+	w *ts.Worker,
+	dir fs.FS,
+	goFilepath string,
+	gomodPaths map[directory]modulePath,
+	query string,
+) (goFile, error) {
+	pkgPathPrefix := getPkgPathPrefix(goFilepath, gomodPaths)
 
-		q, qc, err := ts.Query(ctx, tsparser, goLanguage, tree, text, query)
-		if err != nil {
-			return nil, err
-		}
+	var module modulePath
+	if moduleDir, ok := fileModule(goFilepath, gomodPaths); ok {
+		module = gomodPaths[moduleDir]
+	}
 
-		captureNames := q.CaptureNames()
+	salt := string(pkgPathPrefix) + "\n" + string(module)
 
-		matches := qc.Matches(q, tree.RootNode(), text)
+	return ts.Extract(
+		ctx,
+		w,
+		dir,
+		goFilepath,
+		query,
+		salt,
+		func(q *treesitter.Query, matches treesitter.QueryMatches, text []byte) (goFile, error) {
+			captureNames := q.CaptureNames()
 
-		pkgPath := analyzer.Package("")
-		imports := make([]analyzer.Import, 0, 32)
+			pkgPath := analyzer.Package("")
+			var pkgName string
+			imports := make([]analyzer.Import, 0, 32)
+			importLines := make(map[analyzer.Import]uint)
+			aliases := make(map[string]analyzer.Import)
+			unnamed := make([]analyzer.Import, 0)
+			uses := make([]string, 0, 32)
+			var types, abstractTypes uint
 
-		for match := matches.Next(); match != nil; match = matches.Next() {
-			c := processCaptures(
-				match,
-				captureNames,
+			for match := matches.Next(); match != nil; match = matches.Next() {
+				c := processCaptures(
+					match,
+					captureNames,
+					pkgPath,
+					pkgPathPrefix,
+					text,
+				)
+				imports = append(imports, c.i...)
+				for idx, i := range c.i {
+					if _, ok := importLines[i]; !ok {
+						importLines[i] = c.importLines[idx]
+					}
+				}
+				for _, a := range c.aliases {
+					aliases[a.name] = a.imp
+				}
+				unnamed = append(unnamed, c.unnamed...)
+				uses = append(uses, c.qualifiedTypesUsed...)
+				uses = append(uses, c.selectExpressions...)
+				types += c.types
+				abstractTypes += c.abstractTypes
+				pkgPath = c.p
+				if c.name != "" {
+					pkgName = c.name
+				}
+			}
+			slog.DebugContext(
+				ctx,
+				"processed file",
+				"path",
+				goFilepath,
+				"pkgDetected",
 				pkgPath,
-				pkgPathPrefix,
-				text,
+				"imports",
+				imports,
 			)
-			imports = append(imports, c.i...)
-			pkgPath = c.p
-		}
-		slog.DebugContext(
-			ctx,
-			"processed file",
-			"path",
-			goFilepath,
-			"pkgDetected",
-			pkgPath,
-			"imports",
-			imports,
-		)
 
-		pi[pkgPath] = imports
-
-	}
-
-	return pi, nil
+			return goFile{
+				path:          goFilepath,
+				module:        module,
+				pkg:           pkgPath,
+				importPath:    analyzer.Package(pkgPathPrefix),
+				name:          pkgName,
+				imports:       imports,
+				importLines:   importLines,
+				aliases:       aliases,
+				unnamed:       unnamed,
+				uses:          uses,
+				types:         types,
+				abstractTypes: abstractTypes,
+				constraint:    fileConstraint(goFilepath, text),
+			}, nil
+		},
+	)
 }
 
 func getPkgPathPrefix(goFilepath string, gomodPaths map[directory]modulePath) modulePath {
@@ -253,12 +922,23 @@ func getPkgPathPrefix(goFilepath string, gomodPaths map[directory]modulePath) mo
 	return modulePath(gf)
 }
 
+// importAlias is a named import e.g. f "fmt"
+type importAlias struct {
+	name string
+	imp  analyzer.Import
+}
+
 type captures struct {
 	p                  analyzer.Package
+	name               string
 	i                  []analyzer.Import
-	aliases            []string
+	importLines        []uint
+	aliases            []importAlias
+	unnamed            []analyzer.Import
 	qualifiedTypesUsed []string
 	selectExpressions  []string
+	types              uint
+	abstractTypes      uint
 }
 
 func processCaptures(
@@ -269,9 +949,13 @@ func processCaptures(
 	text []byte,
 ) captures {
 	imports := make([]analyzer.Import, 0, 32)
-	aliases := make([]string, 0, 32)
+	importLines := make([]uint, 0, 32)
+	aliases := make([]importAlias, 0, 1)
+	unnamed := make([]analyzer.Import, 0)
+	var name string
 	qualifiedTypesUsed := make([]string, 0, 32)
 	selectExpressions := make([]string, 0, 32)
+	var types, abstractTypes uint
 
 	for _, capture := range match.Captures {
 		node := capture.Node
@@ -281,12 +965,19 @@ func processCaptures(
 		switch captureName {
 		case "package":
 			detectedPkgName := nodeStr
+			name = detectedPkgName
 			defaultPkgPath := string(pkgPathPrefix)
+			tested, test := strings.CutSuffix(detectedPkgName, "_test")
 
 			dir := filepath.Dir(defaultPkgPath)
-			if detectedPkgName == filepath.Base(defaultPkgPath) {
+			switch {
+			case detectedPkgName == filepath.Base(defaultPkgPath):
 				pkgPath = analyzer.Package(filepath.Join(dir, detectedPkgName))
-			} else {
+			case test && tested == filepath.Base(defaultPkgPath):
+				// an external test package is a package of its own next to the package it tests
+				// e.g. example.com/app/store_test rather than example.com/app/store/store_test
+				pkgPath = analyzer.Package(defaultPkgPath + "_test")
+			default:
 				pkgPath = analyzer.Package(filepath.Join(defaultPkgPath, detectedPkgName))
 			}
 
@@ -302,15 +993,51 @@ func processCaptures(
 		case "import":
 			slog.Debug("import detected", "import", nodeStr)
 			imports = append(imports, analyzer.Import(nodeStr))
+			importLines = append(importLines, node.StartPosition().Row+1)
 		case "alias":
 			slog.Debug("alias detected", "alias", nodeStr)
-			aliases = append(aliases, nodeStr)
+			// the alias is the name of an import_spec, the path is its sibling
+			spec := node.Parent()
+			if spec == nil {
+				continue
+			}
+
+			importPath := spec.ChildByFieldName("path")
+			if importPath == nil {
+				continue
+			}
+
+			imp := analyzer.Import(importPath.Utf8Text(text))
+			if nodeStr == "_" || nodeStr == "." {
+				unnamed = append(unnamed, imp)
+				continue
+			}
+
+			aliases = append(aliases, importAlias{name: nodeStr, imp: imp})
 		case "import_func_use":
 			slog.Debug("import_func_use detected", "expression", nodeStr)
-			selectExpressions = append(selectExpressions, nodeStr)
+			// a selector on a variable, parameter or receiver is not a package reference
+			// even when the variable has the name of an import
+			if operand := node.ChildByFieldName("operand"); operand != nil &&
+				shadowed(&node, operand.Utf8Text(text), text) {
+				continue
+			}
+			selectExpressions = append(
+				selectExpressions,
+				qualifiedName(&node, text, "operand", "field"),
+			)
 		case "import_type_use":
 			slog.Debug("import_type_use detected", "expression", nodeStr)
-			qualifiedTypesUsed = append(qualifiedTypesUsed, nodeStr)
+			qualifiedTypesUsed = append(
+				qualifiedTypesUsed,
+				qualifiedName(&node, text, "package", "name"),
+			)
+		case "type_declaration":
+			slog.Debug("type_declaration detected", "declaration", nodeStr)
+			types++
+		case "abstract_type_declaration":
+			slog.Debug("abstract_type_declaration detected", "declaration", nodeStr)
+			abstractTypes++
 		default:
 			slog.Debug(
 				"unknown capture name",
@@ -323,9 +1050,27 @@ func processCaptures(
 	}
 	return captures{
 		p:                  pkgPath,
+		name:               name,
 		i:                  imports,
+		importLines:        importLines,
 		aliases:            aliases,
+		unnamed:            unnamed,
 		qualifiedTypesUsed: qualifiedTypesUsed,
 		selectExpressions:  selectExpressions,
+		types:              types,
+		abstractTypes:      abstractTypes,
 	}
 }
+
+// qualifiedName joins the qualifier and name fields of a node e.g. "fmt.Println"
+// built from the fields rather than the node text so whitespace and comments are dropped
+func qualifiedName(node *treesitter.Node, text []byte, qualifierField, nameField string) string {
+	qualifier := node.ChildByFieldName(qualifierField)
+	name := node.ChildByFieldName(nameField)
+
+	if qualifier == nil || name == nil {
+		return node.Utf8Text(text)
+	}
+
+	return qualifier.Utf8Text(text) + "." + name.Utf8Text(text)
+}
@@ -3,6 +3,7 @@ package golang
 import (
 	"testing"
 
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
 	"github.com/stretchr/testify/require"
 )
 
@@ -70,3 +71,140 @@ func TestGetPkgPathPrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestParseGowork(t *testing.T) {
+	tests := map[string]struct {
+		gowork string
+		want   []string
+	}{
+		"should parse use block": {
+			gowork: "go 1.21\n\nuse (\n\t./foobarbaz\n\t./cowsay // the cow\n)\n",
+			want:   []string{"./foobarbaz", "./cowsay"},
+		},
+		"should parse single use directives": {
+			gowork: "go 1.21\n\nuse ./foo\nuse\t\"./bar baz\"\n",
+			want:   []string{"./foo", "./bar baz"},
+		},
+		"should ignore other directives": {
+			gowork: "go 1.21\n\nuse(\n\t.\n)\n\nreplace example.com/user => ./user\n",
+			want:   []string{"."},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, parseGowork([]byte(tt.gowork)))
+		})
+	}
+}
+
+func TestInWorkspace(t *testing.T) {
+	gomodPaths := map[directory]modulePath{
+		"cowsay":        "example.com/cowsay",
+		"cowsay/legacy": "example.com/cowsay/legacy",
+		"unused":        "example.com/unused",
+	}
+	workspaceModules := map[directory]struct{}{
+		"cowsay": {},
+	}
+
+	require.True(t, inWorkspace("cowsay/main.go", gomodPaths, workspaceModules))
+	require.True(t, inWorkspace("cowsay/cmd/cmd.go", gomodPaths, workspaceModules))
+	require.False(t, inWorkspace("cowsay/legacy/main.go", gomodPaths, workspaceModules))
+	require.False(t, inWorkspace("unused/main.go", gomodPaths, workspaceModules))
+	require.False(t, inWorkspace("tools/main.go", gomodPaths, workspaceModules))
+}
+
+func TestPackageName(t *testing.T) {
+	names := map[analyzer.Package]string{
+		"example.com/app/go-util":               "util",
+		"github.com/tree-sitter/go-tree-sitter": "tree_sitter",
+	}
+
+	tests := map[string]struct {
+		importPath string
+		want       string
+	}{
+		"should return the last element of std":        {importPath: "io/fs", want: "fs"},
+		"should return a single element of std":        {importPath: "fmt", want: "fmt"},
+		"should skip the major version of std":         {importPath: "math/rand/v2", want: "rand"},
+		"should return the name of the package clause": {importPath: "example.com/app/go-util", want: "util"},
+		"should return an inferred name":               {importPath: "github.com/tree-sitter/go-tree-sitter", want: "tree_sitter"},
+		"should fall back to the last element":         {importPath: "github.com/spf13/cobra", want: "cobra"},
+		"should skip the major version of a module":    {importPath: "example.com/bar/v2", want: "bar"},
+		"should not skip a v element without digits":   {importPath: "example.com/bar/view", want: "view"},
+		"should drop the version of a gopkg.in path":   {importPath: "gopkg.in/yaml.v3", want: "yaml"},
+		"should drop the go- prefix":                   {importPath: "github.com/fatih/go-color", want: "color"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, packageName(analyzer.Package(tt.importPath), names))
+		})
+	}
+}
+
+func TestPackageNames(t *testing.T) {
+	goFiles := []goFile{
+		{pkg: "example.com/app/go-util/util", importPath: "example.com/app/go-util", name: "util"},
+		{
+			// tree-sitter is not referred to by the name of its path
+			pkg:        "example.com/app/parse",
+			importPath: "example.com/app/parse",
+			name:       "parse",
+			imports:    []analyzer.Import{`"fmt"`, `"github.com/tree-sitter/go-tree-sitter"`},
+			uses:       []string{"fmt.Println", "tree_sitter.NewParser", "tree_sitter.Node"},
+		},
+		{
+			pkg:        "example.com/app/cli",
+			importPath: "example.com/app/cli",
+			name:       "cli",
+			imports: []analyzer.Import{
+				`"fmt"`,
+				`"github.com/spf13/cobra"`,
+				`"github.com/spf13/viper"`,
+				`"example.com/app/go-util"`,
+				`"github.com/lib/pq"`,
+			},
+			unnamed: []analyzer.Import{`"github.com/lib/pq"`},
+			uses: []string{
+				"fmt.Println",
+				"cobra.Command",
+				"viper.GetBool",
+				"util.Trim",
+				"tree_sitter.Node",
+			},
+		},
+	}
+
+	names := packageNames(goFiles)
+
+	got := make(map[analyzer.Package]string)
+	for _, f := range goFiles {
+		for _, i := range f.imports {
+			got[i.Package()] = packageName(i.Package(), names)
+		}
+	}
+
+	require.Equal(t, map[analyzer.Package]string{
+		"fmt":                                   "fmt",
+		"example.com/app/go-util":               "util",
+		"github.com/tree-sitter/go-tree-sitter": "tree_sitter",
+		"github.com/spf13/cobra":                "cobra",
+		"github.com/spf13/viper":                "viper",
+		"github.com/lib/pq":                     "pq",
+	}, got)
+
+	// the coupling to every third-party package is counted
+	metrics := buildMetrics(goFiles)
+	require.Equal(t, analyzer.PackageCouplingStats{
+		"fmt":                     {"fmt.Println": {Count: 1}},
+		"github.com/spf13/cobra":  {"cobra.Command": {Count: 1}},
+		"github.com/spf13/viper":  {"viper.GetBool": {Count: 1}},
+		"example.com/app/go-util": {"util.Trim": {Count: 1}},
+	}, metrics[0].Outward)
+}
@@ -58,6 +58,8 @@ func TestGoAnalyze(t *testing.T) {
 				},
 				"example.com/project_gomod/cmd": []analyzer.Import{
 					`"fmt"`,
+					`"example.com/project_gomod/internal/foo"`,
+					`"example.com/project_gomod/internal/bar"`,
 				},
 				"example.com/project_gomod/internal/foo": []analyzer.Import{
 					`"fmt"`,
@@ -86,6 +88,7 @@ func TestGoAnalyze(t *testing.T) {
 				"example.com/cowsay/cmd": []analyzer.Import{
 					`"fmt"`,
 					`"example.com/cowsay/moo"`,
+					`"example.com/foobarbaz/pkg/banner"`,
 				},
 				"example.com/cowsay/moo": []analyzer.Import{
 					`"fmt"`,
@@ -94,6 +97,9 @@ func TestGoAnalyze(t *testing.T) {
 					`"fmt"`,
 					`"example.com/foobarbaz/internal/greet"`,
 				},
+				"example.com/foobarbaz/pkg/banner": []analyzer.Import{
+					`"strings"`,
+				},
 			},
 		},
 	}
@@ -109,3 +115,445 @@ func TestGoAnalyze(t *testing.T) {
 		})
 	}
 }
+
+func TestGoAnalyzeSources(t *testing.T) {
+	dir := os.DirFS(".testdata/project_gomod")
+	got, err := golang.GoAnalyzer().AnalyzeSources(context.Background(), dir)
+	require.NoError(t, err)
+
+	require.Equal(t, analyzer.ImportSources{
+		"example.com/project_gomod/main": {
+			`"example.com/project_gomod/cmd"`: {{File: "main.go", Line: 4}},
+		},
+		"example.com/project_gomod/cmd": {
+			`"fmt"`: {{File: "cmd/blah.go", Line: 4}, {File: "cmd/root.go", Line: 3}},
+			`"example.com/project_gomod/internal/foo"`: {{File: "cmd/blah.go", Line: 6}},
+			`"example.com/project_gomod/internal/bar"`: {{File: "cmd/blah.go", Line: 7}},
+		},
+		"example.com/project_gomod/internal/foo": {
+			`"fmt"`: {{File: "internal/foo/foo.go", Line: 3}},
+		},
+		"example.com/project_gomod/internal/bar": {
+			`"fmt"`: {{File: "internal/bar/bar.go", Line: 4}},
+			`"example.com/project_gomod/internal/bar/baz"`: {
+				{File: "internal/bar/bar.go", Line: 6},
+			},
+		},
+		"example.com/project_gomod/internal/bar/baz": {
+			`"fmt"`: {{File: "internal/bar/baz/baz.go", Line: 3}},
+		},
+	}, got)
+}
+
+func TestGoAnalyzeOrigins(t *testing.T) {
+	tests := map[string]struct {
+		dir  string
+		want analyzer.ImportOrigins
+	}{
+		"simple_nomod": {
+			dir: ".testdata/simple_nomod",
+			want: analyzer.ImportOrigins{
+				"main": {`"fmt"`: analyzer.Std},
+			},
+		},
+		"project_gomod": {
+			dir: ".testdata/project_gomod",
+			want: analyzer.ImportOrigins{
+				"example.com/project_gomod/main": {
+					`"example.com/project_gomod/cmd"`: analyzer.FirstParty,
+				},
+				"example.com/project_gomod/cmd": {
+					`"fmt"`: analyzer.Std,
+					`"example.com/project_gomod/internal/foo"`: analyzer.FirstParty,
+					`"example.com/project_gomod/internal/bar"`: analyzer.FirstParty,
+				},
+				"example.com/project_gomod/internal/foo": {
+					`"fmt"`: analyzer.Std,
+				},
+				"example.com/project_gomod/internal/bar": {
+					`"fmt"`: analyzer.Std,
+					`"example.com/project_gomod/internal/bar/baz"`: analyzer.FirstParty,
+				},
+				"example.com/project_gomod/internal/bar/baz": {
+					`"fmt"`: analyzer.Std,
+				},
+			},
+		},
+		"project_goworkspace": {
+			dir: ".testdata/project_goworkspace",
+			want: analyzer.ImportOrigins{
+				"example.com/cowsay/main": {
+					`"fmt"`:                    analyzer.Std,
+					`"os"`:                     analyzer.Std,
+					`"example.com/cowsay/cmd"`: analyzer.FirstParty,
+					`"example.com/cowsay/moo"`: analyzer.FirstParty,
+				},
+				"example.com/cowsay/cmd": {
+					`"fmt"`:                              analyzer.Std,
+					`"example.com/cowsay/moo"`:           analyzer.FirstParty,
+					`"example.com/foobarbaz/pkg/banner"`: analyzer.Workspace,
+				},
+				"example.com/cowsay/moo": {
+					`"fmt"`: analyzer.Std,
+				},
+				"example.com/foobarbaz/main": {
+					`"fmt"`:                                  analyzer.Std,
+					`"example.com/foobarbaz/internal/greet"`: analyzer.FirstParty,
+				},
+				"example.com/foobarbaz/internal/greet": {
+					`"fmt"`: analyzer.Std,
+				},
+				"example.com/foobarbaz/pkg/banner": {
+					`"strings"`: analyzer.Std,
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := os.DirFS(tt.dir)
+			got, err := golang.GoAnalyzer().AnalyzeOrigins(context.Background(), dir)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGoAnalyzeWithOrigins(t *testing.T) {
+	dir := os.DirFS(".testdata/project_goworkspace")
+	got, err := golang.GoAnalyzer(
+		golang.WithOrigins(analyzer.FirstParty, analyzer.Workspace),
+	).AnalyzeV2(context.Background(), dir)
+	require.NoError(t, err)
+
+	outward := make(map[analyzer.Package]analyzer.PackageCouplingStats, len(got))
+	for _, m := range got {
+		outward[m.Package] = m.Outward
+	}
+
+	require.Equal(t, map[analyzer.Package]analyzer.PackageCouplingStats{
+		"example.com/cowsay/main": {
+			"example.com/cowsay/cmd": {"cmd.Run": {Count: 1}},
+			"example.com/cowsay/moo": {"moo.Say": {Count: 1}},
+		},
+		"example.com/cowsay/cmd": {
+			"example.com/cowsay/moo":           {"moo.Say": {Count: 1}},
+			"example.com/foobarbaz/pkg/banner": {"banner.Wrap": {Count: 1}},
+		},
+		"example.com/cowsay/moo": {},
+		"example.com/foobarbaz/main": {
+			"example.com/foobarbaz/internal/greet": {"greet.Hello": {Count: 1}},
+		},
+		"example.com/foobarbaz/internal/greet": {},
+		"example.com/foobarbaz/pkg/banner":     {},
+	}, outward)
+}
+
+func TestGoAnalyzeBuildContext(t *testing.T) {
+	tests := map[string]struct {
+		build golang.BuildContext
+		want  analyzer.PackageImports
+	}{
+		"linux": {
+			build: golang.BuildContext{GOOS: "linux", GOARCH: "amd64"},
+			want: analyzer.PackageImports{
+				"example.com/platforms/fs": {`"io/fs"`, `"strings"`, `"golang.org/x/sys/unix"`},
+			},
+		},
+		"linux arm64": {
+			build: golang.BuildContext{GOOS: "linux", GOARCH: "arm64"},
+			want: analyzer.PackageImports{
+				"example.com/platforms/fs": {`"io/fs"`, `"strings"`},
+			},
+		},
+		"android satisfies linux": {
+			build: golang.BuildContext{GOOS: "android", GOARCH: "amd64"},
+			want: analyzer.PackageImports{
+				"example.com/platforms/fs": {`"io/fs"`, `"strings"`, `"golang.org/x/sys/unix"`},
+			},
+		},
+		"windows": {
+			build: golang.BuildContext{GOOS: "windows", GOARCH: "amd64"},
+			want: analyzer.PackageImports{
+				"example.com/platforms/fs": {`"io/fs"`, `"syscall"`},
+			},
+		},
+		"tags": {
+			build: golang.BuildContext{GOOS: "windows", GOARCH: "amd64", Tags: []string{"integration"}},
+			want: analyzer.PackageImports{
+				"example.com/platforms/fs": {`"io/fs"`, `"testing/fstest"`, `"syscall"`},
+			},
+		},
+		"all platforms": {
+			build: golang.BuildContext{AllPlatforms: true},
+			want: analyzer.PackageImports{
+				"example.com/platforms/fs": {
+					`"io/fs"`,
+					`"testing/fstest"`,
+					`"strings"`,
+					`"syscall"`,
+					`"golang.org/x/sys/unix"`,
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := golang.WithBuildContext(context.Background(), tt.build)
+			got, err := golang.GoAnalyzer().Analyze(ctx, os.DirFS(".testdata/platforms"))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGoAnalyzePlatforms(t *testing.T) {
+	ctx := golang.WithBuildContext(context.Background(), golang.BuildContext{AllPlatforms: true})
+
+	got, err := golang.GoAnalyzer().AnalyzePlatforms(ctx, os.DirFS(".testdata/platforms"))
+	require.NoError(t, err)
+	require.Equal(t, analyzer.ImportPlatforms{
+		"example.com/platforms/fs": {
+			`"testing/fstest"`:        {"integration"},
+			`"strings"`:               {"unix"},
+			`"syscall"`:               {"windows"},
+			`"golang.org/x/sys/unix"`: {"linux && amd64"},
+		},
+	}, got)
+}
+
+func TestGoAnalyzeTests(t *testing.T) {
+	dir := os.DirFS(".testdata/tests")
+	ctx := golang.WithBuildContext(context.Background(), golang.BuildContext{Tests: true})
+
+	t.Run("excluded by default", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := golang.GoAnalyzer().Analyze(context.Background(), dir)
+		require.NoError(t, err)
+		require.Equal(t, analyzer.PackageImports{
+			"example.com/tests/store": {`"database/sql"`},
+		}, got)
+	})
+
+	t.Run("included", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := golang.GoAnalyzer().Analyze(ctx, dir)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []packageImports{
+			{Pkg: "example.com/tests/store", Imports: []analyzer.Import{`"database/sql"`, `"testing"`}},
+			{Pkg: "example.com/tests/store_test", Imports: []analyzer.Import{
+				`"example.com/tests/store"`,
+				`"github.com/stretchr/testify/require"`,
+				`"testing"`,
+			}},
+		}, toSortedSlice(got))
+	})
+
+	t.Run("test only imports", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := golang.GoAnalyzer().AnalyzeTests(ctx, dir)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []packageImports{
+			{Pkg: "example.com/tests/store", Imports: []analyzer.Import{`"testing"`}},
+			{Pkg: "example.com/tests/store_test", Imports: []analyzer.Import{
+				`"example.com/tests/store"`,
+				`"github.com/stretchr/testify/require"`,
+				`"testing"`,
+			}},
+		}, toSortedSlice(analyzer.PackageImports(got)))
+	})
+
+	t.Run("metrics keep tests apart", func(t *testing.T) {
+		t.Parallel()
+
+		production, err := golang.GoAnalyzer().AnalyzeV2(context.Background(), dir)
+		require.NoError(t, err)
+		require.Equal(t, []analyzer.Metrics{{
+			Package:    "example.com/tests/store",
+			Inward:     analyzer.PackageCouplingStats{},
+			Outward:    analyzer.PackageCouplingStats{"database/sql": {"sql.DB": {Count: 2}}},
+			TotalTypes: 1,
+		}}, production)
+
+		got, err := golang.GoAnalyzer().AnalyzeV2(ctx, dir)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []analyzer.Metrics{
+			{
+				Package:    "example.com/tests/store",
+				Inward:     analyzer.PackageCouplingStats{},
+				Outward:    analyzer.PackageCouplingStats{"database/sql": {"sql.DB": {Count: 2}}},
+				TotalTypes: 1,
+				Tests:      analyzer.PackageCouplingStats{"testing": {"testing.T": {Count: 1}}},
+			},
+			{
+				Package: "example.com/tests/store_test",
+				Inward:  analyzer.PackageCouplingStats{},
+				Outward: analyzer.PackageCouplingStats{
+					"example.com/tests/store":             {"store.Open": {Count: 1}},
+					"github.com/stretchr/testify/require": {"require.NotNil": {Count: 1}},
+					"testing":                             {"testing.T": {Count: 1}},
+				},
+			},
+		}, got)
+	})
+}
+
+func TestGoAnalyzeV2(t *testing.T) {
+	tests := map[string]struct {
+		dir  string
+		want []analyzer.Metrics
+	}{
+		"simple_gomod": {
+			dir: ".testdata/simple_gomod",
+			want: []analyzer.Metrics{
+				{
+					Package: "example.com/simple_gomod/main",
+					Inward:  analyzer.PackageCouplingStats{},
+					Outward: analyzer.PackageCouplingStats{
+						"fmt": {"fmt.Println": {Count: 1}},
+					},
+				},
+			},
+		},
+		"project_gomod": {
+			dir: ".testdata/project_gomod",
+			want: []analyzer.Metrics{
+				{
+					Package: "example.com/project_gomod/cmd",
+					Inward: analyzer.PackageCouplingStats{
+						"example.com/project_gomod/main": {"cmd.Execute": {Count: 1}},
+					},
+					Outward: analyzer.PackageCouplingStats{
+						"fmt":                                    {"fmt.Println": {Count: 2}},
+						"example.com/project_gomod/internal/foo": {"foo.DoFoo": {Count: 1}},
+						"example.com/project_gomod/internal/bar": {"bar.DoBar": {Count: 1}},
+					},
+				},
+				{
+					Package: "example.com/project_gomod/internal/bar",
+					Inward: analyzer.PackageCouplingStats{
+						"example.com/project_gomod/cmd": {"bar.DoBar": {Count: 1}},
+					},
+					Outward: analyzer.PackageCouplingStats{
+						"fmt": {"fmt.Println": {Count: 1}},
+						"example.com/project_gomod/internal/bar/baz": {"baz.DoBaz": {Count: 1}},
+					},
+				},
+				{
+					Package: "example.com/project_gomod/internal/bar/baz",
+					Inward: analyzer.PackageCouplingStats{
+						"example.com/project_gomod/internal/bar": {"baz.DoBaz": {Count: 1}},
+					},
+					Outward: analyzer.PackageCouplingStats{
+						"fmt": {"fmt.Println": {Count: 1}},
+					},
+				},
+				{
+					Package: "example.com/project_gomod/internal/foo",
+					Inward: analyzer.PackageCouplingStats{
+						"example.com/project_gomod/cmd": {"foo.DoFoo": {Count: 1}},
+					},
+					Outward: analyzer.PackageCouplingStats{
+						"fmt": {"fmt.Println": {Count: 1}},
+					},
+				},
+				{
+					Package: "example.com/project_gomod/main",
+					Inward:  analyzer.PackageCouplingStats{},
+					Outward: analyzer.PackageCouplingStats{
+						"example.com/project_gomod/cmd": {"cmd.Execute": {Count: 1}},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := os.DirFS(tt.dir)
+			got, err := golang.GoAnalyzer().AnalyzeV2(context.Background(), dir)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGoAnalyzeV2Aliases(t *testing.T) {
+	dir := os.DirFS(".testdata/aliases")
+	got, err := golang.GoAnalyzer().AnalyzeV2(context.Background(), dir)
+	require.NoError(t, err)
+
+	// uses are keyed by package name whatever the alias, dot and blank imports have no qualified uses
+	// and selectors on a parameter named as an import are not uses
+	// the names of third-party packages are inferred from codec.go then main.go
+	require.Equal(t, []analyzer.Metrics{
+		{
+			Package: "example.com/aliases/lib/go-util/util",
+			Inward:  analyzer.PackageCouplingStats{},
+			Outward: analyzer.PackageCouplingStats{
+				"strings": {"strings.TrimSpace": {Count: 1}},
+			},
+		},
+		{
+			Package: "example.com/aliases/main",
+			Inward:  analyzer.PackageCouplingStats{},
+			Outward: analyzer.PackageCouplingStats{
+				"fmt":                             {"fmt.Println": {Count: 1}},
+				"strings":                         {"strings.ToUpper": {Count: 2}},
+				"example.com/aliases/lib/go-util": {"util.Trim": {Count: 1}},
+				"github.com/acme/api/v2":          {"api.New": {Count: 1}, "api.Server": {Count: 2}},
+				"gopkg.in/yaml.v3":                {"yaml.Marshal": {Count: 1}, "yaml.Unmarshal": {Count: 1}},
+			},
+			TotalTypes: 1,
+		},
+	}, got)
+}
+
+func TestGoAnalyzeV2Abstractness(t *testing.T) {
+	dir := os.DirFS(".testdata/abstractness")
+	got, err := golang.GoAnalyzer().AnalyzeV2(context.Background(), dir)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	require.Equal(t, analyzer.Package("example.com/abstractness/shape"), got[0].Package)
+	require.Equal(t, uint(2), got[0].AbstractTypes)
+	require.Equal(t, uint(5), got[0].TotalTypes)
+	require.InDelta(t, 0.4, got[0].Abstractness(), 0.0001)
+	require.InDelta(t, 0.6, got[0].Distance(), 0.0001)
+}
+
+func TestGoAnalyzeAll(t *testing.T) {
+	ctx := context.Background()
+	dir := os.DirFS(".testdata/project_goworkspace")
+	a := golang.GoAnalyzer()
+
+	got, err := a.AnalyzeAll(ctx, dir)
+	require.NoError(t, err)
+
+	pi, err := a.Analyze(ctx, dir)
+	require.NoError(t, err)
+	require.Equal(t, pi, got.Imports)
+
+	metrics, err := a.AnalyzeV2(ctx, dir)
+	require.NoError(t, err)
+	require.Equal(t, metrics, got.Metrics)
+
+	sources, err := a.AnalyzeSources(ctx, dir)
+	require.NoError(t, err)
+	require.Equal(t, sources, got.Sources)
+
+	origins, err := a.AnalyzeOrigins(ctx, dir)
+	require.NoError(t, err)
+	require.Equal(t, origins, got.Origins)
+}
@@ -0,0 +1,137 @@
+package golang
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/files"
+)
+
+func listGoworkFiles(ctx context.Context, dir fs.FS) ([]string, error) {
+	return files.ListFiles(
+		ctx,
+		dir,
+		files.SkipHiddenDirs(),
+		files.SkipHiddenFiles(),
+		goworkFileFilter(),
+	)
+}
+
+func goworkFileFilter() files.FileFilter {
+	return func(path string, d fs.DirEntry) bool {
+		if d.IsDir() {
+			return false
+		}
+		return filepath.Base(path) != "go.work"
+	}
+}
+
+// extractWorkspaceModules returns the module directories listed by the use directives of every go.work file
+// directories are relative to dir e.g. "cowsay" for `use ./cowsay` in "go.work"
+func extractWorkspaceModules(
+	ctx context.Context,
+	dir fs.FS,
+	goworkFilepaths []string,
+) (map[directory]struct{}, error) {
+	modules := make(map[directory]struct{})
+
+	for _, gwFilepath := range goworkFilepaths {
+		f, err := dir.Open(gwFilepath)
+		if err != nil {
+			return nil, err
+		}
+
+		text, err := io.ReadAll(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, use := range parseGowork(text) {
+			if path.IsAbs(use) || filepath.IsAbs(use) {
+				slog.DebugContext(
+					ctx,
+					"skipping absolute workspace module",
+					"gowork", gwFilepath,
+					"use", use,
+				)
+				continue
+			}
+
+			moduleDir := path.Join(path.Dir(gwFilepath), filepath.ToSlash(use))
+			modules[directory(moduleDir)] = struct{}{}
+		}
+	}
+
+	return modules, nil
+}
+
+// parseGowork extracts the paths of the use directives in a go.work file
+// both the single line `use ./foo` and block `use ( ./foo )` forms are supported
+// the tree-sitter gomod grammar does not support go.work files so this is a small line based parser
+func parseGowork(text []byte) []string {
+	uses := []string{}
+	inUseBlock := false
+
+	for line := range strings.Lines(string(text)) {
+		line, _, _ = strings.Cut(line, "//")
+		line = strings.TrimSpace(line)
+
+		if inUseBlock {
+			switch line {
+			case ")":
+				inUseBlock = false
+			case "":
+			default:
+				uses = append(uses, unquoteGoworkPath(line))
+			}
+
+			continue
+		}
+
+		rest, ok := strings.CutPrefix(line, "use")
+		if !ok || (rest != "" && !strings.ContainsAny(rest[:1], " \t(")) {
+			continue
+		}
+
+		rest = strings.TrimSpace(rest)
+		switch rest {
+		case "(":
+			inUseBlock = true
+		case "":
+		default:
+			uses = append(uses, unquoteGoworkPath(rest))
+		}
+	}
+
+	return uses
+}
+
+func unquoteGoworkPath(p string) string {
+	if unquoted, err := strconv.Unquote(p); err == nil {
+		return unquoted
+	}
+
+	return p
+}
+
+// inWorkspace reports whether goFilepath belongs to one of the workspace modules
+func inWorkspace(
+	goFilepath string,
+	gomodPaths map[directory]modulePath,
+	workspaceModules map[directory]struct{},
+) bool {
+	moduleDir, ok := fileModule(goFilepath, gomodPaths)
+	if !ok {
+		return false
+	}
+
+	_, ok = workspaceModules[moduleDir]
+	return ok
+}
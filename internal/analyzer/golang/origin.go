@@ -0,0 +1,73 @@
+package golang
+
+import (
+	_ "embed"
+	"path"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+)
+
+//go:generate sh -c "go list -e std | grep -v -e '^internal/' -e '/internal/' -e '/internal$' -e '^vendor/' > stdlib.txt"
+//go:embed stdlib.txt
+var stdlibList string
+
+// stdlib is the set of importable standard library packages
+var stdlib = func() map[string]struct{} {
+	pkgs := make(map[string]struct{})
+	for _, pkg := range strings.Fields(stdlibList) {
+		pkgs[pkg] = struct{}{}
+	}
+
+	return pkgs
+}()
+
+// importOrigin classifies an import of a file in module fileModule against the analyzed go modules
+// e.g. "fmt" is std, a package of fileModule is first-party and a package of any other analyzed module
+// e.g. a sibling module of a go workspace is workspace
+func importOrigin(
+	i analyzer.Import,
+	fileModule modulePath,
+	gomodPaths map[directory]modulePath,
+) analyzer.Origin {
+	importPath := string(i.Package())
+
+	// the longest matching module wins as modules can be nested
+	var importModule modulePath
+	for _, modPath := range gomodPaths {
+		if len(modPath) > len(importModule) && withinModule(importPath, modPath) {
+			importModule = modPath
+		}
+	}
+
+	switch {
+	case importModule != "" && importModule == fileModule:
+		return analyzer.FirstParty
+	case importModule != "":
+		return analyzer.Workspace
+	}
+
+	if _, ok := stdlib[importPath]; ok {
+		return analyzer.Std
+	}
+
+	return analyzer.ThirdParty
+}
+
+func withinModule(importPath string, modPath modulePath) bool {
+	return importPath == string(modPath) || strings.HasPrefix(importPath, string(modPath)+"/")
+}
+
+// fileModule returns the path of the module goFilepath belongs to
+// the module of a file is the closest directory containing a go.mod file
+func fileModule(goFilepath string, gomodPaths map[directory]modulePath) (directory, bool) {
+	for fileDir := path.Dir(goFilepath); ; fileDir = path.Dir(fileDir) {
+		if _, ok := gomodPaths[directory(fileDir)]; ok {
+			return directory(fileDir), true
+		}
+
+		if fileDir == "." {
+			return "", false
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package golang
+
+import (
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// shadowed reports whether name is declared in a scope enclosing node before node
+// e.g. the path of path.Join is a local variable rather than the "path" import when a parameter is named path
+// package level declarations are not checked as they cannot share the name of an import
+func shadowed(node *treesitter.Node, name string, text []byte) bool {
+	start := node.StartByte()
+
+	for scope := node.Parent(); scope != nil; scope = scope.Parent() {
+		if scope.Kind() == "source_file" {
+			return false
+		}
+
+		for i := range scope.NamedChildCount() {
+			child := scope.NamedChild(i)
+			// a declaration is in scope after it ends, x := x.Foo() uses the x of the enclosing scope
+			if child.EndByte() > start {
+				break
+			}
+
+			if declares(child, name, text) {
+				return true
+			}
+
+			// alias of a type switch e.g. v of switch v := x.(type)
+			if scope.FieldNameForNamedChild(uint32(i)) == "alias" && namedBy(child, "", name, text) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// declares reports whether the statement or clause node declares name in the scope it belongs to
+// e.g. the parameters of a function, the initializer of an if or the names of a short variable declaration
+func declares(node *treesitter.Node, name string, text []byte) bool {
+	switch node.Kind() {
+	case "parameter_list", "type_parameter_list", "var_declaration", "var_spec_list", "const_declaration",
+		"type_declaration":
+		for i := range node.NamedChildCount() {
+			if declares(node.NamedChild(i), name, text) {
+				return true
+			}
+		}
+	case "parameter_declaration", "variadic_parameter_declaration", "type_parameter_declaration",
+		"var_spec", "const_spec", "type_spec", "type_alias":
+		return namedBy(node, "name", name, text)
+	case "short_var_declaration", "range_clause", "receive_statement":
+		return namedBy(node.ChildByFieldName("left"), "", name, text)
+	case "for_clause":
+		if initializer := node.ChildByFieldName("initializer"); initializer != nil {
+			return declares(initializer, name, text)
+		}
+	}
+
+	return false
+}
+
+// namedBy reports whether a child of node is the identifier name, only the children of field when set
+func namedBy(node *treesitter.Node, field string, name string, text []byte) bool {
+	if node == nil {
+		return false
+	}
+
+	for i := range node.NamedChildCount() {
+		if field != "" && node.FieldNameForNamedChild(uint32(i)) != field {
+			continue
+		}
+
+		if child := node.NamedChild(i); child.Utf8Text(text) == name {
+			return true
+		}
+	}
+
+	return false
+}
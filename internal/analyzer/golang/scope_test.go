@@ -0,0 +1,122 @@
+package golang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+	tsgo "github.com/tree-sitter/tree-sitter-go/bindings/go"
+)
+
+// selector returns the first selector expression on operand in node
+func selector(node *treesitter.Node, operand string, text []byte) *treesitter.Node {
+	if node.Kind() == "selector_expression" && node.ChildByFieldName("operand").Utf8Text(text) == operand {
+		return node
+	}
+
+	for i := range node.NamedChildCount() {
+		if found := selector(node.NamedChild(i), operand, text); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+func TestShadowed(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		want bool
+	}{
+		"package reference": {
+			body: "func f(p string) string { return path.Base(p) }",
+			want: false,
+		},
+		"parameter": {
+			body: "func f(path Path) string { return path.Base() }",
+			want: true,
+		},
+		"variadic parameter": {
+			body: "func f(paths ...Path) { for _, path := range paths { path.Base() } }",
+			want: true,
+		},
+		"receiver": {
+			body: "func (path Path) f() string { return path.Base() }",
+			want: true,
+		},
+		"named result": {
+			body: "func f() (path Path) { path.Base(); return }",
+			want: true,
+		},
+		"local variable": {
+			body: "func f() { var path Path\n path.Base() }",
+			want: true,
+		},
+		"short variable declaration": {
+			body: "func f() { path := New()\n if ok { path.Base() } }",
+			want: true,
+		},
+		"declared by its own right hand side": {
+			body: "func f(p string) { base := path.Base(p)\n _ = base }",
+			want: false,
+		},
+		"self reference": {
+			body: "func f(p string) { path := path.Base(p)\n _ = path }",
+			want: false,
+		},
+		"declared after the use": {
+			body: "func f(p string) { _ = path.Base(p)\n path := 1\n _ = path }",
+			want: false,
+		},
+		"declared in a sibling block": {
+			body: "func f(p string) { if ok { path := 1\n _ = path }\n _ = path.Base(p) }",
+			want: false,
+		},
+		"if initializer": {
+			body: "func f() { if path := New(); ok { path.Base() } }",
+			want: true,
+		},
+		"for clause": {
+			body: "func f() { for path := New(); ok; { path.Base() } }",
+			want: true,
+		},
+		"type switch alias": {
+			body: "func f(v any) { switch path := v.(type) { case Path: path.Base() } }",
+			want: true,
+		},
+		"select case": {
+			body: "func f(c chan Path) { select { case path := <-c: path.Base() } }",
+			want: true,
+		},
+		"closure parameter": {
+			body: "func f() { g := func(path Path) { path.Base() }\n _ = g }",
+			want: true,
+		},
+		"closure of a shadowing function": {
+			body: "func f(path Path) { g := func() { path.Base() }\n _ = g }",
+			want: true,
+		},
+	}
+
+	language := treesitter.NewLanguage(tsgo.Language())
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := treesitter.NewParser()
+			defer parser.Close()
+			require.NoError(t, parser.SetLanguage(language))
+
+			text := []byte("package p\n\nimport \"path\"\n\n" + tt.body + "\n")
+			tree := parser.Parse(text, nil)
+			defer tree.Close()
+
+			require.False(t, tree.RootNode().HasError())
+
+			node := selector(tree.RootNode(), "path", text)
+			require.NotNil(t, node)
+			require.Equal(t, tt.want, shadowed(node, "path", text))
+		})
+	}
+}
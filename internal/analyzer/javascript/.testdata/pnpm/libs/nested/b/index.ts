@@ -0,0 +1,3 @@
+import { x } from "a";
+
+export const y = x;
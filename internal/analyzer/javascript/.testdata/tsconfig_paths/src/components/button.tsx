@@ -0,0 +1,13 @@
+import type { Theme } from "@/lib/theme";
+
+export interface ButtonProps {
+  label: string;
+}
+
+export abstract class Base {}
+
+export class Button extends Base {}
+
+export function render(theme: Theme) {
+  return <button>{theme.name}</button>;
+}
@@ -0,0 +1,15 @@
+import { render } from "@/components/button";
+import * as lib from "@lib";
+import fs from "node:fs";
+import React from "react";
+
+const util = require("./util/format");
+
+export { format } from "./util/format";
+
+export async function main() {
+  lib.connect();
+  fs.readFileSync(util.pad("config"));
+  const page = await import("./pages/home");
+  return render(React, page);
+}
@@ -0,0 +1,3 @@
+export * from "./theme";
+
+export function connect() {}
@@ -0,0 +1,3 @@
+export interface Theme {
+  name: string;
+}
@@ -0,0 +1,5 @@
+import { Button } from "src/components/button";
+
+export default function Home() {
+  return <Button />;
+}
@@ -0,0 +1,7 @@
+const { join } = require("path");
+
+function pad(s) {
+  return join(".", s);
+}
+
+module.exports = { pad };
@@ -0,0 +1,6 @@
+import { Button } from "@acme/ui/src/button";
+import fp from "lodash/fp";
+
+const ui = require("@acme/ui");
+
+export default new ui.Button(fp, Button);
@@ -0,0 +1 @@
+import "@acme/app";
@@ -0,0 +1,5 @@
+import { tokens } from "../theme/tokens";
+
+export class Button {
+  color = tokens.primary;
+}
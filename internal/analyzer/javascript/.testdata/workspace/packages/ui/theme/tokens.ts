@@ -0,0 +1 @@
+export const tokens = { primary: "blue" };
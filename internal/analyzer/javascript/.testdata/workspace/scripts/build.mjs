@@ -0,0 +1,4 @@
+import { readFile } from "fs/promises";
+import app from "@acme/app";
+
+await readFile(app);
@@ -0,0 +1,79 @@
+package javascript
+
+import (
+	"bytes"
+	"encoding/gob"
+	"maps"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+)
+
+// jsFileEntry is a jsFile as stored in the parse cache
+type jsFileEntry struct {
+	Path          string
+	Pkg           analyzer.Package
+	Project       directory
+	Imports       []analyzer.Import
+	ImportLines   map[analyzer.Import]uint
+	Origins       map[analyzer.Import]analyzer.Origin
+	Uses          []useEntry
+	Types         uint
+	AbstractTypes uint
+}
+
+type useEntry struct {
+	Pkg    analyzer.Package
+	Symbol string
+}
+
+func (f jsFile) GobEncode() ([]byte, error) {
+	uses := make([]useEntry, 0, len(f.uses))
+	for _, u := range f.uses {
+		uses = append(uses, useEntry{Pkg: u.pkg, Symbol: u.symbol})
+	}
+
+	var buf bytes.Buffer
+
+	err := gob.NewEncoder(&buf).Encode(jsFileEntry{
+		Path:          f.path,
+		Pkg:           f.pkg,
+		Project:       f.project,
+		Imports:       f.imports,
+		ImportLines:   f.importLines,
+		Origins:       f.origins,
+		Uses:          uses,
+		Types:         f.types,
+		AbstractTypes: f.abstractTypes,
+	})
+
+	return buf.Bytes(), err
+}
+
+func (f *jsFile) GobDecode(data []byte) error {
+	var e jsFileEntry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&e); err != nil {
+		return err
+	}
+
+	// gob drops empty slices and maps
+	*f = jsFile{
+		path:          e.Path,
+		pkg:           e.Pkg,
+		project:       e.Project,
+		imports:       append(make([]analyzer.Import, 0, len(e.Imports)), e.Imports...),
+		importLines:   make(map[analyzer.Import]uint, len(e.ImportLines)),
+		origins:       make(map[analyzer.Import]analyzer.Origin, len(e.Origins)),
+		uses:          make([]use, 0, len(e.Uses)),
+		types:         e.Types,
+		abstractTypes: e.AbstractTypes,
+	}
+
+	maps.Copy(f.importLines, e.ImportLines)
+	maps.Copy(f.origins, e.Origins)
+
+	for _, u := range e.Uses {
+		f.uses = append(f.uses, use{pkg: u.Pkg, symbol: u.Symbol})
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package javascript
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSFileGob(t *testing.T) {
+	f := jsFile{
+		path:          "packages/web/src/app.ts",
+		pkg:           "packages/web/src",
+		project:       "packages/web",
+		imports:       []analyzer.Import{`"@acme/ui"`},
+		importLines:   map[analyzer.Import]uint{`"@acme/ui"`: 1},
+		origins:       map[analyzer.Import]analyzer.Origin{`"@acme/ui"`: analyzer.Workspace},
+		uses:          []use{{pkg: "@acme/ui", symbol: "@acme/ui.Button"}},
+		types:         2,
+		abstractTypes: 1,
+	}
+
+	// a field missing from jsFileEntry would be empty on every cache hit
+	v := reflect.ValueOf(f)
+	for i := range v.NumField() {
+		require.False(t, v.Field(i).IsZero(), "set %s", v.Type().Field(i).Name)
+	}
+
+	data, err := f.GobEncode()
+	require.NoError(t, err)
+
+	var got jsFile
+	require.NoError(t, got.GobDecode(data))
+	require.Equal(t, f, got)
+}
@@ -0,0 +1,751 @@
+package javascript
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/files"
+	"github.com/flamingoosesoftwareinc/uda/internal/ts"
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+	tsjavascript "github.com/tree-sitter/tree-sitter-javascript/bindings/go"
+	tstypescript "github.com/tree-sitter/tree-sitter-typescript/bindings/go"
+)
+
+type javascriptAnalyzer struct {
+	origins map[analyzer.Origin]struct{}
+}
+
+// Option configures the javascript analyzer
+type Option func(*javascriptAnalyzer)
+
+// WithOrigins restricts the analysis to imports of the given origins
+// e.g. WithOrigins(analyzer.FirstParty, analyzer.Workspace) ignores coupling to node builtins and npm packages
+func WithOrigins(origins ...analyzer.Origin) Option {
+	return func(j *javascriptAnalyzer) {
+		j.origins = make(map[analyzer.Origin]struct{}, len(origins))
+		for _, o := range origins {
+			j.origins[o] = struct{}{}
+		}
+	}
+}
+
+var (
+	_ analyzer.Analyzer         = &javascriptAnalyzer{}
+	_ analyzer.SourceAnalyzer   = &javascriptAnalyzer{}
+	_ analyzer.OriginAnalyzer   = &javascriptAnalyzer{}
+	_ analyzer.LanguageAnalyzer = &javascriptAnalyzer{}
+	_ analyzer.AllAnalyzer      = &javascriptAnalyzer{}
+)
+
+// JavaScriptAnalyzer analyzes the imports between javascript and typescript packages
+// a package is a directory e.g. "src/components" or a whole package of a npm, yarn or pnpm workspace
+func JavaScriptAnalyzer(opts ...Option) *javascriptAnalyzer {
+	j := &javascriptAnalyzer{}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	return j
+}
+
+// AnalyzeAll derives every analysis from a single extraction of the javascript and typescript files of dir
+func (j *javascriptAnalyzer) AnalyzeAll(ctx context.Context, dir fs.FS) (analyzer.Result, error) {
+	jsFiles, err := j.analyze(ctx, dir)
+	if err != nil {
+		return analyzer.Result{}, err
+	}
+
+	return analyzer.Result{
+		Imports:   packageImports(jsFiles),
+		Metrics:   buildMetrics(jsFiles),
+		Sources:   importSources(jsFiles),
+		Origins:   importOrigins(jsFiles),
+		Languages: packageLanguages(jsFiles),
+	}, nil
+}
+
+func (j *javascriptAnalyzer) AnalyzeV2(
+	ctx context.Context,
+	dir fs.FS,
+) ([]analyzer.Metrics, error) {
+	jsFiles, err := j.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildMetrics(jsFiles), nil
+}
+
+func buildMetrics(jsFiles []jsFile) []analyzer.Metrics {
+	outward := make(map[analyzer.Package]analyzer.PackageCouplingStats)
+	types := make(map[analyzer.Package]jsFile)
+
+	for _, f := range jsFiles {
+		t := types[f.pkg]
+		t.types += f.types
+		t.abstractTypes += f.abstractTypes
+		types[f.pkg] = t
+
+		stats, ok := outward[f.pkg]
+		if !ok {
+			stats = make(analyzer.PackageCouplingStats)
+			outward[f.pkg] = stats
+		}
+
+		for _, u := range f.uses {
+			stats.Add(u.pkg, u.symbol)
+		}
+	}
+
+	metrics := analyzer.BuildMetrics(outward)
+	for i := range metrics {
+		metrics[i].TotalTypes = types[metrics[i].Package].types
+		metrics[i].AbstractTypes = types[metrics[i].Package].abstractTypes
+	}
+
+	return metrics
+}
+
+func (j *javascriptAnalyzer) Analyze(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.PackageImports, error) {
+	jsFiles, err := j.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return packageImports(jsFiles), nil
+}
+
+func packageImports(jsFiles []jsFile) analyzer.PackageImports {
+	pi := make(analyzer.PackageImports)
+	seen := make(map[analyzer.Package]map[analyzer.Import]struct{})
+
+	// a package is made up of every file in its directory or workspace package so imports are merged across files
+	for _, f := range jsFiles {
+		pkgSeen, ok := seen[f.pkg]
+		if !ok {
+			pkgSeen = make(map[analyzer.Import]struct{}, len(f.imports))
+			seen[f.pkg] = pkgSeen
+			pi[f.pkg] = make([]analyzer.Import, 0, len(f.imports))
+		}
+
+		for _, i := range f.imports {
+			if _, ok := pkgSeen[i]; ok {
+				continue
+			}
+
+			pkgSeen[i] = struct{}{}
+			pi[f.pkg] = append(pi[f.pkg], i)
+		}
+	}
+
+	return pi
+}
+
+func (j *javascriptAnalyzer) AnalyzeSources(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.ImportSources, error) {
+	jsFiles, err := j.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return importSources(jsFiles), nil
+}
+
+func importSources(jsFiles []jsFile) analyzer.ImportSources {
+	sources := make(analyzer.ImportSources)
+
+	for _, f := range jsFiles {
+		pkgSources, ok := sources[f.pkg]
+		if !ok {
+			pkgSources = make(map[analyzer.Import][]analyzer.Location, len(f.imports))
+			sources[f.pkg] = pkgSources
+		}
+
+		for _, i := range f.imports {
+			loc := analyzer.Location{File: f.path, Line: f.importLines[i]}
+			if slices.Contains(pkgSources[i], loc) {
+				continue
+			}
+
+			pkgSources[i] = append(pkgSources[i], loc)
+		}
+	}
+
+	return sources
+}
+
+func (j *javascriptAnalyzer) AnalyzeOrigins(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.ImportOrigins, error) {
+	jsFiles, err := j.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return importOrigins(jsFiles), nil
+}
+
+func importOrigins(jsFiles []jsFile) analyzer.ImportOrigins {
+	origins := make(analyzer.ImportOrigins)
+
+	for _, f := range jsFiles {
+		pkgOrigins, ok := origins[f.pkg]
+		if !ok {
+			pkgOrigins = make(map[analyzer.Import]analyzer.Origin, len(f.imports))
+			origins[f.pkg] = pkgOrigins
+		}
+
+		for _, i := range f.imports {
+			pkgOrigins[i] = f.origins[i]
+		}
+	}
+
+	return origins
+}
+
+// AnalyzeLanguages returns "typescript" for packages with typescript sources and "javascript" for the others
+func (j *javascriptAnalyzer) AnalyzeLanguages(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.PackageLanguages, error) {
+	jsFiles, err := j.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return packageLanguages(jsFiles), nil
+}
+
+func packageLanguages(jsFiles []jsFile) analyzer.PackageLanguages {
+	languages := make(analyzer.PackageLanguages)
+
+	for _, f := range jsFiles {
+		if _, ok := languages[f.pkg]; !ok {
+			languages[f.pkg] = string(ts.JS)
+		}
+
+		// a single typescript file makes the package a typescript package e.g. while migrating from javascript
+		if l := language(f.path); l == ts.TS || l == ts.TSX {
+			languages[f.pkg] = string(ts.TS)
+		}
+	}
+
+	return languages
+}
+
+func (j *javascriptAnalyzer) analyze(ctx context.Context, dir fs.FS) ([]jsFile, error) {
+	// package.json names the packages and declares npm and yarn workspaces,
+	// pnpm-workspace.yaml declares pnpm workspaces and tsconfig.json or jsconfig.json
+	// declare the path aliases of non-relative specifiers
+	manifestFiles, err := listManifestFiles(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.DebugContext(ctx, "found manifest files", "filepaths", manifestFiles)
+
+	l, err := extractLayout(ctx, dir, manifestFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	tsconfigs, err := extractTsconfigs(dir, manifestFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceFilepaths, err := listSourceFiles(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	jsFiles, err := analyzeSourceFiles(
+		ctx,
+		dir,
+		sourceFilepaths,
+		newResolver(l, tsconfigs, sourceFilepaths),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if j.origins != nil {
+		for i := range jsFiles {
+			f := &jsFiles[i]
+
+			allowed := func(imp analyzer.Import) bool {
+				_, ok := j.origins[f.origins[imp]]
+				return ok
+			}
+
+			f.imports = slices.DeleteFunc(f.imports, func(imp analyzer.Import) bool {
+				return !allowed(imp)
+			})
+			f.uses = slices.DeleteFunc(f.uses, func(u use) bool {
+				return !allowed(quote(u.pkg))
+			})
+		}
+	}
+
+	return jsFiles, nil
+}
+
+func listSourceFiles(ctx context.Context, dir fs.FS) ([]string, error) {
+	return files.ListFiles(
+		ctx,
+		dir,
+		files.SkipHiddenDirs(),
+		files.SkipHiddenFiles(),
+		skipDependencyDirs(),
+		sourceFileFilter(),
+	)
+}
+
+// sourceFileFilter keeps javascript and typescript sources
+// declaration files are skipped as they describe code rather than being part of it
+func sourceFileFilter() files.FileFilter {
+	return func(p string, d fs.DirEntry) bool {
+		if d.IsDir() {
+			return false
+		}
+
+		if strings.HasSuffix(p, ".d.ts") || strings.HasSuffix(p, ".d.mts") ||
+			strings.HasSuffix(p, ".d.cts") {
+			return true
+		}
+
+		return !slices.Contains(extensions, path.Ext(p))
+	}
+}
+
+// skipDependencyDirs skips installed dependencies
+func skipDependencyDirs() files.FileFilter {
+	return func(p string, d fs.DirEntry) bool {
+		return d.IsDir() && path.Base(p) == "node_modules"
+	}
+}
+
+// language returns the grammar of a source file, the javascript grammar includes jsx
+func language(sourceFilepath string) ts.LanguageID {
+	switch path.Ext(sourceFilepath) {
+	case ".ts", ".mts", ".cts":
+		return ts.TS
+	case ".tsx":
+		return ts.TSX
+	}
+
+	return ts.JS
+}
+
+func quote(pkg analyzer.Package) analyzer.Import {
+	return analyzer.Import(`"` + pkg + `"`)
+}
+
+// jsFile is what was extracted from a single javascript or typescript file
+type jsFile struct {
+	path string
+	pkg  analyzer.Package
+	// directory of the package.json the file belongs to, "." when there is none
+	project     directory
+	imports     []analyzer.Import
+	importLines map[analyzer.Import]uint
+	origins     map[analyzer.Import]analyzer.Origin
+	// symbols of other packages used in the file
+	uses []use
+	// number of top level classes and interfaces and how many of them are abstract
+	types, abstractTypes uint
+}
+
+// use is a reference to an exported symbol of a package e.g. "@acme/ui.Button" of "@acme/ui"
+type use struct {
+	pkg    analyzer.Package
+	symbol string
+}
+
+// binding is what a name bound by an import refers to, either a whole module or one of its exports
+// e.g. `import * as ui from "@acme/ui"` binds ui to the "@acme/ui" module
+// e.g. `import { Button } from "@acme/ui"` binds Button to the "@acme/ui.Button" symbol
+type binding struct {
+	pkg    analyzer.Package
+	symbol string
+}
+
+// grammar is a grammar of the analyzed source files and the query of its files
+type grammar struct {
+	language *treesitter.Language
+	query    string
+}
+
+// workers are the workers of a single goroutine, one per grammar created on first use
+type workers map[ts.LanguageID]*ts.Worker
+
+func (w workers) Close() {
+	for _, worker := range w {
+		worker.Close()
+	}
+}
+
+func analyzeSourceFiles(
+	ctx context.Context,
+	dir fs.FS,
+	sourceFilepaths []string,
+	r resolver,
+) ([]jsFile, error) {
+	jsQuery, err := ts.LoadQuery(ts.JS, ts.AnalyzeQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	// tsx files are queried with the typescript query
+	tsQuery, err := ts.LoadQuery(ts.TS, ts.AnalyzeQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	// specifiers resolve against every analyzed file so a file added or removed invalidates every file
+	salt := ts.Digest(r)
+
+	grammars := map[ts.LanguageID]grammar{
+		ts.JS:  {language: treesitter.NewLanguage(tsjavascript.Language()), query: jsQuery},
+		ts.TS:  {language: treesitter.NewLanguage(tstypescript.LanguageTypescript()), query: tsQuery},
+		ts.TSX: {language: treesitter.NewLanguage(tstypescript.LanguageTSX()), query: tsQuery},
+	}
+
+	return ts.Map(
+		ctx,
+		sourceFilepaths,
+		func() (workers, error) { return make(workers, len(grammars)), nil },
+		func(ctx context.Context, w workers, sourceFilepath string) (jsFile, error) {
+			lang := language(sourceFilepath)
+			g := grammars[lang]
+
+			worker, ok := w[lang]
+			if !ok {
+				var err error
+
+				worker, err = ts.NewWorker(g.language)
+				if err != nil {
+					return jsFile{}, err
+				}
+
+				w[lang] = worker
+			}
+
+			return analyzeSourceFile(ctx, worker, dir, sourceFilepath, g.query, r, salt)
+		},
+	)
+}
+
+// analyzeSourceFile extracts the imports, uses and declarations of a single source file
+func analyzeSourceFile(
+	ctx context.Context,
+	w *ts.Worker,
+	dir fs.FS,
+	sourceFilepath string,
+	query string,
+	r resolver,
+	salt string,
+) (jsFile, error) {
+	return ts.Extract(
+		ctx,
+		w,
+		dir,
+		sourceFilepath,
+		query,
+		salt,
+		func(q *treesitter.Query, matches treesitter.QueryMatches, text []byte) (jsFile, error) {
+			captureNames := q.CaptureNames()
+
+			pkg, project := r.layout.node(directory(path.Dir(sourceFilepath)))
+			f := jsFile{
+				path:        sourceFilepath,
+				pkg:         pkg,
+				project:     project,
+				imports:     make([]analyzer.Import, 0, 32),
+				importLines: make(map[analyzer.Import]uint),
+				origins:     make(map[analyzer.Import]analyzer.Origin),
+				uses:        make([]use, 0, 32),
+			}
+
+			e := extractor{
+				file:     &f,
+				resolver: r,
+				text:     text,
+				bindings: make(map[string]binding),
+			}
+
+			for match := matches.Next(); match != nil; match = matches.Next() {
+				for _, capture := range match.Captures {
+					e.capture(captureNames[capture.Index], &capture.Node)
+				}
+			}
+
+			// member uses are resolved once every import is known as a require may follow its use
+			for _, m := range e.members {
+				b, ok := e.bindings[m[0]]
+				if !ok {
+					// not an imported name e.g. a local variable
+					continue
+				}
+
+				symbol := b.symbol
+				if symbol == "" {
+					symbol = m[1]
+				}
+
+				f.uses = append(f.uses, use{pkg: b.pkg, symbol: string(b.pkg) + "." + symbol})
+			}
+
+			f.uses = slices.DeleteFunc(f.uses, func(u use) bool {
+				return u.pkg == f.pkg
+			})
+
+			slog.DebugContext(
+				ctx,
+				"processed file",
+				"path",
+				sourceFilepath,
+				"pkgDetected",
+				f.pkg,
+				"imports",
+				f.imports,
+			)
+
+			return f, nil
+		},
+	)
+}
+
+// extractor collects the imports, bindings and uses of a single file
+type extractor struct {
+	file     *jsFile
+	resolver resolver
+	text     []byte
+	bindings map[string]binding
+	// object and property of member expressions on identifiers e.g. ["ui", "Button"] for ui.Button
+	members [][2]string
+}
+
+func (e *extractor) capture(captureName string, node *treesitter.Node) {
+	switch captureName {
+	case "import":
+		slog.Debug("import detected", "import", node.Utf8Text(e.text))
+		e.importStatement(node)
+	case "export_from":
+		slog.Debug("export_from detected", "export", node.Utf8Text(e.text))
+		t, ok := e.addImport(node, node.ChildByFieldName("source"))
+		if !ok {
+			return
+		}
+
+		for i := range node.NamedChildCount() {
+			if clause := node.NamedChild(i); clause.Kind() == "export_clause" {
+				for j := range clause.NamedChildCount() {
+					name := clause.NamedChild(j).ChildByFieldName("name")
+					e.addUse(t.pkg, stringValue(name, e.text))
+				}
+			}
+		}
+	case "dynamic_import":
+		slog.Debug("dynamic_import detected", "import", node.Utf8Text(e.text))
+		e.addImport(node, node.ChildByFieldName("arguments").NamedChild(0))
+	case "require_call":
+		if node.ChildByFieldName("function").Utf8Text(e.text) != "require" {
+			return
+		}
+
+		slog.Debug("require detected", "require", node.Utf8Text(e.text))
+		t, ok := e.addImport(node, node.ChildByFieldName("arguments").NamedChild(0))
+		if !ok {
+			return
+		}
+
+		// e.g. const db = require("./db") or const { query } = require("./db")
+		if declarator := node.Parent(); declarator != nil && declarator.Kind() == "variable_declarator" {
+			e.bindPattern(t, declarator.ChildByFieldName("name"))
+		}
+	case "member_use":
+		object, property := node.ChildByFieldName("object"), node.ChildByFieldName("property")
+		e.members = append(e.members, [2]string{object.Utf8Text(e.text), property.Utf8Text(e.text)})
+	case "type_use":
+		// e.g. ui.ButtonProps or ui.theme.Colors as a type
+		parts := strings.Split(node.Utf8Text(e.text), ".")
+		if len(parts) > 1 {
+			e.members = append(e.members, [2]string{strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])})
+		}
+	case "type_declaration":
+		slog.Debug("type_declaration detected", "declaration", node.Utf8Text(e.text))
+		e.file.types++
+	case "abstract_type_declaration":
+		slog.Debug("abstract_type_declaration detected", "declaration", node.Utf8Text(e.text))
+		e.file.types++
+		e.file.abstractTypes++
+	default:
+		slog.Debug(
+			"unknown capture name",
+			"captureName",
+			captureName,
+			"value",
+			node.Utf8Text(e.text),
+		)
+	}
+}
+
+// importStatement records an import and the names it binds
+// e.g. import Default, { named as alias } from "x", import * as ns from "x" or import x = require("x")
+func (e *extractor) importStatement(node *treesitter.Node) {
+	source := node.ChildByFieldName("source")
+
+	var requireClause *treesitter.Node
+	for i := range node.NamedChildCount() {
+		if child := node.NamedChild(i); child.Kind() == "import_require_clause" {
+			requireClause = child
+			source = child.ChildByFieldName("source")
+		}
+	}
+
+	t, ok := e.addImport(node, source)
+	if !ok {
+		return
+	}
+
+	if requireClause != nil {
+		for i := range requireClause.NamedChildCount() {
+			if child := requireClause.NamedChild(i); child.Kind() == "identifier" {
+				e.bindings[child.Utf8Text(e.text)] = binding{pkg: t.pkg}
+			}
+		}
+
+		return
+	}
+
+	for i := range node.NamedChildCount() {
+		clause := node.NamedChild(i)
+		if clause.Kind() != "import_clause" {
+			continue
+		}
+
+		for j := range clause.NamedChildCount() {
+			child := clause.NamedChild(j)
+
+			switch child.Kind() {
+			case "identifier":
+				// members of a default import are kept apart e.g. fs.readFileSync of import fs from "fs"
+				e.bindings[child.Utf8Text(e.text)] = binding{pkg: t.pkg}
+				e.addUse(t.pkg, "default")
+			case "namespace_import":
+				if name := child.NamedChild(0); name != nil {
+					e.bindings[name.Utf8Text(e.text)] = binding{pkg: t.pkg}
+				}
+			case "named_imports":
+				for k := range child.NamedChildCount() {
+					specifier := child.NamedChild(k)
+					if specifier.Kind() != "import_specifier" {
+						continue
+					}
+
+					name := stringValue(specifier.ChildByFieldName("name"), e.text)
+					bound := name
+					if alias := specifier.ChildByFieldName("alias"); alias != nil {
+						bound = alias.Utf8Text(e.text)
+					}
+
+					e.bindings[bound] = binding{pkg: t.pkg, symbol: name}
+					e.addUse(t.pkg, name)
+				}
+			}
+		}
+	}
+}
+
+// bindPattern binds the names of a require declaration
+// e.g. db in const db = require("./db") or query and q in const { query, close: q } = require("./db")
+func (e *extractor) bindPattern(t target, pattern *treesitter.Node) {
+	if pattern == nil {
+		return
+	}
+
+	switch pattern.Kind() {
+	case "identifier":
+		e.bindings[pattern.Utf8Text(e.text)] = binding{pkg: t.pkg}
+	case "object_pattern":
+		for i := range pattern.NamedChildCount() {
+			property := pattern.NamedChild(i)
+
+			switch property.Kind() {
+			case "shorthand_property_identifier_pattern":
+				name := property.Utf8Text(e.text)
+				e.bindings[name] = binding{pkg: t.pkg, symbol: name}
+				e.addUse(t.pkg, name)
+			case "pair_pattern":
+				name := stringValue(property.ChildByFieldName("key"), e.text)
+				if value := property.ChildByFieldName("value"); value != nil &&
+					value.Kind() == "identifier" {
+					e.bindings[value.Utf8Text(e.text)] = binding{pkg: t.pkg, symbol: name}
+				}
+				e.addUse(t.pkg, name)
+			}
+		}
+	}
+}
+
+// addImport resolves the specifier of a statement and records the package node it imports
+func (e *extractor) addImport(statement, source *treesitter.Node) (target, bool) {
+	if source == nil || source.Kind() != "string" {
+		return target{}, false
+	}
+
+	specifier := stringValue(source, e.text)
+	if specifier == "" {
+		return target{}, false
+	}
+
+	t := e.resolver.resolve(e.file.path, specifier)
+	if t.pkg == e.file.pkg {
+		return t, true
+	}
+
+	imp := quote(t.pkg)
+	if _, ok := e.file.importLines[imp]; ok {
+		return t, true
+	}
+
+	e.file.imports = append(e.file.imports, imp)
+	e.file.importLines[imp] = statement.StartPosition().Row + 1
+	e.file.origins[imp] = t.origin
+
+	return t, true
+}
+
+func (e *extractor) addUse(pkg analyzer.Package, symbol string) {
+	e.file.uses = append(e.file.uses, use{pkg: pkg, symbol: string(pkg) + "." + symbol})
+}
+
+// stringValue returns the content of a string literal without its quotes, other nodes are returned as is
+func stringValue(node *treesitter.Node, text []byte) string {
+	if node == nil {
+		return ""
+	}
+
+	if node.Kind() != "string" {
+		return node.Utf8Text(text)
+	}
+
+	var b strings.Builder
+	for i := range node.NamedChildCount() {
+		b.WriteString(node.NamedChild(i).Utf8Text(text))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,187 @@
+package javascript
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripJSONC(t *testing.T) {
+	content := `{
+  // line comment
+  "a": "http://example.com", /* block
+  comment */
+  "b": ["x", "y",],
+  "c": "not // a comment",
+}`
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(stripJSONC([]byte(content)), &got))
+	require.Equal(t, map[string]any{
+		"a": "http://example.com",
+		"b": []any{"x", "y"},
+		"c": "not // a comment",
+	}, got)
+}
+
+func TestAlias(t *testing.T) {
+	cfg := tsconfig{
+		paths: map[string][]string{
+			"@/*":            {"src/*"},
+			"@/components/*": {"ui/components/*", "src/components/*"},
+			"config":         {"config/index.ts"},
+		},
+		pathsBase: "app",
+	}
+
+	tests := map[string]struct {
+		specifier string
+		want      []string
+	}{
+		"should substitute the wildcard": {
+			specifier: "@/lib/db",
+			want:      []string{"app/src/lib/db"},
+		},
+		"should prefer the longest prefix": {
+			specifier: "@/components/button",
+			want:      []string{"app/ui/components/button", "app/src/components/button"},
+		},
+		"should match exact patterns": {
+			specifier: "config",
+			want:      []string{"app/config/index.ts"},
+		},
+		"should not match other specifiers": {
+			specifier: "react",
+			want:      nil,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, cfg.alias(tt.specifier))
+		})
+	}
+}
+
+func TestInWorkspace(t *testing.T) {
+	globs := []string{"packages/*", "apps/**", "!packages/legacy"}
+
+	tests := map[string]struct {
+		pkgDir directory
+		want   bool
+	}{
+		"should match a glob":                {pkgDir: "packages/ui", want: true},
+		"should match a recursive glob":      {pkgDir: "apps/web/admin", want: true},
+		"should not match nested packages":   {pkgDir: "packages/ui/fixtures", want: false},
+		"should exclude negated globs":       {pkgDir: "packages/legacy", want: false},
+		"should not match other directories": {pkgDir: "tools/lint", want: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, inWorkspace(".", globs, tt.pkgDir))
+		})
+	}
+}
+
+func TestExtractLayoutMalformedManifests(t *testing.T) {
+	dir := fstest.MapFS{
+		"package.json":                 {Data: []byte(`{"workspaces": ["packages/*"]}`)},
+		"packages/ui/package.json":     {Data: []byte(`{"name": "@acme/ui"}`)},
+		"packages/broken/package.json": {Data: []byte(`{"name": "@acme/broken",}`)},
+		"pnpm/pnpm-workspace.yaml":     {Data: []byte("packages: [apps/*\n")},
+		"pnpm/apps/web/package.json":   {Data: []byte(`{"name": "web"}`)},
+	}
+
+	got, err := extractLayout(context.Background(), dir, []string{
+		"package.json",
+		"packages/ui/package.json",
+		"packages/broken/package.json",
+		"pnpm/pnpm-workspace.yaml",
+		"pnpm/apps/web/package.json",
+	})
+	require.NoError(t, err)
+
+	// the broken package and the broken pnpm workspace are skipped, the rest is still a workspace
+	require.Equal(t, map[directory]string{"packages/ui": "@acme/ui"}, got.members)
+	require.Equal(t, map[string]directory{"@acme/ui": "packages/ui"}, got.names)
+}
+
+func TestNode(t *testing.T) {
+	l := layout{
+		packages: map[directory]string{
+			".":               "shop",
+			"packages/ui":     "@acme/ui",
+			"packages/ui/esm": "",
+			"tools":           "",
+		},
+		members: map[directory]string{
+			"packages/ui": "@acme/ui",
+		},
+	}
+
+	tests := map[string]struct {
+		dir         directory
+		wantPkg     analyzer.Package
+		wantProject directory
+	}{
+		"should name a directory after its package": {
+			dir:         "src/components",
+			wantPkg:     "shop/src/components",
+			wantProject: ".",
+		},
+		"should name the package directory after the package": {
+			dir:         ".",
+			wantPkg:     "shop",
+			wantProject: ".",
+		},
+		"should group a workspace package": {
+			dir:         "packages/ui/esm/button",
+			wantPkg:     "@acme/ui",
+			wantProject: "packages/ui",
+		},
+		"should skip unnamed packages": {
+			dir:         "tools/lint",
+			wantPkg:     "shop/tools/lint",
+			wantProject: "tools",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pkg, project := l.node(tt.dir)
+			require.Equal(t, tt.wantPkg, pkg)
+			require.Equal(t, tt.wantProject, project)
+		})
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	tests := map[string]struct {
+		specifier string
+		want      string
+	}{
+		"should return a package":             {specifier: "react", want: "react"},
+		"should strip the subpath":            {specifier: "lodash/fp", want: "lodash"},
+		"should keep the scope":               {specifier: "@acme/ui", want: "@acme/ui"},
+		"should strip the subpath of a scope": {specifier: "@acme/ui/button", want: "@acme/ui"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, packageName(tt.specifier))
+		})
+	}
+}
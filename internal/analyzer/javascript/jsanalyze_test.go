@@ -0,0 +1,222 @@
+package javascript_test
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer/javascript"
+	"github.com/stretchr/testify/require"
+)
+
+type packageImports struct {
+	Pkg     analyzer.Package
+	Imports []analyzer.Import
+}
+
+func toSortedSlice(pi analyzer.PackageImports) []packageImports {
+	result := make([]packageImports, 0, len(pi))
+	for pkg, imports := range pi {
+		sorted := make([]analyzer.Import, len(imports))
+		copy(sorted, imports)
+		slices.Sort(sorted)
+		result = append(result, packageImports{Pkg: pkg, Imports: sorted})
+	}
+	return result
+}
+
+func TestJavaScriptAnalyze(t *testing.T) {
+	tests := map[string]struct {
+		dir  string
+		want analyzer.PackageImports
+	}{
+		"tsconfig_paths": {
+			dir: ".testdata/tsconfig_paths",
+			want: analyzer.PackageImports{
+				"shop/src": []analyzer.Import{
+					`"shop/src/components"`,
+					`"shop/src/lib"`,
+					`"fs"`,
+					`"react"`,
+					`"shop/src/util"`,
+					`"shop/src/pages"`,
+				},
+				"shop/src/components": []analyzer.Import{
+					`"shop/src/lib"`,
+				},
+				"shop/src/lib": []analyzer.Import{},
+				"shop/src/pages": []analyzer.Import{
+					`"shop/src/components"`,
+				},
+				"shop/src/util": []analyzer.Import{
+					`"path"`,
+				},
+			},
+		},
+		"workspace": {
+			dir: ".testdata/workspace",
+			want: analyzer.PackageImports{
+				"@acme/app": []analyzer.Import{
+					`"@acme/ui"`,
+					`"lodash"`,
+				},
+				"@acme/legacy": []analyzer.Import{
+					`"@acme/app"`,
+				},
+				"@acme/ui": []analyzer.Import{},
+				"acme/scripts": []analyzer.Import{
+					`"fs"`,
+					`"@acme/app"`,
+				},
+			},
+		},
+		"pnpm": {
+			dir: ".testdata/pnpm",
+			want: analyzer.PackageImports{
+				"a": []analyzer.Import{},
+				"b": []analyzer.Import{
+					`"a"`,
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := os.DirFS(tt.dir)
+			got, err := javascript.JavaScriptAnalyzer().Analyze(context.Background(), dir)
+			require.NoError(t, err)
+			require.ElementsMatch(t, toSortedSlice(tt.want), toSortedSlice(got))
+		})
+	}
+}
+
+func TestJavaScriptAnalyzeSources(t *testing.T) {
+	dir := os.DirFS(".testdata/workspace")
+
+	got, err := javascript.JavaScriptAnalyzer().AnalyzeSources(context.Background(), dir)
+	require.NoError(t, err)
+
+	require.Equal(t, analyzer.ImportSources{
+		"@acme/app": {
+			`"@acme/ui"`: {{File: "packages/app/index.js", Line: 1}},
+			`"lodash"`:   {{File: "packages/app/index.js", Line: 2}},
+		},
+		"@acme/legacy": {
+			`"@acme/app"`: {{File: "packages/legacy/index.js", Line: 1}},
+		},
+		"@acme/ui": {},
+		"acme/scripts": {
+			`"fs"`:        {{File: "scripts/build.mjs", Line: 1}},
+			`"@acme/app"`: {{File: "scripts/build.mjs", Line: 2}},
+		},
+	}, got)
+}
+
+func TestJavaScriptAnalyzeOrigins(t *testing.T) {
+	tests := map[string]struct {
+		dir  string
+		want analyzer.ImportOrigins
+	}{
+		"tsconfig_paths": {
+			dir: ".testdata/tsconfig_paths",
+			want: analyzer.ImportOrigins{
+				"shop/src": {
+					`"shop/src/components"`: analyzer.FirstParty,
+					`"shop/src/lib"`:        analyzer.FirstParty,
+					`"fs"`:                  analyzer.Std,
+					`"react"`:               analyzer.ThirdParty,
+					`"shop/src/util"`:       analyzer.FirstParty,
+					`"shop/src/pages"`:      analyzer.FirstParty,
+				},
+				"shop/src/components": {`"shop/src/lib"`: analyzer.FirstParty},
+				"shop/src/lib":        {},
+				"shop/src/pages":      {`"shop/src/components"`: analyzer.FirstParty},
+				"shop/src/util":       {`"path"`: analyzer.Std},
+			},
+		},
+		"workspace": {
+			dir: ".testdata/workspace",
+			want: analyzer.ImportOrigins{
+				"@acme/app": {
+					`"@acme/ui"`: analyzer.Workspace,
+					`"lodash"`:   analyzer.ThirdParty,
+				},
+				"@acme/legacy": {`"@acme/app"`: analyzer.Workspace},
+				"@acme/ui":     {},
+				"acme/scripts": {
+					`"fs"`:        analyzer.Std,
+					`"@acme/app"`: analyzer.Workspace,
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := os.DirFS(tt.dir)
+			got, err := javascript.JavaScriptAnalyzer().AnalyzeOrigins(context.Background(), dir)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestJavaScriptAnalyzeWithOrigins(t *testing.T) {
+	dir := os.DirFS(".testdata/workspace")
+
+	a := javascript.JavaScriptAnalyzer(
+		javascript.WithOrigins(analyzer.FirstParty, analyzer.Workspace),
+	)
+
+	got, err := a.Analyze(context.Background(), dir)
+	require.NoError(t, err)
+	require.ElementsMatch(t, toSortedSlice(analyzer.PackageImports{
+		"@acme/app":    {`"@acme/ui"`},
+		"@acme/legacy": {`"@acme/app"`},
+		"@acme/ui":     {},
+		"acme/scripts": {`"@acme/app"`},
+	}), toSortedSlice(got))
+}
+
+func TestJavaScriptAnalyzeV2(t *testing.T) {
+	dir := os.DirFS(".testdata/tsconfig_paths")
+
+	metrics, err := javascript.JavaScriptAnalyzer().AnalyzeV2(context.Background(), dir)
+	require.NoError(t, err)
+
+	type result struct {
+		Pkg                       analyzer.Package
+		Ca, Ce                    float64
+		AbstractTypes, TotalTypes uint
+	}
+
+	got := make([]result, 0, len(metrics))
+	for _, m := range metrics {
+		got = append(got, result{
+			Pkg:           m.Package,
+			Ca:            m.InwardCoupling(),
+			Ce:            m.OutwardCoupling(),
+			AbstractTypes: m.AbstractTypes,
+			TotalTypes:    m.TotalTypes,
+		})
+	}
+
+	require.Equal(t, []result{
+		// render, connect, fs default and readFileSync, react default, format and pad
+		{Pkg: "shop/src", Ca: 0, Ce: 7},
+		// Theme
+		{Pkg: "shop/src/components", Ca: 2, Ce: 1, AbstractTypes: 2, TotalTypes: 3},
+		{Pkg: "shop/src/lib", Ca: 2, Ce: 0, AbstractTypes: 1, TotalTypes: 1},
+		// Button
+		{Pkg: "shop/src/pages", Ca: 0, Ce: 1},
+		// join
+		{Pkg: "shop/src/util", Ca: 2, Ce: 1},
+	}, got)
+}
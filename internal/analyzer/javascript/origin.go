@@ -0,0 +1,20 @@
+package javascript
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:generate sh -c "node -e \"console.log(require('module').builtinModules.filter(m => !m.startsWith('_')).sort().join('\\n'))\" > builtins.txt"
+//go:embed builtins.txt
+var builtinsList string
+
+// builtins is the set of node builtin modules e.g. "fs" which can also be imported as "node:fs"
+var builtins = func() map[string]struct{} {
+	modules := make(map[string]struct{})
+	for _, m := range strings.Fields(builtinsList) {
+		modules[m] = struct{}{}
+	}
+
+	return modules
+}()
@@ -0,0 +1,181 @@
+package javascript
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+)
+
+// extensions are tried in order when a specifier omits the extension of a file
+var extensions = []string{".ts", ".tsx", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs"}
+
+// target is the package node a specifier resolved to
+type target struct {
+	pkg    analyzer.Package
+	origin analyzer.Origin
+}
+
+type resolver struct {
+	layout    layout
+	tsconfigs map[directory]tsconfig
+	// every analyzed source file and every directory containing one
+	files map[string]struct{}
+	dirs  map[directory]struct{}
+}
+
+func newResolver(l layout, tsconfigs map[directory]tsconfig, sourceFilepaths []string) resolver {
+	r := resolver{
+		layout:    l,
+		tsconfigs: tsconfigs,
+		files:     make(map[string]struct{}, len(sourceFilepaths)),
+		dirs:      make(map[directory]struct{}),
+	}
+
+	for _, f := range sourceFilepaths {
+		r.files[f] = struct{}{}
+
+		for d := range ancestors(directory(path.Dir(f))) {
+			r.dirs[d] = struct{}{}
+		}
+	}
+
+	return r
+}
+
+// resolve returns the package node a specifier of fromFile refers to
+// relative specifiers and tsconfig aliases resolve to the directory of the file they point to,
+// workspace packages to their package node and anything else to the npm package it names
+// e.g. "./button" is the directory of "./button.tsx", "@/lib/db" with "@/*": ["src/*"] is "src/lib"
+// and "lodash/fp" is "lodash"
+func (r resolver) resolve(fromFile, specifier string) target {
+	fromDir := directory(path.Dir(fromFile))
+	_, fromProject := r.layout.node(fromDir)
+
+	if isRelative(specifier) {
+		p := path.Join(string(fromDir), specifier)
+		if !fs.ValidPath(p) {
+			// outside of the analyzed directory
+			return target{pkg: analyzer.Package(p), origin: analyzer.ThirdParty}
+		}
+
+		return r.internal(p, fromProject)
+	}
+
+	if cfg, ok := r.tsconfig(fromDir); ok {
+		candidates := cfg.alias(specifier)
+		for _, c := range candidates {
+			if r.exists(c) {
+				return r.internal(c, fromProject)
+			}
+		}
+
+		if len(candidates) > 0 {
+			return r.internal(candidates[0], fromProject)
+		}
+
+		if cfg.baseURL != "" {
+			if c := path.Join(string(cfg.baseURL), specifier); r.exists(c) {
+				return r.internal(c, fromProject)
+			}
+		}
+	}
+
+	if builtin, ok := strings.CutPrefix(specifier, "node:"); ok {
+		return target{pkg: analyzer.Package(packageName(builtin)), origin: analyzer.Std}
+	}
+
+	if _, ok := builtins[packageName(specifier)]; ok {
+		return target{pkg: analyzer.Package(packageName(specifier)), origin: analyzer.Std}
+	}
+
+	if pkg, d, ok := r.layout.workspacePackage(specifier); ok {
+		origin := analyzer.Workspace
+		if d == fromProject {
+			origin = analyzer.FirstParty
+		}
+
+		return target{pkg: pkg, origin: origin}
+	}
+
+	return target{pkg: analyzer.Package(packageName(specifier)), origin: analyzer.ThirdParty}
+}
+
+// internal returns the package node of a path within the analyzed directory
+// paths that do not resolve to a source file are assumed to be a file when their directory exists
+func (r resolver) internal(p string, fromProject directory) target {
+	d := directory(path.Dir(p))
+
+	if f, ok := r.resolveFile(p); ok {
+		d = directory(path.Dir(f))
+	} else if _, ok := r.dirs[directory(p)]; ok {
+		d = directory(p)
+	}
+
+	pkg, project := r.layout.node(d)
+
+	origin := analyzer.Workspace
+	if project == fromProject {
+		origin = analyzer.FirstParty
+	}
+
+	return target{pkg: pkg, origin: origin}
+}
+
+// resolveFile returns the source file a path refers to the way bundlers and typescript do
+// e.g. "src/button" is "src/button.tsx" or "src/button/index.ts"
+// and "src/button.js" is "src/button.ts" as typescript sources import their compiled name
+func (r resolver) resolveFile(p string) (string, bool) {
+	candidates := []string{p}
+
+	for _, ext := range extensions {
+		candidates = append(candidates, p+ext)
+	}
+
+	switch ext := path.Ext(p); ext {
+	case ".js", ".jsx", ".mjs", ".cjs":
+		trimmed := strings.TrimSuffix(p, ext)
+		for _, ext := range extensions {
+			candidates = append(candidates, trimmed+ext)
+		}
+	}
+
+	for _, ext := range extensions {
+		candidates = append(candidates, path.Join(p, "index"+ext))
+	}
+
+	for _, c := range candidates {
+		if _, ok := r.files[c]; ok {
+			return c, true
+		}
+	}
+
+	return "", false
+}
+
+func (r resolver) exists(p string) bool {
+	if _, ok := r.resolveFile(p); ok {
+		return true
+	}
+
+	_, ok := r.dirs[directory(p)]
+
+	return ok
+}
+
+// tsconfig returns the config of the closest directory containing a tsconfig.json or jsconfig.json
+func (r resolver) tsconfig(d directory) (tsconfig, bool) {
+	for p := range ancestors(d) {
+		if cfg, ok := r.tsconfigs[p]; ok {
+			return cfg, true
+		}
+	}
+
+	return tsconfig{}, false
+}
+
+func isRelative(specifier string) bool {
+	return specifier == "." || specifier == ".." ||
+		strings.HasPrefix(specifier, "./") || strings.HasPrefix(specifier, "../")
+}
@@ -0,0 +1,267 @@
+package javascript
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"strings"
+)
+
+// tsconfig is the module resolution configuration of a tsconfig.json or jsconfig.json
+// with extends already applied and every directory relative to the analyzed directory
+type tsconfig struct {
+	// directory non-relative specifiers are resolved against, empty when not set
+	baseURL directory
+	paths   map[string][]string
+	// directory the paths substitutions are relative to
+	pathsBase directory
+}
+
+type tsconfigJSON struct {
+	// either a single config or a list of configs applied in order
+	Extends         json.RawMessage `json:"extends"`
+	CompilerOptions struct {
+		BaseURL *string             `json:"baseUrl"`
+		Paths   map[string][]string `json:"paths"`
+	} `json:"compilerOptions"`
+}
+
+// extractTsconfigs reads every tsconfig.json and jsconfig.json by directory
+// a tsconfig.json takes precedence over a jsconfig.json of the same directory
+func extractTsconfigs(dir fs.FS, manifestFiles []string) (map[directory]tsconfig, error) {
+	configs := make(map[directory]tsconfig)
+
+	for _, manifestFile := range manifestFiles {
+		base := path.Base(manifestFile)
+		if base != "tsconfig.json" && base != "jsconfig.json" {
+			continue
+		}
+
+		d := directory(path.Dir(manifestFile))
+		if _, ok := configs[d]; ok && base == "jsconfig.json" {
+			continue
+		}
+
+		cfg, err := readTsconfig(dir, manifestFile, map[string]struct{}{})
+		if err != nil {
+			return nil, err
+		}
+
+		configs[d] = cfg
+	}
+
+	return configs, nil
+}
+
+// readTsconfig reads a config and the configs it extends
+// configs extended from a package e.g. "@tsconfig/node20" are ignored as they are not analyzed
+func readTsconfig(dir fs.FS, configFile string, seen map[string]struct{}) (tsconfig, error) {
+	if _, ok := seen[configFile]; ok {
+		return tsconfig{}, fmt.Errorf("tsconfig %s extends itself", configFile)
+	}
+	seen[configFile] = struct{}{}
+
+	content, err := fs.ReadFile(dir, configFile)
+	if err != nil {
+		return tsconfig{}, err
+	}
+
+	var raw tsconfigJSON
+	if err := json.Unmarshal(stripJSONC(content), &raw); err != nil {
+		return tsconfig{}, fmt.Errorf("%s: %w", configFile, err)
+	}
+
+	var cfg tsconfig
+
+	for _, extends := range parseExtends(raw.Extends) {
+		if !strings.HasPrefix(extends, "./") && !strings.HasPrefix(extends, "../") {
+			slog.Debug("skipping tsconfig extended from a package", "config", configFile, "extends", extends)
+			continue
+		}
+
+		extendedFile := path.Join(path.Dir(configFile), extends)
+		if path.Ext(extendedFile) != ".json" {
+			extendedFile += ".json"
+		}
+
+		if !fs.ValidPath(extendedFile) {
+			// outside of the analyzed directory
+			continue
+		}
+
+		extended, err := readTsconfig(dir, extendedFile, seen)
+		if err != nil {
+			return tsconfig{}, err
+		}
+
+		if extended.baseURL != "" {
+			cfg.baseURL = extended.baseURL
+		}
+
+		if extended.paths != nil {
+			cfg.paths, cfg.pathsBase = extended.paths, extended.pathsBase
+		}
+	}
+
+	configDir := directory(path.Dir(configFile))
+
+	if raw.CompilerOptions.BaseURL != nil {
+		cfg.baseURL = directory(path.Join(string(configDir), *raw.CompilerOptions.BaseURL))
+	}
+
+	if raw.CompilerOptions.Paths != nil {
+		cfg.paths, cfg.pathsBase = raw.CompilerOptions.Paths, configDir
+	}
+
+	// paths are relative to the baseUrl when there is one
+	if cfg.baseURL != "" {
+		cfg.pathsBase = cfg.baseURL
+	}
+
+	return cfg, nil
+}
+
+func parseExtends(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var extends string
+	if err := json.Unmarshal(raw, &extends); err == nil {
+		return []string{extends}
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+
+	return nil
+}
+
+// alias returns the candidate paths of a specifier matching the paths of the config
+// the pattern with the longest prefix before its wildcard wins
+// e.g. "@/components/button" matching "@/*": ["src/*"] is "src/components/button"
+func (c tsconfig) alias(specifier string) []string {
+	var best string
+	var bestWildcard string
+	bestPrefix := -1
+
+	for pattern := range c.paths {
+		prefix, suffix, hasWildcard := strings.Cut(pattern, "*")
+
+		if !hasWildcard {
+			if pattern == specifier {
+				best, bestWildcard, bestPrefix = pattern, "", len(pattern)+1
+				break
+			}
+
+			continue
+		}
+
+		// ties are broken by pattern so the result does not depend on map order
+		if len(prefix) < bestPrefix ||
+			len(prefix) == bestPrefix && pattern > best ||
+			!strings.HasPrefix(specifier, prefix) ||
+			!strings.HasSuffix(specifier, suffix) ||
+			len(specifier) < len(prefix)+len(suffix) {
+			continue
+		}
+
+		best, bestPrefix = pattern, len(prefix)
+		bestWildcard = specifier[len(prefix) : len(specifier)-len(suffix)]
+	}
+
+	if bestPrefix < 0 {
+		return nil
+	}
+
+	candidates := make([]string, 0, len(c.paths[best]))
+	for _, target := range c.paths[best] {
+		candidates = append(
+			candidates,
+			path.Join(string(c.pathsBase), strings.Replace(target, "*", bestWildcard, 1)),
+		)
+	}
+
+	return candidates
+}
+
+// stripJSONC removes the comments and trailing commas tsconfig.json allows
+func stripJSONC(content []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(content))
+
+	inString := false
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+
+		if inString {
+			out.WriteByte(c)
+
+			switch c {
+			case '\\':
+				if i+1 < len(content) {
+					i++
+					out.WriteByte(content[i])
+				}
+			case '"':
+				inString = false
+			}
+
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out.WriteByte(c)
+		case c == '/' && i+1 < len(content) && content[i+1] == '/':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			out.WriteByte('\n')
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := bytes.Index(content[i+2:], []byte("*/"))
+			if end < 0 {
+				i = len(content)
+				continue
+			}
+			i += end + 3
+		case c == ',' && nextSignificant(content, i+1) == '}' || c == ',' && nextSignificant(content, i+1) == ']':
+			// trailing comma
+		default:
+			out.WriteByte(c)
+		}
+	}
+
+	return out.Bytes()
+}
+
+// nextSignificant returns the next byte that is not whitespace or part of a comment
+func nextSignificant(content []byte, from int) byte {
+	for i := from; i < len(content); i++ {
+		switch c := content[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			continue
+		case c == '/' && i+1 < len(content) && content[i+1] == '/':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := bytes.Index(content[i+2:], []byte("*/"))
+			if end < 0 {
+				return 0
+			}
+			i += end + 3
+		default:
+			return c
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,273 @@
+package javascript
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"iter"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/files"
+	"go.yaml.in/yaml/v3"
+)
+
+type directory string
+
+// layout is how the analyzed directories are grouped into package nodes
+// every directory is a package node except in a workspace, where every file of
+// a workspace package belongs to a single package node named after the package
+type layout struct {
+	// name of every package.json by directory, empty for unnamed packages
+	packages map[directory]string
+	// workspace packages by directory
+	members map[directory]string
+	// directory of workspace packages by name
+	names map[string]directory
+}
+
+type packageJSON struct {
+	Name string `json:"name"`
+	// either a list of globs or an object with a packages list of globs
+	Workspaces json.RawMessage `json:"workspaces"`
+}
+
+type pnpmWorkspace struct {
+	Packages []string `yaml:"packages"`
+}
+
+func listManifestFiles(ctx context.Context, dir fs.FS) ([]string, error) {
+	return files.ListFiles(
+		ctx,
+		dir,
+		files.SkipHiddenDirs(),
+		files.SkipHiddenFiles(),
+		skipDependencyDirs(),
+		manifestFileFilter(),
+	)
+}
+
+func manifestFileFilter() files.FileFilter {
+	return func(p string, d fs.DirEntry) bool {
+		if d.IsDir() {
+			return false
+		}
+
+		switch path.Base(p) {
+		case "package.json", "pnpm-workspace.yaml", "tsconfig.json", "jsconfig.json":
+			return false
+		}
+
+		return true
+	}
+}
+
+// extractLayout reads the package.json names and the workspace globs of
+// npm/yarn workspaces in package.json and of pnpm in pnpm-workspace.yaml
+// a manifest that cannot be parsed is logged and skipped with its workspace
+func extractLayout(ctx context.Context, dir fs.FS, manifestFiles []string) (layout, error) {
+	l := layout{
+		packages: make(map[directory]string),
+		members:  make(map[directory]string),
+		names:    make(map[string]directory),
+	}
+
+	// globs relative to the directory of the workspace root e.g. "packages/*"
+	workspaceGlobs := make(map[directory][]string)
+
+	for _, manifestFile := range manifestFiles {
+		d := directory(path.Dir(manifestFile))
+
+		switch path.Base(manifestFile) {
+		case "package.json":
+			content, err := fs.ReadFile(dir, manifestFile)
+			if err != nil {
+				return layout{}, err
+			}
+
+			var pkg packageJSON
+			if err := json.Unmarshal(content, &pkg); err != nil {
+				// the package is then neither a workspace root nor a member
+				slog.WarnContext(
+					ctx,
+					"failed to parse manifest, skipping its workspace",
+					"path",
+					manifestFile,
+					"error",
+					err,
+				)
+
+				continue
+			}
+
+			l.packages[d] = pkg.Name
+			workspaceGlobs[d] = append(workspaceGlobs[d], parseWorkspaces(pkg.Workspaces)...)
+		case "pnpm-workspace.yaml":
+			content, err := fs.ReadFile(dir, manifestFile)
+			if err != nil {
+				return layout{}, err
+			}
+
+			var ws pnpmWorkspace
+			if err := yaml.Unmarshal(content, &ws); err != nil {
+				slog.WarnContext(
+					ctx,
+					"failed to parse manifest, skipping its workspace",
+					"path",
+					manifestFile,
+					"error",
+					err,
+				)
+
+				continue
+			}
+
+			workspaceGlobs[d] = append(workspaceGlobs[d], ws.Packages...)
+		}
+	}
+
+	for root, globs := range workspaceGlobs {
+		for pkgDir, name := range l.packages {
+			if pkgDir == root || !inWorkspace(root, globs, pkgDir) {
+				continue
+			}
+
+			if name == "" {
+				name = string(pkgDir)
+			}
+
+			l.members[pkgDir] = name
+			l.names[name] = pkgDir
+		}
+	}
+
+	slog.DebugContext(ctx, "identified workspace packages", "packages", l.members)
+
+	return l, nil
+}
+
+// parseWorkspaces reads the workspaces of a package.json
+// e.g. ["packages/*"] or {"packages": ["packages/*"], "nohoist": [...]}
+func parseWorkspaces(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var globs []string
+	if err := json.Unmarshal(raw, &globs); err == nil {
+		return globs
+	}
+
+	var workspaces struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(raw, &workspaces); err == nil {
+		return workspaces.Packages
+	}
+
+	return nil
+}
+
+// inWorkspace reports whether pkgDir matches the globs of the workspace at root
+// globs starting with ! exclude matching packages e.g. "!packages/legacy"
+func inWorkspace(root directory, globs []string, pkgDir directory) bool {
+	matched := false
+
+	for _, glob := range globs {
+		exclude := strings.HasPrefix(glob, "!")
+		pattern := path.Join(string(root), strings.TrimPrefix(glob, "!"))
+
+		ok, err := doublestar.Match(pattern, string(pkgDir))
+		if err != nil || !ok {
+			continue
+		}
+
+		matched = !exclude
+		if exclude {
+			return false
+		}
+	}
+
+	return matched
+}
+
+// node returns the package node of a directory and the directory of the package it belongs to
+// e.g. "packages/ui/src/button" of the "@acme/ui" workspace package is "@acme/ui"
+// e.g. "src/components" of a package named "shop" is "shop/src/components"
+// e.g. "src/components" without a package.json is "src/components"
+func (l layout) node(d directory) (analyzer.Package, directory) {
+	for p := range ancestors(d) {
+		if name, ok := l.members[p]; ok {
+			return analyzer.Package(name), p
+		}
+	}
+
+	var project directory
+
+	for p := range ancestors(d) {
+		name, ok := l.packages[p]
+		if !ok {
+			continue
+		}
+
+		if project == "" {
+			project = p
+		}
+
+		// unnamed packages e.g. a package.json setting "type": "module" are skipped
+		if name == "" {
+			continue
+		}
+
+		if p == d {
+			return analyzer.Package(name), project
+		}
+
+		rel := string(d)
+		if p != "." {
+			rel = strings.TrimPrefix(rel, string(p)+"/")
+		}
+
+		return analyzer.Package(name + "/" + rel), project
+	}
+
+	if project == "" {
+		project = "."
+	}
+
+	return analyzer.Package(d), project
+}
+
+// ancestors yields d and every parent directory of d up to the root
+func ancestors(d directory) iter.Seq[directory] {
+	return func(yield func(directory) bool) {
+		for p := d; ; p = directory(path.Dir(string(p))) {
+			if !yield(p) || p == "." {
+				return
+			}
+		}
+	}
+}
+
+// workspacePackage returns the workspace package a bare specifier refers to
+// e.g. "@acme/ui/button" refers to the "@acme/ui" workspace package
+func (l layout) workspacePackage(specifier string) (analyzer.Package, directory, bool) {
+	name := packageName(specifier)
+
+	d, ok := l.names[name]
+
+	return analyzer.Package(name), d, ok
+}
+
+// packageName returns the package of a bare specifier e.g. "@acme/ui" for "@acme/ui/button" and "lodash" for "lodash/fp"
+func packageName(specifier string) string {
+	parts := strings.SplitN(specifier, "/", 3)
+	if strings.HasPrefix(specifier, "@") && len(parts) > 1 {
+		return parts[0] + "/" + parts[1]
+	}
+
+	return parts[0]
+}
@@ -0,0 +1,5 @@
+import sys
+
+from billing.invoice import total
+
+print(total(int(sys.argv[1])))
@@ -0,0 +1,6 @@
+from billing import tax
+from billing.tax import rate as tax_rate
+
+
+def total(amount):
+    return amount + tax.compute(amount) * tax_rate()
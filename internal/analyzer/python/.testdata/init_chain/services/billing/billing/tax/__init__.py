@@ -0,0 +1,9 @@
+import decimal
+
+
+def compute(amount):
+    return decimal.Decimal(amount) * rate()
+
+
+def rate():
+    return decimal.Decimal("0.13")
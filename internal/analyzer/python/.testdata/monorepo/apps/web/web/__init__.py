@@ -0,0 +1,8 @@
+import requests
+
+import common
+from web import views
+
+
+def fetch(url):
+    return requests.get(url, headers={"x": common.helper()})
@@ -0,0 +1,5 @@
+from common import helper
+
+
+def index():
+    return helper()
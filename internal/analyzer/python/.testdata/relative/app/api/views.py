@@ -0,0 +1,6 @@
+from . import v1
+from .. import models
+
+
+def user():
+    return v1.route("/users"), models.User
@@ -0,0 +1,9 @@
+import json
+
+from ..models import order
+from ..models.order import Repository
+
+
+def show(repo: Repository, id: int) -> str:
+    o: order.Order = repo.get(id)
+    return json.dumps({"id": o.id})
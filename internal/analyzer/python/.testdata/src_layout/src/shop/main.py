@@ -0,0 +1,6 @@
+import shop.api.views as views
+from shop import models
+
+
+def main() -> None:
+    print(views.show(models.Repository(), 1))
@@ -0,0 +1,3 @@
+from .order import Order, Repository
+
+__all__ = ["Order", "Repository"]
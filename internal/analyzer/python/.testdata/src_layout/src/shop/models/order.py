@@ -0,0 +1,12 @@
+from abc import ABC, abstractmethod
+from dataclasses import dataclass
+
+
+@dataclass
+class Order:
+    id: int
+
+
+class Repository(ABC):
+    @abstractmethod
+    def get(self, id: int) -> Order: ...
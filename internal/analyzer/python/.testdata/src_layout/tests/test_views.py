@@ -0,0 +1,5 @@
+from shop.api import views
+
+
+def test_show():
+    assert views.show
@@ -0,0 +1,82 @@
+package python
+
+import (
+	"bytes"
+	"encoding/gob"
+	"maps"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+)
+
+// pyFileEntry is a pyFile as stored in the parse cache
+type pyFileEntry struct {
+	Path          string
+	Project       directory
+	Module        string
+	RelativeTo    string
+	Pkg           analyzer.Package
+	Imports       []analyzer.Import
+	ImportLines   map[analyzer.Import]uint
+	Uses          []useEntry
+	Types         uint
+	AbstractTypes uint
+	Resolved      resolution
+}
+
+type useEntry struct {
+	Pkg    analyzer.Package
+	Symbol string
+}
+
+func (f pyFile) GobEncode() ([]byte, error) {
+	uses := make([]useEntry, 0, len(f.uses))
+	for _, u := range f.uses {
+		uses = append(uses, useEntry{Pkg: u.pkg, Symbol: u.symbol})
+	}
+
+	var buf bytes.Buffer
+
+	err := gob.NewEncoder(&buf).Encode(pyFileEntry{
+		Path:          f.path,
+		Project:       f.project,
+		Module:        f.module,
+		RelativeTo:    f.relativeTo,
+		Pkg:           f.pkg,
+		Imports:       f.imports,
+		ImportLines:   f.importLines,
+		Uses:          uses,
+		Types:         f.types,
+		AbstractTypes: f.abstractTypes,
+		Resolved:      f.resolved,
+	})
+
+	return buf.Bytes(), err
+}
+
+func (f *pyFile) GobDecode(data []byte) error {
+	var e pyFileEntry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&e); err != nil {
+		return err
+	}
+
+	*f = pyFile{
+		path:          e.Path,
+		project:       e.Project,
+		module:        e.Module,
+		relativeTo:    e.RelativeTo,
+		pkg:           e.Pkg,
+		imports:       e.Imports,
+		importLines:   make(map[analyzer.Import]uint, len(e.ImportLines)),
+		types:         e.Types,
+		abstractTypes: e.AbstractTypes,
+		resolved:      e.Resolved,
+	}
+
+	maps.Copy(f.importLines, e.ImportLines)
+
+	for _, u := range e.Uses {
+		f.uses = append(f.uses, use{pkg: u.Pkg, symbol: u.Symbol})
+	}
+
+	return nil
+}
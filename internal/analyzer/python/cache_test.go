@@ -0,0 +1,85 @@
+package python
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/ts"
+	"github.com/stretchr/testify/require"
+)
+
+// markCached sets the number of types of every cached file to 99 so a file read from the cache can be told apart
+func markCached(t *testing.T, cacheDir string) {
+	t.Helper()
+
+	err := filepath.WalkDir(cacheDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := os.ReadFile(p)
+		require.NoError(t, err)
+
+		var f pyFile
+		require.NoError(t, gob.NewDecoder(bytes.NewReader(data)).Decode(&f))
+		f.types = 99
+
+		var buf bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&buf).Encode(f))
+
+		return os.WriteFile(p, buf.Bytes(), 0o644)
+	})
+	require.NoError(t, err)
+}
+
+func TestCachedResolution(t *testing.T) {
+	t.Parallel()
+
+	cacheDir := t.TempDir()
+	ctx := ts.WithCache(context.Background(), ts.NewCache(cacheDir))
+
+	dir := fstest.MapFS{
+		"app/__init__.py": {Data: []byte("models = None\n")},
+		"web/api.py":      {Data: []byte("from app import models\n")},
+		"tools/gen.py":    {Data: []byte("import os\n")},
+	}
+
+	analyze := func() map[string]pyFile {
+		pyFiles, _, err := PythonAnalyzer().analyze(ctx, dir)
+		require.NoError(t, err)
+
+		byPath := make(map[string]pyFile, len(pyFiles))
+		for _, f := range pyFiles {
+			byPath[f.path] = f
+		}
+
+		return byPath
+	}
+
+	analyze()
+	markCached(t, cacheDir)
+
+	// a module no file resolved keeps every file cached
+	dir["other/x.py"] = &fstest.MapFile{Data: []byte("import os\n")}
+
+	got := analyze()
+	require.EqualValues(t, 99, got["web/api.py"].types)
+	require.EqualValues(t, 99, got["tools/gen.py"].types)
+	require.Equal(t, []use{{pkg: "app", symbol: "app.models"}}, got["web/api.py"].uses)
+
+	// app.models was resolved as a variable of app by web/api.py only
+	dir["app/models.py"] = &fstest.MapFile{Data: []byte("class User: pass\n")}
+
+	got = analyze()
+	require.Zero(t, got["web/api.py"].types)
+	require.Equal(t, []analyzer.Import{`"app"`}, got["web/api.py"].imports)
+	require.Empty(t, got["web/api.py"].uses)
+	require.EqualValues(t, 99, got["tools/gen.py"].types)
+}
@@ -0,0 +1,44 @@
+package python
+
+import (
+	_ "embed"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+)
+
+//go:generate sh -c "python3 -c 'import sys; print(chr(10).join(sorted(sys.stdlib_module_names)))' > stdlib.txt"
+//go:embed stdlib.txt
+var stdlibList string
+
+// stdlib is the set of top level standard library modules
+var stdlib = func() map[string]struct{} {
+	modules := make(map[string]struct{})
+	for _, m := range strings.Fields(stdlibList) {
+		modules[m] = struct{}{}
+	}
+
+	return modules
+}()
+
+// importOrigin classifies an import of a file of fileProject against the analyzed modules
+// e.g. "os.path" is std, a module of fileProject is first-party and a module of any other
+// analyzed project e.g. a sibling package of a monorepo is workspace
+func importOrigin(i analyzer.Import, fileProject directory, idx index) analyzer.Origin {
+	name := string(i.Package())
+
+	if p, ok := idx.project(name); ok {
+		if p == fileProject {
+			return analyzer.FirstParty
+		}
+
+		return analyzer.Workspace
+	}
+
+	top, _, _ := strings.Cut(name, ".")
+	if _, ok := stdlib[top]; ok {
+		return analyzer.Std
+	}
+
+	return analyzer.ThirdParty
+}
@@ -0,0 +1,226 @@
+package python_test
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer/python"
+	"github.com/stretchr/testify/require"
+)
+
+type packageImports struct {
+	Pkg     analyzer.Package
+	Imports []analyzer.Import
+}
+
+func toSortedSlice(pi analyzer.PackageImports) []packageImports {
+	result := make([]packageImports, 0, len(pi))
+	for pkg, imports := range pi {
+		sorted := make([]analyzer.Import, len(imports))
+		copy(sorted, imports)
+		slices.Sort(sorted)
+		result = append(result, packageImports{Pkg: pkg, Imports: sorted})
+	}
+	return result
+}
+
+func TestPythonAnalyze(t *testing.T) {
+	tests := map[string]struct {
+		dir  string
+		want analyzer.PackageImports
+	}{
+		"src_layout": {
+			dir: ".testdata/src_layout",
+			want: analyzer.PackageImports{
+				"shop": []analyzer.Import{
+					`"shop.api"`,
+					`"shop.models"`,
+				},
+				"shop.api": []analyzer.Import{
+					`"json"`,
+					`"shop.models"`,
+				},
+				"shop.models": []analyzer.Import{
+					`"abc"`,
+					`"dataclasses"`,
+				},
+				"tests": []analyzer.Import{
+					`"shop.api"`,
+				},
+			},
+		},
+		"init_chain": {
+			dir: ".testdata/init_chain",
+			want: analyzer.PackageImports{
+				"billing": []analyzer.Import{
+					`"billing.tax"`,
+				},
+				"billing.tax": []analyzer.Import{
+					`"decimal"`,
+				},
+				"run": []analyzer.Import{
+					`"sys"`,
+					`"billing"`,
+				},
+			},
+		},
+		"relative": {
+			dir: ".testdata/relative",
+			want: analyzer.PackageImports{
+				"app": []analyzer.Import{},
+				// from . import v1 and from .. import models
+				"app.api": []analyzer.Import{
+					`"app.api.v1"`,
+					`"app"`,
+				},
+				"app.api.v1": []analyzer.Import{},
+			},
+		},
+		"monorepo": {
+			dir: ".testdata/monorepo",
+			want: analyzer.PackageImports{
+				"common": []analyzer.Import{},
+				"web": []analyzer.Import{
+					`"requests"`,
+					`"common"`,
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := os.DirFS(tt.dir)
+			got, err := python.PythonAnalyzer().Analyze(context.Background(), dir)
+			require.NoError(t, err)
+			require.ElementsMatch(t, toSortedSlice(tt.want), toSortedSlice(got))
+		})
+	}
+}
+
+func TestPythonAnalyzeSources(t *testing.T) {
+	dir := os.DirFS(".testdata/monorepo")
+
+	got, err := python.PythonAnalyzer().AnalyzeSources(context.Background(), dir)
+	require.NoError(t, err)
+
+	require.Equal(t, analyzer.ImportSources{
+		"common": {},
+		"web": {
+			`"requests"`: {{File: "apps/web/web/__init__.py", Line: 1}},
+			`"common"`: {
+				{File: "apps/web/web/__init__.py", Line: 3},
+				{File: "apps/web/web/views.py", Line: 1},
+			},
+		},
+	}, got)
+}
+
+func TestPythonAnalyzeOrigins(t *testing.T) {
+	tests := map[string]struct {
+		dir  string
+		want analyzer.ImportOrigins
+	}{
+		"init_chain": {
+			dir: ".testdata/init_chain",
+			want: analyzer.ImportOrigins{
+				"billing":     {`"billing.tax"`: analyzer.FirstParty},
+				"billing.tax": {`"decimal"`: analyzer.Std},
+				"run": {
+					`"sys"`:     analyzer.Std,
+					`"billing"`: analyzer.FirstParty,
+				},
+			},
+		},
+		"relative": {
+			dir: ".testdata/relative",
+			want: analyzer.ImportOrigins{
+				"app": {},
+				"app.api": {
+					`"app.api.v1"`: analyzer.FirstParty,
+					`"app"`:        analyzer.FirstParty,
+				},
+				"app.api.v1": {},
+			},
+		},
+		"monorepo": {
+			dir: ".testdata/monorepo",
+			want: analyzer.ImportOrigins{
+				"common": {},
+				"web": {
+					`"requests"`: analyzer.ThirdParty,
+					`"common"`:   analyzer.Workspace,
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := os.DirFS(tt.dir)
+			got, err := python.PythonAnalyzer().AnalyzeOrigins(context.Background(), dir)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPythonAnalyzeWithOrigins(t *testing.T) {
+	dir := os.DirFS(".testdata/monorepo")
+
+	a := python.PythonAnalyzer(python.WithOrigins(analyzer.FirstParty, analyzer.Workspace))
+
+	got, err := a.Analyze(context.Background(), dir)
+	require.NoError(t, err)
+	require.Equal(t, analyzer.PackageImports{
+		"common": {},
+		"web":    {`"common"`},
+	}, got)
+
+	metrics, err := a.AnalyzeV2(context.Background(), dir)
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	require.Equal(t, analyzer.Package("web"), metrics[1].Package)
+	require.Equal(t, float64(1), metrics[1].OutwardCoupling())
+}
+
+func TestPythonAnalyzeV2(t *testing.T) {
+	dir := os.DirFS(".testdata/src_layout")
+
+	metrics, err := python.PythonAnalyzer().AnalyzeV2(context.Background(), dir)
+	require.NoError(t, err)
+
+	type result struct {
+		Pkg                       analyzer.Package
+		Ca, Ce                    float64
+		AbstractTypes, TotalTypes uint
+	}
+
+	got := make([]result, 0, len(metrics))
+	for _, m := range metrics {
+		got = append(got, result{
+			Pkg:           m.Package,
+			Ca:            m.InwardCoupling(),
+			Ce:            m.OutwardCoupling(),
+			AbstractTypes: m.AbstractTypes,
+			TotalTypes:    m.TotalTypes,
+		})
+	}
+
+	require.Equal(t, []result{
+		// views.show
+		{Pkg: "shop", Ca: 0, Ce: 2},
+		// json.dumps, order.Order, Repository
+		{Pkg: "shop.api", Ca: 2, Ce: 3},
+		// abc.ABC, abc.abstractmethod, dataclasses.dataclass
+		{Pkg: "shop.models", Ca: 3, Ce: 3, AbstractTypes: 1, TotalTypes: 2},
+		{Pkg: "tests", Ca: 0, Ce: 1},
+	}, got)
+}
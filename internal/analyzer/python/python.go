@@ -0,0 +1,856 @@
+package python
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/files"
+	"github.com/flamingoosesoftwareinc/uda/internal/ts"
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+	tspython "github.com/tree-sitter/tree-sitter-python/bindings/go"
+)
+
+type pythonAnalyzer struct {
+	origins map[analyzer.Origin]struct{}
+}
+
+// Option configures the python analyzer
+type Option func(*pythonAnalyzer)
+
+// WithOrigins restricts the analysis to imports of the given origins
+// e.g. WithOrigins(analyzer.FirstParty, analyzer.Workspace) ignores coupling to std and third-party modules
+func WithOrigins(origins ...analyzer.Origin) Option {
+	return func(p *pythonAnalyzer) {
+		p.origins = make(map[analyzer.Origin]struct{}, len(origins))
+		for _, o := range origins {
+			p.origins[o] = struct{}{}
+		}
+	}
+}
+
+var (
+	_ analyzer.Analyzer       = &pythonAnalyzer{}
+	_ analyzer.SourceAnalyzer = &pythonAnalyzer{}
+	_ analyzer.OriginAnalyzer = &pythonAnalyzer{}
+	_ analyzer.AllAnalyzer    = &pythonAnalyzer{}
+)
+
+// PythonAnalyzer analyzes the imports between python packages
+// a package is a directory of modules e.g. "app/models/user.py" belongs to the "app.models" package
+// and modules at the top of a source root are their own package
+func PythonAnalyzer(opts ...Option) *pythonAnalyzer {
+	p := &pythonAnalyzer{}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// AnalyzeAll derives every analysis from a single extraction of the .py files of dir
+func (p *pythonAnalyzer) AnalyzeAll(ctx context.Context, dir fs.FS) (analyzer.Result, error) {
+	pyFiles, idx, err := p.analyze(ctx, dir)
+	if err != nil {
+		return analyzer.Result{}, err
+	}
+
+	return analyzer.Result{
+		Imports: packageImports(pyFiles),
+		Metrics: buildMetrics(pyFiles),
+		Sources: importSources(pyFiles),
+		Origins: importOrigins(pyFiles, idx),
+	}, nil
+}
+
+func (p *pythonAnalyzer) AnalyzeV2(ctx context.Context, dir fs.FS) ([]analyzer.Metrics, error) {
+	pyFiles, _, err := p.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildMetrics(pyFiles), nil
+}
+
+func buildMetrics(pyFiles []pyFile) []analyzer.Metrics {
+	outward := make(map[analyzer.Package]analyzer.PackageCouplingStats)
+	types := make(map[analyzer.Package]pyFile)
+
+	for _, f := range pyFiles {
+		t := types[f.pkg]
+		t.types += f.types
+		t.abstractTypes += f.abstractTypes
+		types[f.pkg] = t
+
+		stats, ok := outward[f.pkg]
+		if !ok {
+			stats = make(analyzer.PackageCouplingStats)
+			outward[f.pkg] = stats
+		}
+
+		for _, u := range f.uses {
+			stats.Add(u.pkg, u.symbol)
+		}
+	}
+
+	metrics := analyzer.BuildMetrics(outward)
+	for i := range metrics {
+		metrics[i].TotalTypes = types[metrics[i].Package].types
+		metrics[i].AbstractTypes = types[metrics[i].Package].abstractTypes
+	}
+
+	return metrics
+}
+
+func (p *pythonAnalyzer) Analyze(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.PackageImports, error) {
+	pyFiles, _, err := p.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return packageImports(pyFiles), nil
+}
+
+func packageImports(pyFiles []pyFile) analyzer.PackageImports {
+	pi := make(analyzer.PackageImports)
+	seen := make(map[analyzer.Package]map[analyzer.Import]struct{})
+
+	// a package is made up of every module in its directory so imports are merged across files
+	for _, f := range pyFiles {
+		pkgSeen, ok := seen[f.pkg]
+		if !ok {
+			pkgSeen = make(map[analyzer.Import]struct{}, len(f.imports))
+			seen[f.pkg] = pkgSeen
+			pi[f.pkg] = make([]analyzer.Import, 0, len(f.imports))
+		}
+
+		for _, i := range f.imports {
+			if _, ok := pkgSeen[i]; ok {
+				continue
+			}
+
+			pkgSeen[i] = struct{}{}
+			pi[f.pkg] = append(pi[f.pkg], i)
+		}
+	}
+
+	return pi
+}
+
+func (p *pythonAnalyzer) AnalyzeSources(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.ImportSources, error) {
+	pyFiles, _, err := p.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return importSources(pyFiles), nil
+}
+
+func importSources(pyFiles []pyFile) analyzer.ImportSources {
+	sources := make(analyzer.ImportSources)
+
+	for _, f := range pyFiles {
+		pkgSources, ok := sources[f.pkg]
+		if !ok {
+			pkgSources = make(map[analyzer.Import][]analyzer.Location, len(f.imports))
+			sources[f.pkg] = pkgSources
+		}
+
+		for _, i := range f.imports {
+			loc := analyzer.Location{File: f.path, Line: f.importLines[i]}
+			if slices.Contains(pkgSources[i], loc) {
+				continue
+			}
+
+			pkgSources[i] = append(pkgSources[i], loc)
+		}
+	}
+
+	return sources
+}
+
+func (p *pythonAnalyzer) AnalyzeOrigins(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.ImportOrigins, error) {
+	pyFiles, idx, err := p.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return importOrigins(pyFiles, idx), nil
+}
+
+func importOrigins(pyFiles []pyFile, idx index) analyzer.ImportOrigins {
+	origins := make(analyzer.ImportOrigins)
+
+	for _, f := range pyFiles {
+		pkgOrigins, ok := origins[f.pkg]
+		if !ok {
+			pkgOrigins = make(map[analyzer.Import]analyzer.Origin, len(f.imports))
+			origins[f.pkg] = pkgOrigins
+		}
+
+		for _, i := range f.imports {
+			pkgOrigins[i] = importOrigin(i, f.project, idx)
+		}
+	}
+
+	return origins
+}
+
+func (p *pythonAnalyzer) analyze(ctx context.Context, dir fs.FS) ([]pyFile, index, error) {
+	// pyproject.toml and setup.cfg mark a project and configure where its packages live
+	// e.g. a src layout makes "src/app/main.py" the "app.main" module
+	projectFiles, err := listProjectFiles(ctx, dir)
+	if err != nil {
+		return nil, index{}, err
+	}
+
+	projects, err := extractProjects(ctx, dir, projectFiles)
+	if err != nil {
+		return nil, index{}, err
+	}
+	slog.DebugContext(ctx, "identified python projects", "projects", projects)
+
+	pyFilepaths, err := listPyFiles(ctx, dir)
+	if err != nil {
+		return nil, index{}, err
+	}
+
+	// every module name is known up front so imports can be resolved to the package
+	// they belong to e.g. "from app import models" imports the "app.models" package
+	// when app/models is a directory but the "app" package when models is a variable
+	pyFiles, idx := indexModules(dir, pyFilepaths, projects)
+
+	pyFiles, err = analyzePyFiles(ctx, dir, pyFiles, idx)
+	if err != nil {
+		return nil, index{}, err
+	}
+
+	if p.origins != nil {
+		for i := range pyFiles {
+			allowed := func(pkg analyzer.Package) bool {
+				_, ok := p.origins[importOrigin(quote(pkg), pyFiles[i].project, idx)]
+				return ok
+			}
+
+			pyFiles[i].imports = slices.DeleteFunc(pyFiles[i].imports, func(imp analyzer.Import) bool {
+				return !allowed(imp.Package())
+			})
+			pyFiles[i].uses = slices.DeleteFunc(pyFiles[i].uses, func(u use) bool {
+				return !allowed(u.pkg)
+			})
+		}
+	}
+
+	return pyFiles, idx, nil
+}
+
+func listPyFiles(ctx context.Context, dir fs.FS) ([]string, error) {
+	return files.ListFiles(
+		ctx,
+		dir,
+		files.SkipHiddenDirs(),
+		files.SkipHiddenFiles(),
+		skipEnvironmentDirs(),
+		pyFileFilter(),
+	)
+}
+
+func pyFileFilter() files.FileFilter {
+	return func(p string, d fs.DirEntry) bool {
+		if d.IsDir() {
+			return false
+		}
+		return path.Ext(p) != ".py"
+	}
+}
+
+// skipEnvironmentDirs skips installed dependencies and bytecode caches
+func skipEnvironmentDirs() files.FileFilter {
+	return func(p string, d fs.DirEntry) bool {
+		if !d.IsDir() {
+			return false
+		}
+
+		switch path.Base(p) {
+		case "__pycache__", "site-packages", "venv", "node_modules":
+			return true
+		}
+
+		return false
+	}
+}
+
+// index is every module of the analyzed projects
+type index struct {
+	modules map[string]indexEntry
+}
+
+type indexEntry struct {
+	pkg     analyzer.Package
+	project directory
+}
+
+// known reports whether name is an analyzed module or package
+func (idx index) known(name string) bool {
+	_, ok := idx.modules[name]
+	return ok
+}
+
+// pkg returns the package a module belongs to, modules that are not analyzed are their own package
+func (idx index) pkg(name string) analyzer.Package {
+	if e, ok := idx.modules[name]; ok {
+		return e.pkg
+	}
+
+	return analyzer.Package(name)
+}
+
+// resolves reports whether idx resolves every module of r as the index r was recorded against
+func (idx index) resolves(r resolution) bool {
+	for name, l := range r {
+		if idx.known(name) != l.Known || idx.pkg(name) != l.Pkg {
+			return false
+		}
+	}
+
+	return true
+}
+
+// resolution is what the extraction of a file looked up in the index e.g. whether "app.models" is a module
+// the cached extraction of a file is reused while the index resolves those modules the same way
+type resolution map[string]lookup
+
+type lookup struct {
+	Known bool
+	Pkg   analyzer.Package
+}
+
+// recorder is the index as seen by the extraction of a single file, it records every lookup
+type recorder struct {
+	idx      index
+	resolved resolution
+}
+
+func (r recorder) known(name string) bool {
+	r.record(name)
+	return r.idx.known(name)
+}
+
+func (r recorder) pkg(name string) analyzer.Package {
+	r.record(name)
+	return r.idx.pkg(name)
+}
+
+func (r recorder) record(name string) {
+	r.resolved[name] = lookup{Known: r.idx.known(name), Pkg: r.idx.pkg(name)}
+}
+
+func (idx index) project(name string) (directory, bool) {
+	e, ok := idx.modules[name]
+	return e.project, ok
+}
+
+func indexModules(dir fs.FS, pyFilepaths []string, projects []project) ([]pyFile, index) {
+	pyFiles := make([]pyFile, 0, len(pyFilepaths))
+	idx := index{modules: make(map[string]indexEntry, len(pyFilepaths))}
+
+	for _, pyFilepath := range pyFilepaths {
+		root, projectDir := sourceRoot(dir, pyFilepath, projects)
+		module := moduleName(pyFilepath, root)
+		isPackage := path.Base(pyFilepath) == "__init__.py"
+
+		// relative imports are resolved from the package containing the module
+		relativeTo := module
+		if !isPackage {
+			relativeTo = parent(module)
+		}
+
+		pkg := analyzer.Package(relativeTo)
+		if pkg == "" {
+			pkg = analyzer.Package(module)
+		}
+
+		idx.modules[module] = indexEntry{pkg: pkg, project: projectDir}
+
+		// parent packages are importable even without an __init__.py e.g. namespace packages
+		for p := parent(module); p != ""; p = parent(p) {
+			if _, ok := idx.modules[p]; !ok {
+				idx.modules[p] = indexEntry{pkg: analyzer.Package(p), project: projectDir}
+			}
+		}
+
+		pyFiles = append(pyFiles, pyFile{
+			path:       pyFilepath,
+			project:    projectDir,
+			module:     module,
+			relativeTo: relativeTo,
+			pkg:        pkg,
+		})
+	}
+
+	return pyFiles, idx
+}
+
+// parent returns the package containing a module e.g. "app.models" for "app.models.user"
+func parent(module string) string {
+	i := strings.LastIndex(module, ".")
+	if i < 0 {
+		return ""
+	}
+
+	return module[:i]
+}
+
+func join(pkg, name string) string {
+	switch {
+	case pkg == "":
+		return name
+	case name == "":
+		// the package itself e.g. "from . import x"
+		return pkg
+	}
+
+	return pkg + "." + name
+}
+
+func quote(pkg analyzer.Package) analyzer.Import {
+	return analyzer.Import(`"` + pkg + `"`)
+}
+
+// pyFile is what was extracted from a single .py file
+type pyFile struct {
+	path string
+	// directory of the pyproject.toml or setup.cfg the file belongs to, "." when there is none
+	project directory
+	module  string
+	// package relative imports are resolved against, empty for top level modules
+	relativeTo string
+	pkg        analyzer.Package
+	imports    []analyzer.Import
+	// line of the import statement of each import
+	importLines map[analyzer.Import]uint
+	// modules looked up in the index while resolving the imports of the file
+	resolved resolution
+	// symbols of other packages used in the file
+	uses []use
+	// number of top level classes and how many of them are abstract
+	types, abstractTypes uint
+}
+
+// use is a reference to a symbol of a package e.g. "app.db.Session" of "app.db"
+type use struct {
+	pkg    analyzer.Package
+	symbol string
+}
+
+// binding is what a name bound by an import refers to, either a module or a symbol of a module
+// e.g. "import app.db as db" binds db to the "app.db" module
+// e.g. "from app.db import Session" binds Session to the "app.db.Session" symbol
+type binding struct {
+	module, symbol string
+}
+
+func analyzePyFiles(
+	ctx context.Context,
+	dir fs.FS,
+	pyFiles []pyFile,
+	idx index,
+) ([]pyFile, error) {
+	pyLanguage := treesitter.NewLanguage(tspython.Language())
+
+	query, err := ts.LoadQuery(ts.PYTHON, ts.AnalyzeQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	return ts.Map(
+		ctx,
+		pyFiles,
+		func() (*ts.Worker, error) { return ts.NewWorker(pyLanguage) },
+		func(ctx context.Context, w *ts.Worker, f pyFile) (pyFile, error) {
+			salt := ts.Digest(f.project, f.module, f.relativeTo, f.pkg)
+			return analyzePyFile(ctx, w, dir, f, idx, query, salt)
+		},
+	)
+}
+
+// analyzePyFile extracts the imports, uses and classes of a single .py file
+func analyzePyFile(
+	ctx context.Context,
+	w *ts.Worker,
+	dir fs.FS,
+	f pyFile,
+	idx index,
+	query string,
+	salt string,
+) (pyFile, error) {
+	// a module added or removed elsewhere only invalidates the files that resolved it
+	valid := func(cached pyFile) bool {
+		return idx.resolves(cached.resolved)
+	}
+
+	return ts.ExtractValid(
+		ctx,
+		w,
+		dir,
+		f.path,
+		query,
+		salt,
+		valid,
+		func(q *treesitter.Query, matches treesitter.QueryMatches, text []byte) (pyFile, error) {
+			captureNames := q.CaptureNames()
+
+			rec := recorder{idx: idx, resolved: make(resolution)}
+			r := resolver{
+				idx:        rec,
+				relativeTo: f.relativeTo,
+				bindings:   make(map[string]binding),
+				imported:   make(map[string]struct{}),
+			}
+			chains := make([][]string, 0, 32)
+			f.importLines = make(map[analyzer.Import]uint)
+
+			addImport := func(module string, line uint) {
+				pkg := rec.pkg(module)
+				if pkg == f.pkg {
+					return
+				}
+
+				imp := quote(pkg)
+				if _, ok := f.importLines[imp]; ok {
+					return
+				}
+
+				f.imports = append(f.imports, imp)
+				f.importLines[imp] = line
+			}
+
+			for match := matches.Next(); match != nil; match = matches.Next() {
+				for _, capture := range match.Captures {
+					node := capture.Node
+					captureName := captureNames[capture.Index]
+					line := node.StartPosition().Row + 1
+
+					switch captureName {
+					case "import":
+						slog.Debug("import detected", "import", node.Utf8Text(text))
+						for _, module := range r.importStatement(&node, text) {
+							addImport(module, line)
+						}
+					case "import_from":
+						slog.Debug("import_from detected", "import", node.Utf8Text(text))
+						modules, symbols := r.importFromStatement(&node, text)
+						for _, module := range modules {
+							addImport(module, line)
+						}
+						f.uses = append(f.uses, symbols...)
+					case "attribute_use":
+						if chain := outermostChain(&node, text); chain != nil {
+							chains = append(chains, chain)
+						}
+					case "type_declaration":
+						slog.Debug("type_declaration detected", "class", node.Utf8Text(text))
+						f.types++
+						if isAbstractClass(&node, text) {
+							f.abstractTypes++
+						}
+					default:
+						slog.Debug(
+							"unknown capture name",
+							"captureName",
+							captureName,
+							"value",
+							node.Utf8Text(text),
+						)
+					}
+				}
+			}
+
+			// chains are resolved once every import is known as imports may follow their use
+			// e.g. an import inside a function defined below a top level use
+			for _, chain := range chains {
+				if u, ok := r.resolve(chain); ok {
+					f.uses = append(f.uses, u)
+				}
+			}
+
+			f.uses = slices.DeleteFunc(f.uses, func(u use) bool {
+				return u.pkg == f.pkg
+			})
+			f.resolved = rec.resolved
+
+			slog.DebugContext(
+				ctx,
+				"processed file",
+				"path",
+				f.path,
+				"module",
+				f.module,
+				"pkgDetected",
+				f.pkg,
+				"imports",
+				f.imports,
+			)
+
+			return f, nil
+		},
+	)
+}
+
+// resolver resolves the imports of a single file and the names they bind
+type resolver struct {
+	idx        recorder
+	relativeTo string
+	bindings   map[string]binding
+	// every module imported by the file including the parents of dotted imports
+	imported map[string]struct{}
+}
+
+// importStatement returns the modules imported by e.g. "import os.path, app.db as db"
+func (r resolver) importStatement(node *treesitter.Node, text []byte) []string {
+	modules := []string{}
+
+	for _, name := range childrenByFieldName(node, "name") {
+		switch name.Kind() {
+		case "dotted_name":
+			module := dottedName(&name, text)
+			r.markImported(module)
+
+			// "import a.b" binds a, which gives access to a.b
+			top, _, _ := strings.Cut(module, ".")
+			r.bindings[top] = binding{module: top}
+			modules = append(modules, module)
+		case "aliased_import":
+			module := dottedName(name.ChildByFieldName("name"), text)
+			r.markImported(module)
+
+			r.bindings[name.ChildByFieldName("alias").Utf8Text(text)] = binding{module: module}
+			modules = append(modules, module)
+		}
+	}
+
+	return modules
+}
+
+// importFromStatement returns the modules imported by e.g. "from ..app import db, Session"
+// and the symbols it uses, a name is a module when it is an analyzed submodule otherwise a symbol
+func (r resolver) importFromStatement(node *treesitter.Node, text []byte) ([]string, []use) {
+	from := r.fromModule(node.ChildByFieldName("module_name"), text)
+
+	names := childrenByFieldName(node, "name")
+	if len(names) == 0 {
+		// wildcard import e.g. "from app.db import *"
+		return []string{from}, nil
+	}
+
+	modules := []string{}
+	symbols := []use{}
+
+	for _, name := range names {
+		imported, alias := &name, &name
+		if name.Kind() == "aliased_import" {
+			imported, alias = name.ChildByFieldName("name"), name.ChildByFieldName("alias")
+		}
+
+		full := join(from, dottedName(imported, text))
+		bound := dottedName(alias, text)
+
+		if r.idx.known(full) {
+			r.markImported(full)
+			r.bindings[bound] = binding{module: full}
+			modules = append(modules, full)
+
+			continue
+		}
+
+		r.markImported(from)
+		r.bindings[bound] = binding{module: from, symbol: full}
+		modules = append(modules, from)
+		symbols = append(symbols, use{pkg: r.idx.pkg(from), symbol: full})
+	}
+
+	return modules, symbols
+}
+
+// fromModule resolves the module of a from import against the package of the file
+// e.g. "..pkg" in a module of "app.api" is "app.pkg"
+func (r resolver) fromModule(node *treesitter.Node, text []byte) string {
+	if node == nil {
+		return ""
+	}
+
+	if node.Kind() != "relative_import" {
+		return dottedName(node, text)
+	}
+
+	base := r.relativeTo
+	name := ""
+
+	for i := range node.NamedChildCount() {
+		child := node.NamedChild(i)
+
+		switch child.Kind() {
+		case "import_prefix":
+			// the first dot is the current package, every other dot is a parent
+			for range strings.Count(child.Utf8Text(text), ".") - 1 {
+				base = parent(base)
+			}
+		case "dotted_name":
+			name = dottedName(child, text)
+		}
+	}
+
+	return join(base, name)
+}
+
+func (r resolver) markImported(module string) {
+	for m := module; m != ""; m = parent(m) {
+		r.imported[m] = struct{}{}
+	}
+}
+
+// resolve returns the symbol an attribute chain refers to
+// e.g. ["os", "path", "join"] after "import os.path" is the "os.path.join" symbol of "os.path"
+func (r resolver) resolve(chain []string) (use, bool) {
+	b, ok := r.bindings[chain[0]]
+	if !ok {
+		// not an imported name e.g. an attribute of a local variable
+		return use{}, false
+	}
+
+	if b.symbol != "" {
+		return use{pkg: r.idx.pkg(b.module), symbol: b.symbol}, true
+	}
+
+	module := b.module
+	rest := chain[1:]
+
+	// descend into submodules for as long as they are imported or analyzed
+	for len(rest) > 0 {
+		next := join(module, rest[0])
+
+		_, imported := r.imported[next]
+		if !imported && !r.idx.known(next) {
+			break
+		}
+
+		module, rest = next, rest[1:]
+	}
+
+	if len(rest) == 0 {
+		// a reference to the module itself rather than one of its symbols
+		return use{}, false
+	}
+
+	return use{pkg: r.idx.pkg(module), symbol: join(module, rest[0])}, true
+}
+
+// outermostChain returns the dotted names of an attribute chain of identifiers e.g. ["os", "path", "join"]
+// for "os.path.join(...)", inner attributes of a chain return nil so every chain is only counted once
+func outermostChain(node *treesitter.Node, text []byte) []string {
+	if p := node.Parent(); p != nil && p.Kind() == "attribute" {
+		if object := p.ChildByFieldName("object"); object != nil && object.Id() == node.Id() {
+			return nil
+		}
+	}
+
+	return attributeChain(node, text)
+}
+
+func attributeChain(node *treesitter.Node, text []byte) []string {
+	switch node.Kind() {
+	case "identifier":
+		return []string{node.Utf8Text(text)}
+	case "attribute":
+		object := attributeChain(node.ChildByFieldName("object"), text)
+		if object == nil {
+			// e.g. a call or subscript "get_db().session"
+			return nil
+		}
+
+		return append(object, node.ChildByFieldName("attribute").Utf8Text(text))
+	}
+
+	return nil
+}
+
+// isAbstractClass reports whether a class is an abstract base class or a protocol
+// e.g. "class Repo(ABC)", "class Repo(typing.Protocol[T])" or "class Repo(metaclass=abc.ABCMeta)"
+func isAbstractClass(node *treesitter.Node, text []byte) bool {
+	superclasses := node.ChildByFieldName("superclasses")
+	if superclasses == nil {
+		return false
+	}
+
+	for i := range superclasses.NamedChildCount() {
+		arg := superclasses.NamedChild(i)
+
+		switch arg.Kind() {
+		case "keyword_argument":
+			if arg.ChildByFieldName("name").Utf8Text(text) == "metaclass" &&
+				lastName(arg.ChildByFieldName("value"), text) == "ABCMeta" {
+				return true
+			}
+		case "subscript":
+			if name := lastName(arg.ChildByFieldName("value"), text); name == "Protocol" ||
+				name == "ABC" {
+				return true
+			}
+		default:
+			if name := lastName(arg, text); name == "Protocol" || name == "ABC" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// lastName returns the last name of a dotted expression e.g. "ABCMeta" for "abc.ABCMeta"
+func lastName(node *treesitter.Node, text []byte) string {
+	if node == nil {
+		return ""
+	}
+
+	name := node.Utf8Text(text)
+
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// dottedName joins the identifiers of a dotted name so whitespace and comments are dropped
+func dottedName(node *treesitter.Node, text []byte) string {
+	if node == nil {
+		return ""
+	}
+
+	if node.Kind() != "dotted_name" {
+		return node.Utf8Text(text)
+	}
+
+	parts := make([]string, 0, node.NamedChildCount())
+	for i := range node.NamedChildCount() {
+		parts = append(parts, node.NamedChild(i).Utf8Text(text))
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func childrenByFieldName(node *treesitter.Node, field string) []treesitter.Node {
+	cursor := node.Walk()
+	defer cursor.Close()
+
+	return node.ChildrenByFieldName(field, cursor)
+}
@@ -0,0 +1,132 @@
+package python
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePyprojectRoots(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    []string
+	}{
+		"should not find roots without configuration": {
+			content: "[project]\nname = \"shop\"\n",
+			want:    []string{},
+		},
+		"should read setuptools find where": {
+			content: "[tool.setuptools.packages.find]\nwhere = [\"src\", \"lib\"]\n",
+			want:    []string{"src", "lib"},
+		},
+		"should ignore setuptools package lists": {
+			content: "[tool.setuptools]\npackages = [\"shop\"]\n",
+			want:    []string{},
+		},
+		"should read setuptools package dir": {
+			content: "[tool.setuptools]\npackage-dir = {\"\" = \"python\"}\n",
+			want:    []string{"python"},
+		},
+		"should read poetry packages": {
+			content: "[tool.poetry]\npackages = [{ include = \"shop\", from = \"src\" }, { include = \"cli\" }]\n",
+			want:    []string{"src"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parsePyprojectRoots([]byte(tt.content))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExtractProjectsMalformedPyproject(t *testing.T) {
+	dir := fstest.MapFS{
+		"broken/pyproject.toml":      {Data: []byte("[tool.setuptools\n")},
+		"broken/src/app/__init__.py": {Data: []byte("")},
+		"ok/pyproject.toml":          {Data: []byte("[project]\nname = \"ok\"\n")},
+	}
+
+	got, err := extractProjects(
+		context.Background(),
+		dir,
+		[]string{"broken/pyproject.toml", "ok/pyproject.toml"},
+	)
+	require.NoError(t, err)
+
+	// the broken project falls back to the src layout convention
+	require.Equal(t, []project{
+		{dir: "broken", roots: []directory{"broken", "broken/src"}},
+		{dir: "ok", roots: []directory{"ok"}},
+	}, got)
+}
+
+func TestParseSetupCfgRoots(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    []string
+	}{
+		"should not find roots without configuration": {
+			content: "[metadata]\nname = shop\n",
+			want:    []string{},
+		},
+		"should read an inline package dir": {
+			content: "[options]\npackage_dir = =src\n",
+			want:    []string{"src"},
+		},
+		"should read a multiline package dir": {
+			content: "[options]\npackage_dir =\n    =src\n    cli = tools/cli\npackages = find:\n",
+			want:    []string{"src"},
+		},
+		"should read find where": {
+			content: "[options.packages.find]\nwhere = lib\n",
+			want:    []string{"lib"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, parseSetupCfgRoots([]byte(tt.content)))
+		})
+	}
+}
+
+func TestModuleName(t *testing.T) {
+	tests := map[string]struct {
+		path string
+		root directory
+		want string
+	}{
+		"should name a module": {
+			path: "src/app/models/user.py",
+			root: "src",
+			want: "app.models.user",
+		},
+		"should name a package by its directory": {
+			path: "src/app/__init__.py",
+			root: "src",
+			want: "app",
+		},
+		"should name a module of the root directory": {
+			path: "app/main.py",
+			root: ".",
+			want: "app.main",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, moduleName(tt.path, tt.root))
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package python
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io/fs"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/files"
+	"github.com/pelletier/go-toml/v2"
+)
+
+type directory string
+
+// project is a directory containing a pyproject.toml or setup.cfg
+// and the source roots module names of its files are relative to
+type project struct {
+	dir   directory
+	roots []directory
+}
+
+func listProjectFiles(ctx context.Context, dir fs.FS) ([]string, error) {
+	return files.ListFiles(
+		ctx,
+		dir,
+		files.SkipHiddenDirs(),
+		files.SkipHiddenFiles(),
+		skipEnvironmentDirs(),
+		projectFileFilter(),
+	)
+}
+
+func projectFileFilter() files.FileFilter {
+	return func(p string, d fs.DirEntry) bool {
+		if d.IsDir() {
+			return false
+		}
+
+		base := path.Base(p)
+		return base != "pyproject.toml" && base != "setup.cfg"
+	}
+}
+
+// extractProjects finds the source roots of every project
+// the src directory is a source root by convention unless configured otherwise
+// the project directory itself is always a source root e.g. for tests next to a src layout
+// a pyproject.toml that cannot be parsed is logged and its project gets the default roots
+func extractProjects(ctx context.Context, dir fs.FS, projectFiles []string) ([]project, error) {
+	configured := make(map[directory][]string)
+
+	for _, projectFile := range projectFiles {
+		content, err := fs.ReadFile(dir, projectFile)
+		if err != nil {
+			return nil, err
+		}
+
+		projectDir := directory(path.Dir(projectFile))
+
+		var roots []string
+		switch path.Base(projectFile) {
+		case "pyproject.toml":
+			roots, err = parsePyprojectRoots(content)
+			if err != nil {
+				// a broken project file should not prevent analyzing the rest of the directory
+				slog.WarnContext(
+					ctx,
+					"failed to parse project file, using the default source roots",
+					"path",
+					projectFile,
+					"error",
+					err,
+				)
+			}
+		case "setup.cfg":
+			roots = parseSetupCfgRoots(content)
+		}
+
+		configured[projectDir] = append(configured[projectDir], roots...)
+	}
+
+	projects := make([]project, 0, len(configured))
+
+	for projectDir, roots := range configured {
+		p := project{dir: projectDir, roots: []directory{projectDir}}
+
+		if len(roots) == 0 && isSrcLayout(dir, projectDir) {
+			roots = []string{"src"}
+		}
+
+		for _, root := range roots {
+			r := directory(path.Join(string(projectDir), root))
+			if !slices.Contains(p.roots, r) {
+				p.roots = append(p.roots, r)
+			}
+		}
+
+		projects = append(projects, p)
+	}
+
+	slices.SortFunc(projects, func(a, b project) int {
+		return strings.Compare(string(a.dir), string(b.dir))
+	})
+
+	return projects, nil
+}
+
+// parsePyprojectRoots reads the package directories configured for setuptools or poetry
+// e.g. [tool.setuptools.packages.find] where = ["src"]
+// e.g. [tool.setuptools] package-dir = {"" = "src"}
+// e.g. [tool.poetry] packages = [{ include = "app", from = "src" }]
+func parsePyprojectRoots(content []byte) ([]string, error) {
+	var pyproject struct {
+		Tool struct {
+			Setuptools struct {
+				PackageDir map[string]string `toml:"package-dir"`
+				Packages   any               `toml:"packages"`
+			} `toml:"setuptools"`
+			Poetry struct {
+				Packages []struct {
+					From string `toml:"from"`
+				} `toml:"packages"`
+			} `toml:"poetry"`
+		} `toml:"tool"`
+	}
+
+	if err := toml.Unmarshal(content, &pyproject); err != nil {
+		return nil, err
+	}
+
+	roots := []string{}
+
+	// packages is either a list of package names or a table with a find section
+	if packages, ok := pyproject.Tool.Setuptools.Packages.(map[string]any); ok {
+		if find, ok := packages["find"].(map[string]any); ok {
+			if where, ok := find["where"].([]any); ok {
+				for _, w := range where {
+					if s, ok := w.(string); ok {
+						roots = append(roots, s)
+					}
+				}
+			}
+		}
+	}
+
+	if root, ok := pyproject.Tool.Setuptools.PackageDir[""]; ok {
+		roots = append(roots, root)
+	}
+
+	for _, p := range pyproject.Tool.Poetry.Packages {
+		if p.From != "" {
+			roots = append(roots, p.From)
+		}
+	}
+
+	return roots, nil
+}
+
+// parseSetupCfgRoots reads the root package directory of setuptools
+// e.g. package_dir = =src or package_dir =\n    =src in the [options] section
+// e.g. where = src in the [options.packages.find] section
+func parseSetupCfgRoots(content []byte) []string {
+	roots := []string{}
+
+	var section, key string
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section, key = strings.Trim(line, "[]"), ""
+			continue
+		}
+
+		// indented lines continue the value of the previous key
+		value := line
+		if raw[0] != ' ' && raw[0] != '\t' {
+			var ok bool
+			key, value, ok = strings.Cut(line, "=")
+			if !ok {
+				key = ""
+				continue
+			}
+
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		}
+
+		switch {
+		case section == "options" && key == "package_dir":
+			// an empty package name maps the root package e.g. "=src"
+			if name, dir, ok := strings.Cut(value, "="); ok && strings.TrimSpace(name) == "" {
+				roots = append(roots, strings.TrimSpace(dir))
+			}
+		case section == "options.packages.find" && key == "where" && value != "":
+			roots = append(roots, value)
+		}
+	}
+
+	return roots
+}
+
+// isSrcLayout reports whether the project keeps its packages in a src directory
+// a src directory that is itself a package is not a layout but a package named src
+func isSrcLayout(dir fs.FS, projectDir directory) bool {
+	src := path.Join(string(projectDir), "src")
+
+	info, err := fs.Stat(dir, src)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+
+	_, err = fs.Stat(dir, path.Join(src, "__init__.py"))
+
+	return err != nil
+}
+
+// sourceRoot returns the directory the module name of a file is relative to
+// the longest project source root containing the file wins, without one the
+// directory above the outermost __init__.py is the root, as python would put it on sys.path
+func sourceRoot(dir fs.FS, pyFilepath string, projects []project) (directory, directory) {
+	var root, projectDir directory
+	found := false
+
+	for _, p := range projects {
+		for _, r := range p.roots {
+			if !within(pyFilepath, r) {
+				continue
+			}
+
+			if !found || len(r) > len(root) {
+				root, projectDir, found = r, p.dir, true
+			}
+		}
+	}
+
+	if found {
+		return root, projectDir
+	}
+
+	d := path.Dir(pyFilepath)
+	for d != "." {
+		if _, err := fs.Stat(dir, path.Join(d, "__init__.py")); err != nil {
+			break
+		}
+
+		d = path.Dir(d)
+	}
+
+	return directory(d), "."
+}
+
+func within(p string, d directory) bool {
+	return d == "." || strings.HasPrefix(p, string(d)+"/")
+}
+
+// moduleName returns the dotted module name of a file relative to its source root
+// e.g. "src/app/models/user.py" in root "src" is "app.models.user"
+// e.g. "src/app/__init__.py" in root "src" is "app"
+func moduleName(pyFilepath string, root directory) string {
+	rel := pyFilepath
+	if root != "." {
+		rel = strings.TrimPrefix(pyFilepath, string(root)+"/")
+	}
+
+	rel = strings.TrimSuffix(rel, ".py")
+	rel = strings.TrimSuffix(rel, "/__init__")
+
+	return strings.ReplaceAll(rel, "/", ".")
+}
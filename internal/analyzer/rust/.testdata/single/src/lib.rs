@@ -0,0 +1,15 @@
+pub mod config {
+    pub struct Config {
+        pub verbose: bool,
+    }
+
+    pub mod defaults {
+        pub fn verbose() -> bool {
+            false
+        }
+    }
+}
+
+pub fn run(cfg: &config::Config) -> bool {
+    cfg.verbose || config::defaults::verbose()
+}
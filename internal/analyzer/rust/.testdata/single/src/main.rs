@@ -0,0 +1,9 @@
+extern crate clap as args;
+
+use cli_tool::config::Config;
+
+fn main() {
+    let _ = args::Command::new("cli-tool");
+    let cfg = Config { verbose: false };
+    cli_tool::run(&cfg);
+}
@@ -0,0 +1,4 @@
+pub mod model;
+pub mod store;
+
+pub use store::Store;
@@ -0,0 +1,15 @@
+use serde::Serialize;
+
+#[derive(Clone, Serialize)]
+pub struct User {
+    pub id: u64,
+}
+
+pub enum Role {
+    Admin,
+    Member,
+}
+
+pub trait Named {
+    fn name(&self) -> String;
+}
@@ -0,0 +1,18 @@
+use super::Store;
+use crate::model::{Role, User};
+use std::collections::HashMap;
+
+#[derive(Default)]
+pub struct MemoryStore {
+    users: HashMap<u64, User>,
+}
+
+impl Store for MemoryStore {
+    fn get(&self, id: u64) -> Option<User> {
+        self.users.get(&id).cloned()
+    }
+
+    fn roles(&self) -> HashMap<u64, Role> {
+        HashMap::new()
+    }
+}
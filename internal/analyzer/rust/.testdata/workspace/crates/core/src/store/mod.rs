@@ -0,0 +1,11 @@
+pub mod memory;
+
+use crate::model::{Role, User};
+use std::collections::HashMap;
+
+pub use memory::MemoryStore;
+
+pub trait Store {
+    fn get(&self, id: u64) -> Option<User>;
+    fn roles(&self) -> HashMap<u64, Role>;
+}
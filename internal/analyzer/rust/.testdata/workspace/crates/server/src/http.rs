@@ -0,0 +1,18 @@
+#[path = "routes_v2.rs"]
+mod routes;
+
+use acme_core::model;
+
+pub fn handler() -> model::User {
+    routes::index()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn handles() {
+        assert_eq!(handler().id, 1);
+    }
+}
@@ -0,0 +1,10 @@
+mod http;
+
+use acme_core::Store;
+
+#[tokio::main]
+async fn main() {
+    let store = acme_core::store::MemoryStore::default();
+    let _ = store.get(1);
+    http::handler();
+}
@@ -0,0 +1,5 @@
+use acme_core::model::{self, User};
+
+pub fn index() -> User {
+    model::User { id: 1 }
+}
@@ -0,0 +1,248 @@
+package rust
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/flamingoosesoftwareinc/uda/internal/files"
+	"github.com/pelletier/go-toml/v2"
+)
+
+type directory string
+
+// cargoPackage is a package of a Cargo.toml and the crates it builds
+type cargoPackage struct {
+	dir  directory
+	name string
+	// names the dependencies are referred to by in code e.g. "serde_json" for serde-json
+	dependencies map[string]struct{}
+	crates       []*crate
+}
+
+// crate is a library or binary crate of a package
+type crate struct {
+	// name of the root module e.g. "server" or "server::main" for the binary of a package with a library of the same name
+	node string
+	// name the crate is referred to by in code, empty for binaries
+	name string
+	root string
+	pkg  *cargoPackage
+	// root module, set once the module tree of the crate is built
+	module *module
+}
+
+type cargoManifest struct {
+	Package *struct {
+		Name string `toml:"name"`
+	} `toml:"package"`
+	Lib *struct {
+		Name string `toml:"name"`
+		Path string `toml:"path"`
+	} `toml:"lib"`
+	Bin []struct {
+		Name string `toml:"name"`
+		Path string `toml:"path"`
+	} `toml:"bin"`
+	Workspace *struct {
+		Members []string `toml:"members"`
+		Exclude []string `toml:"exclude"`
+	} `toml:"workspace"`
+	Dependencies      map[string]any `toml:"dependencies"`
+	DevDependencies   map[string]any `toml:"dev-dependencies"`
+	BuildDependencies map[string]any `toml:"build-dependencies"`
+}
+
+// workspace is the directory of a workspace manifest and its member globs
+type workspace struct {
+	dir              directory
+	members, exclude []string
+}
+
+func listCargoFiles(ctx context.Context, dir fs.FS) ([]string, error) {
+	return files.ListFiles(
+		ctx,
+		dir,
+		files.SkipHiddenDirs(),
+		files.SkipHiddenFiles(),
+		skipTargetDirs(),
+		cargoFileFilter(),
+	)
+}
+
+func cargoFileFilter() files.FileFilter {
+	return func(p string, d fs.DirEntry) bool {
+		if d.IsDir() {
+			return false
+		}
+		return path.Base(p) != "Cargo.toml"
+	}
+}
+
+// skipTargetDirs skips the build output of cargo which contains generated sources and vendored crates
+func skipTargetDirs() files.FileFilter {
+	return func(p string, d fs.DirEntry) bool {
+		return d.IsDir() && path.Base(p) == "target"
+	}
+}
+
+// extractPackages reads every Cargo.toml into its package and crates
+// a workspace manifest restricts the analysis to its members, like a go.work does for go modules
+func extractPackages(
+	ctx context.Context,
+	dir fs.FS,
+	cargoFilepaths []string,
+) ([]*cargoPackage, error) {
+	packages := []*cargoPackage{}
+	workspaces := []workspace{}
+
+	for _, cargoFilepath := range cargoFilepaths {
+		content, err := fs.ReadFile(dir, cargoFilepath)
+		if err != nil {
+			return nil, err
+		}
+
+		var manifest cargoManifest
+		if err := toml.Unmarshal(content, &manifest); err != nil {
+			return nil, err
+		}
+
+		d := directory(path.Dir(cargoFilepath))
+
+		if manifest.Workspace != nil {
+			workspaces = append(workspaces, workspace{
+				dir:     d,
+				members: manifest.Workspace.Members,
+				exclude: manifest.Workspace.Exclude,
+			})
+		}
+
+		if manifest.Package == nil {
+			// a virtual workspace manifest
+			continue
+		}
+
+		packages = append(packages, newPackage(dir, d, manifest))
+	}
+
+	if len(workspaces) > 0 {
+		packages = slices.DeleteFunc(packages, func(p *cargoPackage) bool {
+			return !slices.ContainsFunc(workspaces, func(w workspace) bool {
+				return w.contains(p.dir)
+			})
+		})
+	}
+
+	slog.DebugContext(ctx, "identified cargo packages", "count", len(packages))
+
+	return packages, nil
+}
+
+// newPackage finds the crates of a package the way cargo does
+// src/lib.rs is the library, src/main.rs and src/bin/*.rs are binaries unless the manifest says otherwise
+func newPackage(dir fs.FS, d directory, manifest cargoManifest) *cargoPackage {
+	p := &cargoPackage{
+		dir:          d,
+		name:         manifest.Package.Name,
+		dependencies: make(map[string]struct{}),
+	}
+
+	for _, deps := range []map[string]any{
+		manifest.Dependencies,
+		manifest.DevDependencies,
+		manifest.BuildDependencies,
+	} {
+		for name := range maps.Keys(deps) {
+			p.dependencies[crateName(name)] = struct{}{}
+		}
+	}
+
+	libName := crateName(p.name)
+	libPath := "src/lib.rs"
+
+	if manifest.Lib != nil {
+		if manifest.Lib.Name != "" {
+			libName = crateName(manifest.Lib.Name)
+		}
+
+		if manifest.Lib.Path != "" {
+			libPath = manifest.Lib.Path
+		}
+	}
+
+	hasLib := false
+	if root := path.Join(string(d), libPath); exists(dir, root) {
+		hasLib = true
+		p.crates = append(p.crates, &crate{node: libName, name: libName, root: root, pkg: p})
+	}
+
+	bins := map[string]string{}
+
+	if root := path.Join(string(d), "src/main.rs"); exists(dir, root) {
+		bins[crateName(p.name)] = root
+	}
+
+	if entries, err := fs.ReadDir(dir, path.Join(string(d), "src/bin")); err == nil {
+		for _, e := range entries {
+			switch {
+			case !e.IsDir() && path.Ext(e.Name()) == ".rs":
+				bins[crateName(strings.TrimSuffix(e.Name(), ".rs"))] = path.Join(
+					string(d),
+					"src/bin",
+					e.Name(),
+				)
+			case e.IsDir() && exists(dir, path.Join(string(d), "src/bin", e.Name(), "main.rs")):
+				bins[crateName(e.Name())] = path.Join(string(d), "src/bin", e.Name(), "main.rs")
+			}
+		}
+	}
+
+	for _, bin := range manifest.Bin {
+		if bin.Path != "" {
+			bins[crateName(bin.Name)] = path.Join(string(d), bin.Path)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(bins)) {
+		node := name
+		if hasLib && node == libName {
+			node += "::main"
+		}
+
+		p.crates = append(p.crates, &crate{node: node, root: bins[name], pkg: p})
+	}
+
+	return p
+}
+
+// contains reports whether a package directory is a member of the workspace
+// the package of the workspace manifest itself is always a member
+func (w workspace) contains(d directory) bool {
+	if d == w.dir {
+		return true
+	}
+
+	match := func(globs []string) bool {
+		return slices.ContainsFunc(globs, func(glob string) bool {
+			ok, err := doublestar.Match(path.Join(string(w.dir), glob), string(d))
+			return err == nil && ok
+		})
+	}
+
+	return match(w.members) && !match(w.exclude)
+}
+
+// crateName returns the name a crate is referred to by in code e.g. "serde_json" for "serde-json"
+func crateName(name string) string {
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+func exists(dir fs.FS, p string) bool {
+	info, err := fs.Stat(dir, p)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,238 @@
+package rust
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/ts"
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// module is a module of a crate and the package node of the analysis e.g. "server::http::routes"
+type module struct {
+	node     analyzer.Package
+	crate    *crate
+	parent   *module
+	children map[string]*module
+}
+
+func newModule(c *crate, parent *module, name string) *module {
+	node := analyzer.Package(c.node)
+	if parent != nil {
+		node = parent.node + "::" + analyzer.Package(name)
+	}
+
+	return &module{
+		node:     node,
+		crate:    c,
+		parent:   parent,
+		children: make(map[string]*module),
+	}
+}
+
+// child returns the submodule of the given name, declaring it when it is not known yet
+func (m *module) child(name string) *module {
+	if c, ok := m.children[name]; ok {
+		return c
+	}
+
+	c := newModule(m.crate, m, name)
+	m.children[name] = c
+
+	return c
+}
+
+// rsSource is a parsed file and the module it is the root of
+type rsSource struct {
+	path   string
+	module *module
+	// directory the files of `mod x;` declarations are looked up in
+	// the directory of the file for crate roots and mod.rs, a directory named after the module otherwise
+	modDir string
+	// modules declared inline in the file e.g. `mod tests { ... }`
+	inline []*module
+	tree   *treesitter.Tree
+	text   []byte
+}
+
+// enclosing returns the module a node belongs to and the names of the inline modules between it and the file
+// e.g. a node within `mod tests { ... }` of "server::http" belongs to "server::http::tests"
+func (s *rsSource) enclosing(node *treesitter.Node) (*module, []string) {
+	var inline []string
+	for p := node.Parent(); p != nil; p = p.Parent() {
+		if p.Kind() == "mod_item" {
+			inline = append(inline, p.ChildByFieldName("name").Utf8Text(s.text))
+		}
+	}
+
+	slices.Reverse(inline)
+
+	m := s.module
+	for _, name := range inline {
+		m = m.child(name)
+	}
+
+	return m, inline
+}
+
+// buildModuleTrees parses every file reachable from the crate roots by following their mod declarations
+// files which are not declared as a module are not part of a crate and are never analyzed
+func buildModuleTrees(
+	ctx context.Context,
+	dir fs.FS,
+	language *treesitter.Language,
+	crates []*crate,
+) ([]*rsSource, error) {
+	query, err := ts.LoadQuery(ts.RUST, ts.ModulesQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	// the module trees of crates are independent of each other
+	crateSources, err := ts.Map(
+		ctx,
+		crates,
+		func() (*ts.Worker, error) { return ts.NewWorker(language) },
+		func(ctx context.Context, w *ts.Worker, c *crate) ([]*rsSource, error) {
+			c.module = newModule(c, nil, "")
+			sources := []*rsSource{}
+
+			queue := []*rsSource{{path: c.root, module: c.module, modDir: path.Dir(c.root)}}
+			seen := map[string]struct{}{c.root: {}}
+
+			for len(queue) > 0 {
+				src := queue[0]
+				queue = queue[1:]
+
+				tree, text, err := w.Parse(ctx, dir, src.path)
+				if err != nil {
+					return nil, err
+				}
+
+				src.tree, src.text = tree, text
+
+				_, matches, err := w.Query(ctx, tree, text, query)
+				if err != nil {
+					return nil, err
+				}
+
+				for match := matches.Next(); match != nil; match = matches.Next() {
+					for _, capture := range match.Captures {
+						node := capture.Node
+						name := node.ChildByFieldName("name").Utf8Text(text)
+
+						parent, inline := src.enclosing(&node)
+						m := parent.child(name)
+
+						if node.ChildByFieldName("body") != nil {
+							// an inline module lives in the same file
+							src.inline = append(src.inline, m)
+							continue
+						}
+
+						file, ok := modFile(dir, src, &node, inline, name)
+						if !ok {
+							slog.Debug("module file not found", "module", m.node, "declaredIn", src.path)
+							continue
+						}
+
+						if _, ok := seen[file]; ok {
+							continue
+						}
+						seen[file] = struct{}{}
+
+						queue = append(queue, &rsSource{path: file, module: m, modDir: modDir(file)})
+					}
+				}
+
+				sources = append(sources, src)
+			}
+
+			return sources, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Concat(crateSources...), nil
+}
+
+// closeSources closes the trees of sources
+func closeSources(sources []*rsSource) {
+	for _, src := range sources {
+		if src.tree != nil {
+			src.tree.Close()
+		}
+	}
+}
+
+// modFile returns the file of a `mod name;` declaration
+// e.g. `mod routes;` of src/http.rs is src/http/routes.rs or src/http/routes/mod.rs
+// unless a #[path = "..."] attribute says otherwise
+func modFile(
+	dir fs.FS,
+	src *rsSource,
+	node *treesitter.Node,
+	inline []string,
+	name string,
+) (string, bool) {
+	childDir := path.Join(append([]string{src.modDir}, inline...)...)
+
+	if p, ok := pathAttribute(node, src.text); ok {
+		// the path of a module declared directly in a file is relative to the directory of the file
+		base := path.Dir(src.path)
+		if len(inline) > 0 {
+			base = childDir
+		}
+
+		file := path.Join(base, p)
+
+		return file, exists(dir, file)
+	}
+
+	for _, file := range []string{
+		path.Join(childDir, name+".rs"),
+		path.Join(childDir, name, "mod.rs"),
+	} {
+		if exists(dir, file) {
+			return file, true
+		}
+	}
+
+	return "", false
+}
+
+// pathAttribute returns the value of the #[path = "..."] attribute of a module declaration
+func pathAttribute(node *treesitter.Node, text []byte) (string, bool) {
+	for s := node.PrevNamedSibling(); s != nil && s.Kind() == "attribute_item"; s = s.PrevNamedSibling() {
+		attr := s.NamedChild(0)
+		if attr == nil || attr.Kind() != "attribute" {
+			continue
+		}
+
+		value := attr.ChildByFieldName("value")
+		if value == nil || attr.NamedChild(0).Utf8Text(text) != "path" {
+			continue
+		}
+
+		return strings.Trim(value.Utf8Text(text), `"`), true
+	}
+
+	return "", false
+}
+
+// modDir returns the directory the submodules of a file are looked up in
+// e.g. src/http for src/http.rs and src/http/mod.rs
+func modDir(file string) string {
+	if path.Base(file) == "mod.rs" {
+		return path.Dir(file)
+	}
+
+	return strings.TrimSuffix(file, ".rs")
+}
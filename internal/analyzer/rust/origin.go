@@ -0,0 +1,10 @@
+package rust
+
+// stdCrates are the crates shipped with the rust toolchain which need no dependency e.g. "std" or "core"
+var stdCrates = map[string]struct{}{
+	"alloc":      {},
+	"core":       {},
+	"proc_macro": {},
+	"std":        {},
+	"test":       {},
+}
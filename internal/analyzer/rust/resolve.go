@@ -0,0 +1,165 @@
+package rust
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+)
+
+// target is what a path resolved to, either a module of an analyzed crate or an external crate
+type target struct {
+	pkg    analyzer.Package
+	origin analyzer.Origin
+	// module of an analyzed crate, nil for external crates
+	module *module
+	// symbol the path refers to e.g. "server::db::Pool", empty for a whole module
+	symbol string
+	// segments of a path into an external crate e.g. ["serde", "de", "Error"]
+	path []string
+}
+
+type resolver struct {
+	// root modules of the analyzed library crates by the name they are referred to in code
+	libs map[string]*module
+}
+
+func newResolver(crates []*crate) resolver {
+	r := resolver{libs: make(map[string]*module)}
+
+	for _, c := range crates {
+		if c.name != "" {
+			r.libs[c.name] = c.module
+		}
+	}
+
+	return r
+}
+
+// resolve returns what a path used in module m refers to
+// the first segment is looked up the way rust 2018 does: crate, self, super,
+// a name bound by a use declaration, a submodule and finally an extern crate
+// e.g. "crate::db::Pool" is the Pool symbol of the "server::db" module and "serde::Deserialize" is the serde crate
+// unknown first segments are local names e.g. String::from unless the path is part of a use declaration
+func (r resolver) resolve(
+	m *module,
+	bindings map[string]target,
+	segments []string,
+	inUse bool,
+) (target, bool) {
+	if len(segments) == 0 {
+		return target{}, false
+	}
+
+	first, rest := segments[0], segments[1:]
+
+	var cur *module
+
+	switch first {
+	case "crate":
+		cur = m.crate.module
+	case "self":
+		cur = m
+	case "super":
+		cur = m
+		for len(segments) > 0 && segments[0] == "super" {
+			if cur.parent == nil {
+				return target{}, false
+			}
+
+			cur, segments = cur.parent, segments[1:]
+		}
+
+		rest = segments
+	default:
+		if b, ok := bindings[first]; ok {
+			return r.extend(m, b, rest), true
+		}
+
+		if c, ok := m.children[first]; ok {
+			cur = c
+			break
+		}
+
+		if lib, ok := r.libs[first]; ok {
+			cur = lib
+			break
+		}
+
+		if _, ok := stdCrates[first]; ok {
+			return external(analyzer.Std, segments), true
+		}
+
+		if _, ok := m.crate.pkg.dependencies[first]; ok || inUse {
+			return external(analyzer.ThirdParty, segments), true
+		}
+
+		return target{}, false
+	}
+
+	return descend(m, cur, rest), true
+}
+
+// extend resolves the rest of a path starting with a name bound by a use declaration
+// e.g. Pool::new with `use crate::db::Pool;` is still the Pool symbol
+func (r resolver) extend(m *module, b target, rest []string) target {
+	switch {
+	case b.module == nil:
+		return external(b.origin, append(slices.Clone(b.path), rest...))
+	case b.symbol == "":
+		return descend(m, b.module, rest)
+	}
+
+	return b
+}
+
+// descend follows the segments through the submodules of cur, the first segment which is not a module is the symbol
+func descend(from, cur *module, segments []string) target {
+	for _, s := range segments {
+		c, ok := cur.children[s]
+		if !ok {
+			return internal(from, cur, s)
+		}
+
+		cur = c
+	}
+
+	return internal(from, cur, "")
+}
+
+func internal(from, to *module, symbol string) target {
+	origin := analyzer.Workspace
+	if from.crate.pkg == to.crate.pkg {
+		origin = analyzer.FirstParty
+	}
+
+	t := target{pkg: to.node, origin: origin, module: to}
+	if symbol != "" {
+		t.symbol = string(to.node) + "::" + symbol
+	}
+
+	return t
+}
+
+// external returns the crate of a path outside of the analyzed crates
+// its modules are unknown so the symbol ends at the first type-like segment
+// e.g. "std::collections::HashMap" of std::collections::HashMap::new and "std::mem::swap" of std::mem::swap
+func external(origin analyzer.Origin, segments []string) target {
+	t := target{pkg: analyzer.Package(segments[0]), origin: origin, path: segments}
+
+	if len(segments) == 1 {
+		return t
+	}
+
+	end := slices.IndexFunc(segments[1:], func(s string) bool {
+		return s != "" && unicode.IsUpper(rune(s[0]))
+	})
+	if end < 0 {
+		end = len(segments) - 2
+	}
+
+	t.symbol = strings.Join(segments[:end+2], "::")
+
+	return t
+}
@@ -0,0 +1,214 @@
+package rust_test
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer/rust"
+	"github.com/stretchr/testify/require"
+)
+
+type packageImports struct {
+	Pkg     analyzer.Package
+	Imports []analyzer.Import
+}
+
+func toSortedSlice(pi analyzer.PackageImports) []packageImports {
+	result := make([]packageImports, 0, len(pi))
+	for pkg, imports := range pi {
+		sorted := make([]analyzer.Import, len(imports))
+		copy(sorted, imports)
+		slices.Sort(sorted)
+		result = append(result, packageImports{Pkg: pkg, Imports: sorted})
+	}
+	return result
+}
+
+func TestRustAnalyze(t *testing.T) {
+	tests := map[string]struct {
+		dir  string
+		want analyzer.PackageImports
+	}{
+		"workspace": {
+			dir: ".testdata/workspace",
+			want: analyzer.PackageImports{
+				"acme_core": []analyzer.Import{
+					`"acme_core::store"`,
+				},
+				"acme_core::model": []analyzer.Import{
+					`"serde"`,
+				},
+				"acme_core::store": []analyzer.Import{
+					`"acme_core::model"`,
+					`"acme_core::store::memory"`,
+					`"std"`,
+				},
+				"acme_core::store::memory": []analyzer.Import{
+					`"acme_core::model"`,
+					`"acme_core::store"`,
+					`"std"`,
+				},
+				"server": []analyzer.Import{
+					`"acme_core"`,
+					`"acme_core::store"`,
+					`"server::http"`,
+					`"tokio"`,
+				},
+				"server::http": []analyzer.Import{
+					`"acme_core::model"`,
+					`"server::http::routes"`,
+				},
+				"server::http::routes": []analyzer.Import{
+					`"acme_core::model"`,
+				},
+				"server::http::tests": []analyzer.Import{
+					`"server::http"`,
+				},
+			},
+		},
+		"single": {
+			dir: ".testdata/single",
+			want: analyzer.PackageImports{
+				"cli_tool": []analyzer.Import{
+					`"cli_tool::config"`,
+					`"cli_tool::config::defaults"`,
+				},
+				"cli_tool::config":           []analyzer.Import{},
+				"cli_tool::config::defaults": []analyzer.Import{},
+				"cli_tool::main": []analyzer.Import{
+					`"clap"`,
+					`"cli_tool"`,
+					`"cli_tool::config"`,
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := os.DirFS(tt.dir)
+			got, err := rust.RustAnalyzer().Analyze(context.Background(), dir)
+			require.NoError(t, err)
+			require.ElementsMatch(t, toSortedSlice(tt.want), toSortedSlice(got))
+		})
+	}
+}
+
+func TestRustAnalyzeSources(t *testing.T) {
+	dir := os.DirFS(".testdata/single")
+
+	got, err := rust.RustAnalyzer().AnalyzeSources(context.Background(), dir)
+	require.NoError(t, err)
+
+	require.Equal(t, analyzer.ImportSources{
+		"cli_tool": {
+			`"cli_tool::config"`:           {{File: "src/lib.rs", Line: 13}},
+			`"cli_tool::config::defaults"`: {{File: "src/lib.rs", Line: 14}},
+		},
+		"cli_tool::config":           {},
+		"cli_tool::config::defaults": {},
+		"cli_tool::main": {
+			`"clap"`:             {{File: "src/main.rs", Line: 1}},
+			`"cli_tool::config"`: {{File: "src/main.rs", Line: 3}},
+			`"cli_tool"`:         {{File: "src/main.rs", Line: 8}},
+		},
+	}, got)
+}
+
+func TestRustAnalyzeOrigins(t *testing.T) {
+	dir := os.DirFS(".testdata/workspace")
+
+	got, err := rust.RustAnalyzer().AnalyzeOrigins(context.Background(), dir)
+	require.NoError(t, err)
+
+	require.Equal(t, analyzer.ImportOrigins{
+		"acme_core": {`"acme_core::store"`: analyzer.FirstParty},
+		"acme_core::model": {
+			`"serde"`: analyzer.ThirdParty,
+		},
+		"acme_core::store": {
+			`"acme_core::model"`:         analyzer.FirstParty,
+			`"acme_core::store::memory"`: analyzer.FirstParty,
+			`"std"`:                      analyzer.Std,
+		},
+		"acme_core::store::memory": {
+			`"acme_core::model"`: analyzer.FirstParty,
+			`"acme_core::store"`: analyzer.FirstParty,
+			`"std"`:              analyzer.Std,
+		},
+		"server": {
+			`"acme_core"`:        analyzer.Workspace,
+			`"acme_core::store"`: analyzer.Workspace,
+			`"server::http"`:     analyzer.FirstParty,
+			`"tokio"`:            analyzer.ThirdParty,
+		},
+		"server::http": {
+			`"acme_core::model"`:     analyzer.Workspace,
+			`"server::http::routes"`: analyzer.FirstParty,
+		},
+		"server::http::routes": {`"acme_core::model"`: analyzer.Workspace},
+		"server::http::tests":  {`"server::http"`: analyzer.FirstParty},
+	}, got)
+}
+
+func TestRustAnalyzeWithOrigins(t *testing.T) {
+	dir := os.DirFS(".testdata/single")
+
+	a := rust.RustAnalyzer(rust.WithOrigins(analyzer.Std, analyzer.ThirdParty))
+
+	got, err := a.Analyze(context.Background(), dir)
+	require.NoError(t, err)
+	require.Equal(t, analyzer.PackageImports{
+		"cli_tool":                   {},
+		"cli_tool::config":           {},
+		"cli_tool::config::defaults": {},
+		"cli_tool::main":             {`"clap"`},
+	}, got)
+}
+
+func TestRustAnalyzeV2(t *testing.T) {
+	dir := os.DirFS(".testdata/workspace")
+
+	metrics, err := rust.RustAnalyzer().AnalyzeV2(context.Background(), dir)
+	require.NoError(t, err)
+
+	type result struct {
+		Pkg                       analyzer.Package
+		Ca, Ce                    float64
+		AbstractTypes, TotalTypes uint
+	}
+
+	got := make([]result, 0, len(metrics))
+	for _, m := range metrics {
+		got = append(got, result{
+			Pkg:           m.Package,
+			Ca:            m.InwardCoupling(),
+			Ce:            m.OutwardCoupling(),
+			AbstractTypes: m.AbstractTypes,
+			TotalTypes:    m.TotalTypes,
+		})
+	}
+
+	require.Equal(t, []result{
+		// store::Store
+		{Pkg: "acme_core", Ca: 1, Ce: 1},
+		// serde::Serialize
+		{Pkg: "acme_core::model", Ca: 6, Ce: 1, AbstractTypes: 1, TotalTypes: 3},
+		// model::Role, model::User, memory::MemoryStore, HashMap
+		{Pkg: "acme_core::store", Ca: 3, Ce: 4, AbstractTypes: 1, TotalTypes: 1},
+		// store::Store, model::Role, model::User, HashMap
+		{Pkg: "acme_core::store::memory", Ca: 1, Ce: 4, TotalTypes: 1},
+		// acme_core::Store, store::MemoryStore, http::handler, tokio::main
+		{Pkg: "server", Ca: 0, Ce: 4},
+		// model::User, routes::index
+		{Pkg: "server::http", Ca: 1, Ce: 2},
+		// model::User
+		{Pkg: "server::http::routes", Ca: 1, Ce: 1},
+		{Pkg: "server::http::tests", Ca: 0, Ce: 0},
+	}, got)
+}
@@ -0,0 +1,681 @@
+package rust
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"slices"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/ts"
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+	tsrust "github.com/tree-sitter/tree-sitter-rust/bindings/go"
+)
+
+type rustAnalyzer struct {
+	origins map[analyzer.Origin]struct{}
+}
+
+// Option configures the rust analyzer
+type Option func(*rustAnalyzer)
+
+// WithOrigins restricts the analysis to imports of the given origins
+// e.g. WithOrigins(analyzer.FirstParty, analyzer.Workspace) ignores coupling to std and crates.io
+func WithOrigins(origins ...analyzer.Origin) Option {
+	return func(r *rustAnalyzer) {
+		r.origins = make(map[analyzer.Origin]struct{}, len(origins))
+		for _, o := range origins {
+			r.origins[o] = struct{}{}
+		}
+	}
+}
+
+var (
+	_ analyzer.Analyzer       = &rustAnalyzer{}
+	_ analyzer.SourceAnalyzer = &rustAnalyzer{}
+	_ analyzer.OriginAnalyzer = &rustAnalyzer{}
+	_ analyzer.AllAnalyzer    = &rustAnalyzer{}
+)
+
+// RustAnalyzer analyzes the coupling between the modules of the crates of cargo packages and workspaces
+// a package is a module e.g. "server::http" and external crates are a single package e.g. "serde"
+func RustAnalyzer(opts ...Option) *rustAnalyzer {
+	r := &rustAnalyzer{}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// AnalyzeAll derives every analysis from a single extraction of the .rs files of dir
+func (r *rustAnalyzer) AnalyzeAll(ctx context.Context, dir fs.FS) (analyzer.Result, error) {
+	rsFiles, err := r.analyze(ctx, dir)
+	if err != nil {
+		return analyzer.Result{}, err
+	}
+
+	return analyzer.Result{
+		Imports: packageImports(rsFiles),
+		Metrics: buildMetrics(rsFiles),
+		Sources: importSources(rsFiles),
+		Origins: importOrigins(rsFiles),
+	}, nil
+}
+
+func (r *rustAnalyzer) AnalyzeV2(
+	ctx context.Context,
+	dir fs.FS,
+) ([]analyzer.Metrics, error) {
+	rsFiles, err := r.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildMetrics(rsFiles), nil
+}
+
+func buildMetrics(rsFiles []rsFile) []analyzer.Metrics {
+	outward := make(map[analyzer.Package]analyzer.PackageCouplingStats)
+	types := make(map[analyzer.Package]rsFile)
+
+	for _, f := range rsFiles {
+		t := types[f.pkg]
+		t.types += f.types
+		t.abstractTypes += f.abstractTypes
+		types[f.pkg] = t
+
+		stats, ok := outward[f.pkg]
+		if !ok {
+			stats = make(analyzer.PackageCouplingStats)
+			outward[f.pkg] = stats
+		}
+
+		for _, u := range f.uses {
+			stats.Add(u.pkg, u.symbol)
+		}
+	}
+
+	metrics := analyzer.BuildMetrics(outward)
+	for i := range metrics {
+		metrics[i].TotalTypes = types[metrics[i].Package].types
+		metrics[i].AbstractTypes = types[metrics[i].Package].abstractTypes
+	}
+
+	return metrics
+}
+
+func (r *rustAnalyzer) Analyze(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.PackageImports, error) {
+	rsFiles, err := r.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return packageImports(rsFiles), nil
+}
+
+func packageImports(rsFiles []rsFile) analyzer.PackageImports {
+	pi := make(analyzer.PackageImports)
+	seen := make(map[analyzer.Package]map[analyzer.Import]struct{})
+
+	// a module with submodules declared inline is spread over several entries so imports are merged
+	for _, f := range rsFiles {
+		pkgSeen, ok := seen[f.pkg]
+		if !ok {
+			pkgSeen = make(map[analyzer.Import]struct{}, len(f.imports))
+			seen[f.pkg] = pkgSeen
+			pi[f.pkg] = make([]analyzer.Import, 0, len(f.imports))
+		}
+
+		for _, i := range f.imports {
+			if _, ok := pkgSeen[i]; ok {
+				continue
+			}
+
+			pkgSeen[i] = struct{}{}
+			pi[f.pkg] = append(pi[f.pkg], i)
+		}
+	}
+
+	return pi
+}
+
+func (r *rustAnalyzer) AnalyzeSources(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.ImportSources, error) {
+	rsFiles, err := r.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return importSources(rsFiles), nil
+}
+
+func importSources(rsFiles []rsFile) analyzer.ImportSources {
+	sources := make(analyzer.ImportSources)
+
+	for _, f := range rsFiles {
+		pkgSources, ok := sources[f.pkg]
+		if !ok {
+			pkgSources = make(map[analyzer.Import][]analyzer.Location, len(f.imports))
+			sources[f.pkg] = pkgSources
+		}
+
+		for _, i := range f.imports {
+			loc := analyzer.Location{File: f.path, Line: f.importLines[i]}
+			if slices.Contains(pkgSources[i], loc) {
+				continue
+			}
+
+			pkgSources[i] = append(pkgSources[i], loc)
+		}
+	}
+
+	return sources
+}
+
+func (r *rustAnalyzer) AnalyzeOrigins(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.ImportOrigins, error) {
+	rsFiles, err := r.analyze(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return importOrigins(rsFiles), nil
+}
+
+func importOrigins(rsFiles []rsFile) analyzer.ImportOrigins {
+	origins := make(analyzer.ImportOrigins)
+
+	for _, f := range rsFiles {
+		pkgOrigins, ok := origins[f.pkg]
+		if !ok {
+			pkgOrigins = make(map[analyzer.Import]analyzer.Origin, len(f.imports))
+			origins[f.pkg] = pkgOrigins
+		}
+
+		for _, i := range f.imports {
+			pkgOrigins[i] = f.origins[i]
+		}
+	}
+
+	return origins
+}
+
+func (r *rustAnalyzer) analyze(ctx context.Context, dir fs.FS) ([]rsFile, error) {
+	// Cargo.toml declares the packages, their crates and dependencies and the members of a workspace
+	cargoFilepaths, err := listCargoFiles(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.DebugContext(ctx, "found cargo files", "filepaths", cargoFilepaths)
+
+	packages, err := extractPackages(ctx, dir, cargoFilepaths)
+	if err != nil {
+		return nil, err
+	}
+
+	rsFiles, err := analyzeCrates(ctx, dir, packages)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.origins != nil {
+		for i := range rsFiles {
+			f := &rsFiles[i]
+
+			allowed := func(imp analyzer.Import) bool {
+				_, ok := r.origins[f.origins[imp]]
+				return ok
+			}
+
+			f.imports = slices.DeleteFunc(f.imports, func(imp analyzer.Import) bool {
+				return !allowed(imp)
+			})
+			f.uses = slices.DeleteFunc(f.uses, func(u use) bool {
+				return !allowed(quote(u.pkg))
+			})
+		}
+	}
+
+	return rsFiles, nil
+}
+
+func quote(pkg analyzer.Package) analyzer.Import {
+	return analyzer.Import(`"` + pkg + `"`)
+}
+
+// rsFile is what was extracted for a single module of a file
+// a file declaring inline modules e.g. `mod tests { ... }` has one per module
+type rsFile struct {
+	path        string
+	pkg         analyzer.Package
+	imports     []analyzer.Import
+	importLines map[analyzer.Import]uint
+	origins     map[analyzer.Import]analyzer.Origin
+	// symbols of other modules and crates used in the module
+	uses []use
+	// number of structs, enums, unions and traits and how many of them are traits
+	types, abstractTypes uint
+}
+
+// use is a reference to an item of a module e.g. "server::db::Pool" of "server::db"
+type use struct {
+	pkg    analyzer.Package
+	symbol string
+}
+
+func analyzeCrates(
+	ctx context.Context,
+	dir fs.FS,
+	packages []*cargoPackage,
+) ([]rsFile, error) {
+	language := treesitter.NewLanguage(tsrust.Language())
+
+	crates := []*crate{}
+	for _, p := range packages {
+		crates = append(crates, p.crates...)
+	}
+
+	// every module has to be known before a path can be resolved
+	// so rust files are always parsed and never read from the parse cache
+	sources, err := buildModuleTrees(ctx, dir, language, crates)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSources(sources)
+
+	query, err := ts.LoadQuery(ts.RUST, ts.AnalyzeQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	res := newResolver(crates)
+
+	sourceFiles, err := ts.Map(
+		ctx,
+		sources,
+		func() (*ts.Worker, error) { return ts.NewWorker(language) },
+		func(ctx context.Context, w *ts.Worker, src *rsSource) ([]rsFile, error) {
+			return analyzeSource(ctx, w, src, query, res)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Concat(sourceFiles...), nil
+}
+
+// analyzeSource extracts the imports and uses of the modules of a single parsed file
+func analyzeSource(
+	ctx context.Context,
+	w *ts.Worker,
+	src *rsSource,
+	query string,
+	res resolver,
+) ([]rsFile, error) {
+	q, matches, err := w.Query(ctx, src.tree, src.text, query)
+	if err != nil {
+		return nil, err
+	}
+
+	captureNames := q.CaptureNames()
+
+	e := extractor{
+		source:   src,
+		resolver: res,
+		files:    make(map[*module]*rsFile),
+		bindings: make(map[*module]map[string]target),
+	}
+	e.file(src.module)
+	for _, m := range src.inline {
+		e.file(m)
+	}
+
+	for match := matches.Next(); match != nil; match = matches.Next() {
+		for _, capture := range match.Captures {
+			e.capture(captureNames[capture.Index], &capture.Node)
+		}
+	}
+
+	// paths are resolved once every use declaration is known as a use may follow the code using it
+	for _, p := range e.paths {
+		t, ok := e.resolver.resolve(p.module, e.bindings[p.module], p.segments, false)
+		if !ok {
+			// a local name e.g. an associated function of a type of the module
+			continue
+		}
+
+		e.addImport(p.module, p.line, t)
+		e.addUse(p.module, t)
+	}
+
+	rsFiles := make([]rsFile, 0, len(e.modules))
+
+	for _, m := range e.modules {
+		f := e.files[m]
+		f.uses = slices.DeleteFunc(f.uses, func(u use) bool {
+			return u.pkg == f.pkg
+		})
+
+		slog.DebugContext(
+			ctx,
+			"processed file",
+			"path",
+			src.path,
+			"pkgDetected",
+			f.pkg,
+			"imports",
+			f.imports,
+		)
+
+		rsFiles = append(rsFiles, *f)
+	}
+
+	return rsFiles, nil
+}
+
+// extractor collects the imports, bindings and uses of the modules of a single file
+type extractor struct {
+	source   *rsSource
+	resolver resolver
+	files    map[*module]*rsFile
+	// modules in the order they were first seen
+	modules  []*module
+	bindings map[*module]map[string]target
+	paths    []pathUse
+}
+
+// pathUse is a path outside of a use declaration e.g. db::Pool::new or serde_json::to_string
+type pathUse struct {
+	module   *module
+	segments []string
+	line     uint
+}
+
+// useItem is a single path of a use declaration e.g. crate::db::Pool of `use crate::db::{Pool, Conn as C};`
+type useItem struct {
+	segments []string
+	alias    string
+	wildcard bool
+}
+
+func (e *extractor) file(m *module) *rsFile {
+	if f, ok := e.files[m]; ok {
+		return f
+	}
+
+	f := &rsFile{
+		path:        e.source.path,
+		pkg:         m.node,
+		imports:     make([]analyzer.Import, 0, 32),
+		importLines: make(map[analyzer.Import]uint),
+		origins:     make(map[analyzer.Import]analyzer.Origin),
+		uses:        make([]use, 0, 32),
+	}
+	e.files[m] = f
+	e.modules = append(e.modules, m)
+	e.bindings[m] = make(map[string]target)
+
+	return f
+}
+
+func (e *extractor) capture(captureName string, node *treesitter.Node) {
+	text := e.source.text
+
+	switch captureName {
+	case "use":
+		slog.Debug("use detected", "use", node.Utf8Text(text))
+		e.useDeclaration(node)
+	case "extern_crate":
+		slog.Debug("extern_crate detected", "extern", node.Utf8Text(text))
+		e.externCrate(node)
+	case "path_use":
+		if !outermost(node) {
+			return
+		}
+
+		segments := pathSegments(node, text)
+		if len(segments) < 2 {
+			return
+		}
+
+		m, _ := e.source.enclosing(node)
+		e.file(m)
+		e.paths = append(e.paths, pathUse{
+			module:   m,
+			segments: segments,
+			line:     node.StartPosition().Row + 1,
+		})
+	case "type_declaration":
+		slog.Debug("type_declaration detected", "declaration", node.Utf8Text(text))
+		m, _ := e.source.enclosing(node)
+		e.file(m).types++
+	case "abstract_type_declaration":
+		slog.Debug("abstract_type_declaration detected", "declaration", node.Utf8Text(text))
+		m, _ := e.source.enclosing(node)
+		f := e.file(m)
+		f.types++
+		f.abstractTypes++
+	default:
+		slog.Debug(
+			"unknown capture name",
+			"captureName",
+			captureName,
+			"value",
+			node.Utf8Text(text),
+		)
+	}
+}
+
+// useDeclaration records the imports of a use declaration and the names it binds
+// e.g. `use crate::db;` imports and binds the db module,
+// `use crate::db::{Pool, Conn as C};` imports db and uses and binds Pool and C
+// and `use crate::db::*;` only imports db as the names it binds are unknown
+func (e *extractor) useDeclaration(node *treesitter.Node) {
+	m, _ := e.source.enclosing(node)
+	e.file(m)
+
+	line := node.StartPosition().Row + 1
+
+	for _, item := range flattenUse(node.ChildByFieldName("argument"), nil, e.source.text) {
+		t, ok := e.resolver.resolve(m, e.bindings[m], item.segments, true)
+		if !ok {
+			continue
+		}
+
+		e.addImport(m, line, t)
+
+		if item.wildcard {
+			continue
+		}
+
+		e.addUse(m, t)
+
+		name := item.alias
+		if name == "" {
+			name = item.segments[len(item.segments)-1]
+		}
+
+		if name != "_" {
+			e.bindings[m][name] = t
+		}
+	}
+}
+
+// externCrate records `extern crate foo;` and `extern crate foo as bar;` which bind the crate to a name
+func (e *extractor) externCrate(node *treesitter.Node) {
+	m, _ := e.source.enclosing(node)
+	e.file(m)
+
+	name := node.ChildByFieldName("name").Utf8Text(e.source.text)
+
+	t, ok := e.resolver.resolve(m, e.bindings[m], []string{name}, true)
+	if !ok {
+		return
+	}
+
+	e.addImport(m, node.StartPosition().Row+1, t)
+
+	if alias := node.ChildByFieldName("alias"); alias != nil {
+		name = alias.Utf8Text(e.source.text)
+	}
+
+	if name != "_" {
+		e.bindings[m][name] = t
+	}
+}
+
+// addImport records the package node a path of module m resolved to, a module never imports itself
+func (e *extractor) addImport(m *module, line uint, t target) {
+	if t.pkg == m.node {
+		return
+	}
+
+	f := e.file(m)
+
+	imp := quote(t.pkg)
+	if _, ok := f.importLines[imp]; ok {
+		return
+	}
+
+	f.imports = append(f.imports, imp)
+	f.importLines[imp] = line
+	f.origins[imp] = t.origin
+}
+
+func (e *extractor) addUse(m *module, t target) {
+	if t.symbol == "" {
+		return
+	}
+
+	f := e.file(m)
+	f.uses = append(f.uses, use{pkg: t.pkg, symbol: t.symbol})
+}
+
+// outermost reports whether a path is neither part of a longer path nor of a use declaration
+// e.g. only crate::db::Pool of crate::db::Pool::new is outermost
+func outermost(node *treesitter.Node) bool {
+	switch node.Parent().Kind() {
+	case "scoped_identifier", "scoped_type_identifier", "scoped_use_list":
+		return false
+	}
+
+	for p := node.Parent(); p != nil; p = p.Parent() {
+		if p.Kind() == "use_declaration" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// flattenUse returns every path of a use tree with the prefix of its enclosing lists
+// e.g. `crate::db::{self, Pool as P, models::*}` is crate::db, crate::db::Pool as P and crate::db::models::*
+func flattenUse(node *treesitter.Node, prefix []string, text []byte) []useItem {
+	if node == nil {
+		return nil
+	}
+
+	switch node.Kind() {
+	case "use_as_clause":
+		segments := pathSegments(node.ChildByFieldName("path"), text)
+		if segments == nil {
+			return nil
+		}
+
+		return []useItem{{
+			segments: concat(prefix, segments),
+			alias:    node.ChildByFieldName("alias").Utf8Text(text),
+		}}
+	case "scoped_use_list":
+		p := prefix
+		if path := node.ChildByFieldName("path"); path != nil {
+			segments := pathSegments(path, text)
+			if segments == nil {
+				return nil
+			}
+
+			p = concat(prefix, segments)
+		}
+
+		return flattenUse(node.ChildByFieldName("list"), p, text)
+	case "use_list":
+		items := []useItem{}
+		for i := range node.NamedChildCount() {
+			items = append(items, flattenUse(node.NamedChild(i), prefix, text)...)
+		}
+
+		return items
+	case "use_wildcard":
+		segments := prefix
+		if path := node.NamedChild(0); path != nil {
+			segments = concat(prefix, pathSegments(path, text))
+		}
+
+		if len(segments) == 0 {
+			return nil
+		}
+
+		return []useItem{{segments: segments, wildcard: true}}
+	case "self":
+		// the module of the enclosing list e.g. `use crate::db::{self};`
+		if len(prefix) > 0 {
+			return []useItem{{segments: prefix}}
+		}
+	}
+
+	segments := pathSegments(node, text)
+	if segments == nil {
+		return nil
+	}
+
+	return []useItem{{segments: concat(prefix, segments)}}
+}
+
+// pathSegments returns the segments of a path e.g. ["crate", "db", "Pool"] for crate::db::Pool
+// generic arguments are dropped and paths starting with a type e.g. <T as Trait>::f are not supported
+func pathSegments(node *treesitter.Node, text []byte) []string {
+	if node == nil {
+		return nil
+	}
+
+	switch node.Kind() {
+	case "identifier", "type_identifier", "crate", "self", "super":
+		return []string{node.Utf8Text(text)}
+	case "scoped_identifier", "scoped_type_identifier":
+		name := node.ChildByFieldName("name")
+		if name == nil {
+			return nil
+		}
+
+		p := node.ChildByFieldName("path")
+		if p == nil {
+			// a path starting with :: e.g. ::serde::Serialize
+			return []string{name.Utf8Text(text)}
+		}
+
+		segments := pathSegments(p, text)
+		if segments == nil {
+			return nil
+		}
+
+		return append(segments, name.Utf8Text(text))
+	case "generic_type":
+		return pathSegments(node.ChildByFieldName("type"), text)
+	}
+
+	return nil
+}
+
+func concat(prefix, segments []string) []string {
+	return append(slices.Clone(prefix), segments...)
+}
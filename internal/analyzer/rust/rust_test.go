@@ -0,0 +1,116 @@
+package rust
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternal(t *testing.T) {
+	tests := map[string]struct {
+		segments []string
+		want     string
+	}{
+		"should not have a symbol for the crate itself": {
+			segments: []string{"serde"},
+			want:     "",
+		},
+		"should end at the first type": {
+			segments: []string{"std", "collections", "HashMap", "new"},
+			want:     "std::collections::HashMap",
+		},
+		"should keep a path without types": {
+			segments: []string{"std", "mem", "swap"},
+			want:     "std::mem::swap",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := external(analyzer.Std, tt.segments)
+			require.Equal(t, analyzer.Package(tt.segments[0]), got.pkg)
+			require.Equal(t, tt.want, got.symbol)
+		})
+	}
+}
+
+func TestModDir(t *testing.T) {
+	tests := map[string]struct {
+		file string
+		want string
+	}{
+		"should use the directory of mod.rs": {
+			file: "src/http/mod.rs",
+			want: "src/http",
+		},
+		"should use a directory named after the module": {
+			file: "src/http.rs",
+			want: "src/http",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, modDir(tt.file))
+		})
+	}
+}
+
+func TestExtractPackages(t *testing.T) {
+	dir := fstest.MapFS{
+		"Cargo.toml": {Data: []byte(
+			"[workspace]\nmembers = [\"crates/*\", \"tools/gen\"]\nexclude = [\"crates/old\"]\n",
+		)},
+		"crates/api/Cargo.toml": {Data: []byte(
+			"[package]\nname = \"api\"\n\n[lib]\nname = \"api_v2\"\n\n[dependencies]\nserde-json = \"1\"\n",
+		)},
+		"crates/api/src/lib.rs":       {},
+		"crates/api/src/main.rs":      {},
+		"crates/api/src/bin/seed.rs":  {},
+		"crates/old/Cargo.toml":       {Data: []byte("[package]\nname = \"old\"\n")},
+		"crates/old/src/lib.rs":       {},
+		"tools/gen/Cargo.toml":        {Data: []byte("[package]\nname = \"gen\"\n")},
+		"tools/gen/src/main.rs":       {},
+		"tools/gen/src/bin/x/main.rs": {},
+		"vendor/dep/Cargo.toml":       {Data: []byte("[package]\nname = \"dep\"\n")},
+	}
+
+	cargoFilepaths, err := listCargoFiles(context.Background(), dir)
+	require.NoError(t, err)
+
+	packages, err := extractPackages(context.Background(), dir, cargoFilepaths)
+	require.NoError(t, err)
+
+	type result struct {
+		Node, Name, Root string
+	}
+
+	got := map[string][]result{}
+	for _, p := range packages {
+		got[p.name] = []result{}
+		for _, c := range p.crates {
+			got[p.name] = append(got[p.name], result{Node: c.node, Name: c.name, Root: c.root})
+		}
+	}
+
+	require.Equal(t, map[string][]result{
+		"api": {
+			{Node: "api_v2", Name: "api_v2", Root: "crates/api/src/lib.rs"},
+			{Node: "api", Root: "crates/api/src/main.rs"},
+			{Node: "seed", Root: "crates/api/src/bin/seed.rs"},
+		},
+		"gen": {
+			{Node: "gen", Root: "tools/gen/src/main.rs"},
+			{Node: "x", Root: "tools/gen/src/bin/x/main.rs"},
+		},
+	}, got)
+
+	require.Contains(t, packages[0].dependencies, "serde_json")
+}
@@ -0,0 +1,132 @@
+package check
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/graph"
+)
+
+// BaselineVersion is the version of the baseline file schema
+const BaselineVersion = 1
+
+// ErrBaselineMismatch is returned when a check does not analyze packages the way its baseline was recorded
+var ErrBaselineMismatch = errors.New("baseline recorded with a different analysis")
+
+// Baseline records the metrics of every package at a point in time
+// violations of packages that did not get worse since the baseline are not reported
+// which allows adopting thresholds on a codebase that already violates them
+type Baseline struct {
+	Version int `json:"version"`
+	// whether only coupling between packages of the analyzed modules was recorded
+	FirstParty bool                                 `json:"firstParty"`
+	Packages   map[analyzer.Package]BaselineMetrics `json:"packages"`
+}
+
+type BaselineMetrics struct {
+	EfferentCoupling float64 `json:"efferentCoupling"`
+	Instability      float64 `json:"instability"`
+	Distance         float64 `json:"distance"`
+	InCycle          bool    `json:"inCycle"`
+}
+
+// NewBaseline records the current metrics of every package
+// firstParty records whether metrics only count coupling between packages of the analyzed modules
+func NewBaseline(metrics []analyzer.Metrics, g graph.Graph, firstParty bool) Baseline {
+	b := Baseline{
+		Version:    BaselineVersion,
+		FirstParty: firstParty,
+		Packages:   make(map[analyzer.Package]BaselineMetrics, len(metrics)),
+	}
+
+	for _, m := range metrics {
+		b.Packages[m.Package] = BaselineMetrics{
+			EfferentCoupling: m.OutwardCoupling(),
+			Instability:      m.Instability(),
+			Distance:         m.Distance(),
+		}
+	}
+
+	for _, component := range g.StronglyConnectedComponents() {
+		for _, pkg := range component {
+			bm := b.Packages[pkg]
+			bm.InCycle = true
+			b.Packages[pkg] = bm
+		}
+	}
+
+	return b
+}
+
+// ReadBaseline decodes a baseline written by Baseline.Write
+func ReadBaseline(r io.Reader) (Baseline, error) {
+	var b Baseline
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return Baseline{}, err
+	}
+
+	if b.Version != BaselineVersion {
+		return Baseline{}, fmt.Errorf("unsupported baseline version %d", b.Version)
+	}
+
+	return b, nil
+}
+
+// Write encodes the baseline as indented JSON with sorted packages
+func (b Baseline) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(b)
+}
+
+// Matches returns ErrBaselineMismatch when metrics analyzed with firstParty cannot be compared to the baseline
+// e.g. a first party check against a baseline that counted coupling to third party packages
+func (b Baseline) Matches(firstParty bool) error {
+	if b.FirstParty != firstParty {
+		return fmt.Errorf(
+			"%w: first-party is %t in the baseline and %t in the check, update the baseline with the same flags",
+			ErrBaselineMismatch,
+			b.FirstParty,
+			firstParty,
+		)
+	}
+
+	return nil
+}
+
+// Regressions returns the violations of packages that are new or got worse since the baseline
+func (b Baseline) Regressions(violations []Violation) []Violation {
+	regressions := []Violation{}
+
+	for _, v := range violations {
+		if b.regressed(v) {
+			regressions = append(regressions, v)
+		}
+	}
+
+	return regressions
+}
+
+func (b Baseline) regressed(v Violation) bool {
+	baseline, ok := b.Packages[v.Package]
+	if !ok {
+		return true
+	}
+
+	switch v.Rule {
+	case MaxInstability:
+		return v.Value > baseline.Instability
+	case MaxEfferentCoupling:
+		return v.Value > baseline.EfferentCoupling
+	case MaxDistance:
+		return v.Value > baseline.Distance
+	case ForbidCycles:
+		return !baseline.InCycle
+	}
+
+	return true
+}
@@ -0,0 +1,73 @@
+package check_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/check"
+	"github.com/flamingoosesoftwareinc/uda/internal/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaselineRoundTrip(t *testing.T) {
+	metrics := analyzer.BuildMetrics(map[analyzer.Package]analyzer.PackageCouplingStats{
+		"a": {"b": {"b.Do": {Count: 1}}},
+		"b": {"a": {"a.Do": {Count: 1}}},
+	})
+	g := graph.New(analyzer.PackageImports{"a": {`"b"`}, "b": {`"a"`}})
+
+	var buf bytes.Buffer
+	require.NoError(t, check.NewBaseline(metrics, g, true).Write(&buf))
+
+	got, err := check.ReadBaseline(&buf)
+	require.NoError(t, err)
+	require.Equal(t, check.Baseline{
+		Version:    check.BaselineVersion,
+		FirstParty: true,
+		Packages: map[analyzer.Package]check.BaselineMetrics{
+			"a": {EfferentCoupling: 1, Instability: 0.5, Distance: 0.5, InCycle: true},
+			"b": {EfferentCoupling: 1, Instability: 0.5, Distance: 0.5, InCycle: true},
+		},
+	}, got)
+}
+
+func TestReadBaselineVersion(t *testing.T) {
+	_, err := check.ReadBaseline(bytes.NewBufferString(`{"version": 99, "packages": {}}`))
+	require.Error(t, err)
+}
+
+func TestBaselineMatches(t *testing.T) {
+	// baselines recorded before the filter was recorded counted every origin
+	b, err := check.ReadBaseline(bytes.NewBufferString(`{"version": 1, "packages": {}}`))
+	require.NoError(t, err)
+
+	require.NoError(t, b.Matches(false))
+	require.ErrorIs(t, b.Matches(true), check.ErrBaselineMismatch)
+}
+
+func TestBaselineRegressions(t *testing.T) {
+	baseline := check.Baseline{
+		Version: check.BaselineVersion,
+		Packages: map[analyzer.Package]check.BaselineMetrics{
+			"legacy":   {EfferentCoupling: 30, Instability: 0.9, Distance: 0.8, InCycle: true},
+			"worsened": {EfferentCoupling: 30, Instability: 0.9, Distance: 0.8},
+		},
+	}
+
+	violations := []check.Violation{
+		{Package: "legacy", Rule: check.MaxEfferentCoupling, Value: 30, Threshold: 20},
+		{Package: "legacy", Rule: check.MaxInstability, Value: 0.85, Threshold: 0.8},
+		{Package: "legacy", Rule: check.ForbidCycles, Cycle: graph.Cycle{"legacy", "worsened"}},
+		{Package: "worsened", Rule: check.MaxEfferentCoupling, Value: 31, Threshold: 20},
+		{Package: "worsened", Rule: check.MaxDistance, Value: 0.8, Threshold: 0.7},
+		{Package: "worsened", Rule: check.ForbidCycles, Cycle: graph.Cycle{"worsened", "legacy"}},
+		{Package: "new", Rule: check.MaxInstability, Value: 0.85, Threshold: 0.8},
+	}
+
+	require.Equal(t, []check.Violation{
+		{Package: "worsened", Rule: check.MaxEfferentCoupling, Value: 31, Threshold: 20},
+		{Package: "worsened", Rule: check.ForbidCycles, Cycle: graph.Cycle{"worsened", "legacy"}},
+		{Package: "new", Rule: check.MaxInstability, Value: 0.85, Threshold: 0.8},
+	}, baseline.Regressions(violations))
+}
@@ -0,0 +1,124 @@
+package check
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/graph"
+)
+
+// Thresholds are the limits a package must stay within, a zero value disables the threshold
+// e.g. in .uda.yaml
+//
+//	check:
+//	  max-instability: 0.8
+//	  max-efferent-coupling: 20
+//	  max-distance: 0.7
+//	  forbid-cycles: true
+type Thresholds struct {
+	MaxInstability      float64
+	MaxEfferentCoupling float64
+	MaxDistance         float64
+	ForbidCycles        bool
+}
+
+type Rule string
+
+const (
+	MaxInstability      Rule = "max-instability"
+	MaxEfferentCoupling Rule = "max-efferent-coupling"
+	MaxDistance         Rule = "max-distance"
+	ForbidCycles        Rule = "forbid-cycles"
+)
+
+// Violation is a package breaking one of the thresholds
+type Violation struct {
+	Package   analyzer.Package
+	Rule      Rule
+	Value     float64
+	Threshold float64
+	// the cycle the package is part of when the rule is ForbidCycles
+	Cycle graph.Cycle
+}
+
+func (v Violation) String() string {
+	switch v.Rule {
+	case MaxInstability:
+		return fmt.Sprintf("%v: instability %.2f exceeds %.2f", v.Package, v.Value, v.Threshold)
+	case MaxEfferentCoupling:
+		return fmt.Sprintf("%v: efferent coupling %v exceeds %v", v.Package, v.Value, v.Threshold)
+	case MaxDistance:
+		return fmt.Sprintf(
+			"%v: distance from the main sequence %.2f exceeds %.2f",
+			v.Package,
+			v.Value,
+			v.Threshold,
+		)
+	case ForbidCycles:
+		return fmt.Sprintf("%v: part of import cycle %v", v.Package, v.Cycle)
+	}
+
+	return fmt.Sprintf("%v: %v", v.Package, v.Rule)
+}
+
+// Run returns every violation of the thresholds sorted by package and rule
+func Run(t Thresholds, metrics []analyzer.Metrics, g graph.Graph) []Violation {
+	violations := []Violation{}
+
+	for _, m := range metrics {
+		if t.MaxInstability > 0 && m.Instability() > t.MaxInstability {
+			violations = append(violations, Violation{
+				Package:   m.Package,
+				Rule:      MaxInstability,
+				Value:     m.Instability(),
+				Threshold: t.MaxInstability,
+			})
+		}
+
+		if t.MaxEfferentCoupling > 0 && m.OutwardCoupling() > t.MaxEfferentCoupling {
+			violations = append(violations, Violation{
+				Package:   m.Package,
+				Rule:      MaxEfferentCoupling,
+				Value:     m.OutwardCoupling(),
+				Threshold: t.MaxEfferentCoupling,
+			})
+		}
+
+		// a package without any coupling has an instability of 0 and so a distance of 1 when it is concrete
+		// but it is in no zone of pain as nothing depends on it
+		uncoupled := m.InwardCoupling()+m.OutwardCoupling() == 0
+		if t.MaxDistance > 0 && !uncoupled && m.Distance() > t.MaxDistance {
+			violations = append(violations, Violation{
+				Package:   m.Package,
+				Rule:      MaxDistance,
+				Value:     m.Distance(),
+				Threshold: t.MaxDistance,
+			})
+		}
+	}
+
+	if t.ForbidCycles {
+		for _, component := range g.StronglyConnectedComponents() {
+			for _, pkg := range component {
+				// a single cycle through the package is enough to explain the violation
+				violations = append(violations, Violation{
+					Package: pkg,
+					Rule:    ForbidCycles,
+					Cycle:   g.ShortestCycle(pkg),
+				})
+			}
+		}
+	}
+
+	slices.SortStableFunc(violations, func(a, b Violation) int {
+		if c := strings.Compare(string(a.Package), string(b.Package)); c != 0 {
+			return c
+		}
+
+		return strings.Compare(string(a.Rule), string(b.Rule))
+	})
+
+	return violations
+}
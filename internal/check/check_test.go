@@ -0,0 +1,87 @@
+package check_test
+
+import (
+	"testing"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/check"
+	"github.com/flamingoosesoftwareinc/uda/internal/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	metrics := analyzer.BuildMetrics(map[analyzer.Package]analyzer.PackageCouplingStats{
+		"a": {
+			"b":   {"b.Do": {Count: 1}},
+			"fmt": {"fmt.Println": {Count: 1}, "fmt.Sprintf": {Count: 1}},
+		},
+		"b": {
+			"a": {"a.Do": {Count: 1}},
+		},
+		"c": {},
+		"d": {},
+		"e": {
+			"d": {"d.Do": {Count: 1}},
+		},
+	})
+	g := graph.New(analyzer.PackageImports{
+		"a": {`"b"`, `"fmt"`},
+		"b": {`"a"`},
+		"c": {},
+		"d": {},
+		"e": {`"d"`},
+	})
+
+	tests := map[string]struct {
+		thresholds check.Thresholds
+		want       []check.Violation
+	}{
+		"should not report anything without thresholds": {
+			thresholds: check.Thresholds{},
+			want:       []check.Violation{},
+		},
+		"should report every violated threshold": {
+			thresholds: check.Thresholds{
+				MaxInstability:      0.7,
+				MaxEfferentCoupling: 2,
+				MaxDistance:         0.9,
+				ForbidCycles:        true,
+			},
+			want: []check.Violation{
+				{Package: "a", Rule: check.ForbidCycles, Cycle: graph.Cycle{"a", "b"}},
+				{Package: "a", Rule: check.MaxEfferentCoupling, Value: 3, Threshold: 2},
+				{Package: "a", Rule: check.MaxInstability, Value: 0.75, Threshold: 0.7},
+				{Package: "b", Rule: check.ForbidCycles, Cycle: graph.Cycle{"b", "a"}},
+				{Package: "d", Rule: check.MaxDistance, Value: 1, Threshold: 0.9},
+				{Package: "e", Rule: check.MaxInstability, Value: 1, Threshold: 0.7},
+			},
+		},
+		"should not report the distance of a package without coupling": {
+			thresholds: check.Thresholds{MaxDistance: 0.5},
+			want: []check.Violation{
+				{Package: "d", Rule: check.MaxDistance, Value: 1, Threshold: 0.5},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, check.Run(tt.thresholds, metrics, g))
+		})
+	}
+}
+
+func TestViolationString(t *testing.T) {
+	require.Equal(
+		t,
+		"a: instability 0.75 exceeds 0.70",
+		check.Violation{Package: "a", Rule: check.MaxInstability, Value: 0.75, Threshold: 0.7}.String(),
+	)
+	require.Equal(
+		t,
+		"a: part of import cycle a -> b -> a",
+		check.Violation{Package: "a", Rule: check.ForbidCycles, Cycle: graph.Cycle{"a", "b"}}.String(),
+	)
+}
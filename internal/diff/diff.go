@@ -0,0 +1,232 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/graph"
+	"github.com/flamingoosesoftwareinc/uda/internal/report"
+)
+
+type Status string
+
+const (
+	Added   Status = "added"
+	Removed Status = "removed"
+	Changed Status = "changed"
+)
+
+// Diff is the change in dependencies and metrics between a base and a head analysis
+type Diff struct {
+	// version of the schema shared with the metrics report, see report.SchemaVersion
+	Version      int            `json:"version"`
+	AddedEdges   []Edge         `json:"addedEdges"`
+	RemovedEdges []Edge         `json:"removedEdges"`
+	Packages     []PackageDelta `json:"packages"`
+}
+
+// Edge is an import of a package by another analyzed package
+type Edge struct {
+	From analyzer.Package `json:"from"`
+	To   analyzer.Package `json:"to"`
+}
+
+// PackageDelta is a package that was added, removed or had any of its metrics change
+// Base is nil for added packages and Head is nil for removed packages
+type PackageDelta struct {
+	Package analyzer.Package `json:"package"`
+	Status  Status           `json:"status"`
+	Base    *Values          `json:"base,omitempty"`
+	Head    *Values          `json:"head,omitempty"`
+}
+
+type Values struct {
+	Ca           float64 `json:"ca"`
+	Ce           float64 `json:"ce"`
+	Instability  float64 `json:"instability"`
+	Abstractness float64 `json:"abstractness"`
+	Distance     float64 `json:"distance"`
+}
+
+// Delta returns head minus base, a missing side counts as zero
+func (d PackageDelta) Delta() Values {
+	var base, head Values
+	if d.Base != nil {
+		base = *d.Base
+	}
+
+	if d.Head != nil {
+		head = *d.Head
+	}
+
+	return Values{
+		Ca:           head.Ca - base.Ca,
+		Ce:           head.Ce - base.Ce,
+		Instability:  head.Instability - base.Instability,
+		Abstractness: head.Abstractness - base.Abstractness,
+		Distance:     head.Distance - base.Distance,
+	}
+}
+
+// Analysis is the result of analyzing a single revision
+type Analysis struct {
+	Imports analyzer.PackageImports
+	Metrics []analyzer.Metrics
+}
+
+// New compares the base and head analysis
+// edges and packages are sorted so the same input always produces the same output
+func New(base, head Analysis) Diff {
+	baseGraph, headGraph := graph.New(base.Imports), graph.New(head.Imports)
+
+	d := Diff{
+		Version:      report.SchemaVersion,
+		AddedEdges:   missingEdges(headGraph, baseGraph),
+		RemovedEdges: missingEdges(baseGraph, headGraph),
+		Packages:     []PackageDelta{},
+	}
+
+	baseValues, headValues := values(base.Metrics), values(head.Metrics)
+
+	packages := make([]analyzer.Package, 0, len(baseValues)+len(headValues))
+	for pkg := range baseValues {
+		packages = append(packages, pkg)
+	}
+
+	for pkg := range headValues {
+		if _, ok := baseValues[pkg]; !ok {
+			packages = append(packages, pkg)
+		}
+	}
+
+	slices.Sort(packages)
+
+	for _, pkg := range packages {
+		b, inBase := baseValues[pkg]
+		h, inHead := headValues[pkg]
+
+		switch {
+		case !inBase:
+			d.Packages = append(d.Packages, PackageDelta{Package: pkg, Status: Added, Head: &h})
+		case !inHead:
+			d.Packages = append(d.Packages, PackageDelta{Package: pkg, Status: Removed, Base: &b})
+		case b != h:
+			d.Packages = append(
+				d.Packages,
+				PackageDelta{Package: pkg, Status: Changed, Base: &b, Head: &h},
+			)
+		}
+	}
+
+	return d
+}
+
+// missingEdges returns the edges of a that are not in b
+func missingEdges(a, b graph.Graph) []Edge {
+	edges := []Edge{}
+
+	for _, pkg := range a.Nodes() {
+		for _, dep := range a[pkg] {
+			if !b.HasEdge(pkg, dep) {
+				edges = append(edges, Edge{From: pkg, To: dep})
+			}
+		}
+	}
+
+	return edges
+}
+
+func values(metrics []analyzer.Metrics) map[analyzer.Package]Values {
+	v := make(map[analyzer.Package]Values, len(metrics))
+
+	for _, m := range metrics {
+		v[m.Package] = Values{
+			Ca:           m.InwardCoupling(),
+			Ce:           m.OutwardCoupling(),
+			Instability:  m.Instability(),
+			Abstractness: m.Abstractness(),
+			Distance:     m.Distance(),
+		}
+	}
+
+	return v
+}
+
+// ParseFormat validates a format given by the user
+// formats registered with report.RegisterFormat encode reports rather than diffs so only text and json are accepted
+func ParseFormat(format string) (report.Format, error) {
+	switch f := report.Format(format); f {
+	case report.Text, report.JSON:
+		return f, nil
+	}
+
+	return "", fmt.Errorf("%w: %q, one of text|json", report.ErrUnsupportedFormat, format)
+}
+
+// Write encodes the diff in the given format
+func (d Diff) Write(w io.Writer, format report.Format) error {
+	switch format {
+	case report.Text:
+		return d.writeText(w)
+	case report.JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(d)
+	}
+
+	return fmt.Errorf("%w: %q", report.ErrUnsupportedFormat, format)
+}
+
+func (d Diff) writeText(w io.Writer) error {
+	var b strings.Builder
+
+	for _, e := range d.AddedEdges {
+		fmt.Fprintf(&b, "+ %v -> %v\n", e.From, e.To)
+	}
+
+	for _, e := range d.RemovedEdges {
+		fmt.Fprintf(&b, "- %v -> %v\n", e.From, e.To)
+	}
+
+	for _, p := range d.Packages {
+		delta := p.Delta()
+
+		switch p.Status {
+		case Added:
+			fmt.Fprintf(&b, "Package: %v added\t%s\n", p.Package, formatValues(*p.Head))
+		case Removed:
+			fmt.Fprintf(&b, "Package: %v removed\t%s\n", p.Package, formatValues(*p.Base))
+		case Changed:
+			fmt.Fprintf(
+				&b,
+				"Package: %v\tCa: %v (%+g)\tCe: %v (%+g)\tI: %.2f (%+.2f)\tA: %.2f (%+.2f)\tD: %.2f (%+.2f)\n",
+				p.Package,
+				p.Head.Ca, delta.Ca,
+				p.Head.Ce, delta.Ce,
+				p.Head.Instability, delta.Instability,
+				p.Head.Abstractness, delta.Abstractness,
+				p.Head.Distance, delta.Distance,
+			)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func formatValues(v Values) string {
+	return fmt.Sprintf(
+		"Ca: %v\tCe: %v\tI: %.2f\tA: %.2f\tD: %.2f",
+		v.Ca,
+		v.Ce,
+		v.Instability,
+		v.Abstractness,
+		v.Distance,
+	)
+}
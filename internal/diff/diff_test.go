@@ -0,0 +1,144 @@
+package diff_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/diff"
+	"github.com/flamingoosesoftwareinc/uda/internal/report"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	base := diff.Analysis{
+		Imports: analyzer.PackageImports{
+			"a": {`"b"`},
+			"b": {},
+			"c": {},
+		},
+		Metrics: analyzer.BuildMetrics(map[analyzer.Package]analyzer.PackageCouplingStats{
+			"a": {"b": {"b.Do": {Count: 1}}},
+			"b": {},
+			"c": {},
+		}),
+	}
+	head := diff.Analysis{
+		Imports: analyzer.PackageImports{
+			"a": {`"d"`},
+			"b": {},
+			"d": {},
+		},
+		Metrics: analyzer.BuildMetrics(map[analyzer.Package]analyzer.PackageCouplingStats{
+			"a": {"d": {"d.Do": {Count: 1}}},
+			"b": {},
+			"d": {},
+		}),
+	}
+
+	tests := map[string]struct {
+		base, head diff.Analysis
+		want       diff.Diff
+	}{
+		"should report nothing for identical analysis": {
+			base: base,
+			head: base,
+			want: diff.Diff{
+				Version:      report.SchemaVersion,
+				AddedEdges:   []diff.Edge{},
+				RemovedEdges: []diff.Edge{},
+				Packages:     []diff.PackageDelta{},
+			},
+		},
+		"should report edges and package changes": {
+			base: base,
+			head: head,
+			want: diff.Diff{
+				Version:      report.SchemaVersion,
+				AddedEdges:   []diff.Edge{{From: "a", To: "d"}},
+				RemovedEdges: []diff.Edge{{From: "a", To: "b"}},
+				Packages: []diff.PackageDelta{
+					{
+						Package: "b",
+						Status:  diff.Changed,
+						Base:    &diff.Values{Ca: 1, Distance: 1},
+						Head:    &diff.Values{Distance: 1},
+					},
+					{
+						Package: "c",
+						Status:  diff.Removed,
+						Base:    &diff.Values{Distance: 1},
+					},
+					{
+						Package: "d",
+						Status:  diff.Added,
+						Head:    &diff.Values{Ca: 1, Distance: 1},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, diff.New(tt.base, tt.head))
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	d := diff.Diff{
+		AddedEdges:   []diff.Edge{{From: "a", To: "d"}},
+		RemovedEdges: []diff.Edge{{From: "a", To: "b"}},
+		Packages: []diff.PackageDelta{
+			{
+				Package: "b",
+				Status:  diff.Changed,
+				Base:    &diff.Values{Ca: 1},
+				Head:    &diff.Values{Distance: 1},
+			},
+			{Package: "d", Status: diff.Added, Head: &diff.Values{Ca: 1}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, d.Write(&buf, report.Text))
+	require.Equal(
+		t,
+		"+ a -> d\n"+
+			"- a -> b\n"+
+			"Package: b\tCa: 0 (-1)\tCe: 0 (+0)\tI: 0.00 (+0.00)\tA: 0.00 (+0.00)\tD: 1.00 (+1.00)\n"+
+			"Package: d added\tCa: 1\tCe: 0\tI: 0.00\tA: 0.00\tD: 0.00\n",
+		buf.String(),
+	)
+
+	buf.Reset()
+	require.NoError(t, diff.New(diff.Analysis{}, diff.Analysis{}).Write(&buf, report.JSON))
+	require.JSONEq(
+		t,
+		`{"version": 1, "addedEdges": [], "removedEdges": [], "packages": []}`,
+		buf.String(),
+	)
+
+	require.ErrorIs(t, d.Write(&buf, report.Format("xml")), report.ErrUnsupportedFormat)
+}
+
+func TestParseFormat(t *testing.T) {
+	require.NoError(t, report.RegisterFormat("diff-test", func(io.Writer, report.Report) error { return nil }))
+
+	for _, format := range []string{"text", "json"} {
+		got, err := diff.ParseFormat(format)
+		require.NoError(t, err)
+		require.Equal(t, report.Format(format), got)
+	}
+
+	// a format registered for reports cannot encode a diff
+	for _, format := range []string{"xml", "diff-test"} {
+		_, err := diff.ParseFormat(format)
+		require.ErrorIs(t, err, report.ErrUnsupportedFormat)
+		require.ErrorContains(t, err, "one of text|json")
+	}
+}
@@ -0,0 +1,7 @@
+package api
+
+import "fmt"
+
+func Hello() {
+	fmt.Println("hello")
+}
@@ -0,0 +1,3 @@
+import { extname } from "node:path";
+
+export const ext = (file) => extname(file);
@@ -0,0 +1,3 @@
+import { readFileSync } from "node:fs";
+
+export const config = readFileSync("config.json", "utf8");
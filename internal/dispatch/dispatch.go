@@ -0,0 +1,407 @@
+package dispatch
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/detect"
+	"github.com/flamingoosesoftwareinc/uda/internal/files"
+)
+
+type dispatcher struct {
+	registry  *Registry
+	languages []Language
+	origins   []analyzer.Origin
+	include   []string
+	exclude   []string
+}
+
+// Option configures the dispatcher
+type Option func(*dispatcher)
+
+// WithOrigins restricts the analysis of every language to imports of the given origins
+func WithOrigins(origins ...analyzer.Origin) Option {
+	return func(d *dispatcher) {
+		d.origins = origins
+	}
+}
+
+// WithRegistry routes files to the languages of r instead of the default registry
+func WithRegistry(r *Registry) Option {
+	return func(d *dispatcher) {
+		d.registry = r
+	}
+}
+
+// WithLanguages routes files to languages in addition to the languages of the registry
+// a language replaces the language of the registry with the same ID for this dispatcher only
+func WithLanguages(languages ...Language) Option {
+	return func(d *dispatcher) {
+		d.languages = append(d.languages, languages...)
+	}
+}
+
+// WithInclude restricts the analysis to the source files matching any of patterns
+// e.g. WithInclude("services/**") analyzes services and still reads the go.mod of the root directory
+func WithInclude(patterns ...string) Option {
+	return func(d *dispatcher) {
+		d.include = patterns
+	}
+}
+
+// WithExclude hides the files and directories matching any of patterns from every analyzer
+// e.g. WithExclude("**/*.pb.go", "third_party")
+func WithExclude(patterns ...string) Option {
+	return func(d *dispatcher) {
+		d.exclude = patterns
+	}
+}
+
+var (
+	_ analyzer.Analyzer         = &dispatcher{}
+	_ analyzer.SourceAnalyzer   = &dispatcher{}
+	_ analyzer.OriginAnalyzer   = &dispatcher{}
+	_ analyzer.PlatformAnalyzer = &dispatcher{}
+	_ analyzer.TestAnalyzer     = &dispatcher{}
+	_ analyzer.LanguageAnalyzer = &dispatcher{}
+	_ analyzer.AllAnalyzer      = &dispatcher{}
+)
+
+// Dispatcher analyzes a polyglot directory by routing every file to the analyzer of its language
+// the results of every language are merged as if a single analyzer produced them
+func Dispatcher(opts ...Option) *dispatcher {
+	d := &dispatcher{registry: Default()}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	if len(d.languages) > 0 {
+		d.registry = NewRegistry(append(d.registry.Languages(), d.languages...)...)
+	}
+
+	return d
+}
+
+// route is a language found in the analyzed directory and its view of the directory
+type route struct {
+	language Language
+	analyzer analyzer.Analyzer
+	dir      fs.FS
+}
+
+// AnalyzeAll walks dir once, runs the analyzer of every language found once and merges their results
+func (d *dispatcher) AnalyzeAll(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.Result, error) {
+	routes, err := d.route(ctx, dir)
+	if err != nil {
+		return analyzer.Result{}, err
+	}
+
+	r := analyzer.Result{
+		Imports:   make(analyzer.PackageImports),
+		Metrics:   []analyzer.Metrics{},
+		Sources:   make(analyzer.ImportSources),
+		Origins:   make(analyzer.ImportOrigins),
+		Platforms: make(analyzer.ImportPlatforms),
+		Tests:     make(analyzer.TestImports),
+		Languages: make(analyzer.PackageLanguages),
+	}
+
+	for _, rt := range routes {
+		languageResult, err := analyzer.AnalyzeAll(ctx, rt.analyzer, rt.dir)
+		if err != nil {
+			return analyzer.Result{}, err
+		}
+
+		merge(ctx, &r, languageResult, rt.language)
+	}
+
+	slices.SortStableFunc(r.Metrics, func(a, b analyzer.Metrics) int {
+		return strings.Compare(string(a.Package), string(b.Package))
+	})
+
+	return r, nil
+}
+
+// merge adds the result of the analyzer of a language to r
+// a package analyzed by several languages keeps the first language of the registry and the imports of both
+func merge(ctx context.Context, r *analyzer.Result, languageResult analyzer.Result, l Language) {
+	for pkg, imports := range languageResult.Imports {
+		existing, ok := r.Imports[pkg]
+		if !ok {
+			r.Imports[pkg] = imports
+			r.Languages[pkg] = string(l.ID)
+			// an analyzer of several languages tells the language of each package e.g. javascript or typescript
+			if language, ok := languageResult.Languages[pkg]; ok {
+				r.Languages[pkg] = language
+			}
+
+			continue
+		}
+
+		slog.WarnContext(ctx, "package analyzed by several languages", "package", pkg)
+
+		for _, i := range imports {
+			if !slices.Contains(existing, i) {
+				existing = append(existing, i)
+			}
+		}
+
+		r.Imports[pkg] = existing
+	}
+
+	// packages of different languages never import each other so each language is complete on its own
+	r.Metrics = append(r.Metrics, languageResult.Metrics...)
+
+	for pkg, imports := range languageResult.Sources {
+		if _, ok := r.Sources[pkg]; !ok {
+			r.Sources[pkg] = make(map[analyzer.Import][]analyzer.Location, len(imports))
+		}
+
+		for i, locations := range imports {
+			r.Sources[pkg][i] = append(r.Sources[pkg][i], locations...)
+		}
+	}
+
+	for pkg, imports := range languageResult.Origins {
+		if _, ok := r.Origins[pkg]; !ok {
+			r.Origins[pkg] = make(map[analyzer.Import]analyzer.Origin, len(imports))
+		}
+
+		maps.Copy(r.Origins[pkg], imports)
+	}
+
+	for pkg, imports := range languageResult.Platforms {
+		if _, ok := r.Platforms[pkg]; !ok {
+			r.Platforms[pkg] = make(map[analyzer.Import][]string, len(imports))
+		}
+
+		maps.Copy(r.Platforms[pkg], imports)
+	}
+
+	for pkg, imports := range languageResult.Tests {
+		for _, i := range imports {
+			if !slices.Contains(r.Tests[pkg], i) {
+				r.Tests[pkg] = append(r.Tests[pkg], i)
+			}
+		}
+	}
+}
+
+// Analyze walks dir for the imports only, AnalyzeAll runs every analysis in a single walk
+func (d *dispatcher) Analyze(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.PackageImports, error) {
+	r, err := d.AnalyzeAll(ctx, dir)
+	return r.Imports, err
+}
+
+func (d *dispatcher) AnalyzeV2(
+	ctx context.Context,
+	dir fs.FS,
+) ([]analyzer.Metrics, error) {
+	r, err := d.AnalyzeAll(ctx, dir)
+	return r.Metrics, err
+}
+
+func (d *dispatcher) AnalyzeSources(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.ImportSources, error) {
+	r, err := d.AnalyzeAll(ctx, dir)
+	return r.Sources, err
+}
+
+func (d *dispatcher) AnalyzeOrigins(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.ImportOrigins, error) {
+	r, err := d.AnalyzeAll(ctx, dir)
+	return r.Origins, err
+}
+
+func (d *dispatcher) AnalyzePlatforms(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.ImportPlatforms, error) {
+	r, err := d.AnalyzeAll(ctx, dir)
+	return r.Platforms, err
+}
+
+func (d *dispatcher) AnalyzeTests(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.TestImports, error) {
+	r, err := d.AnalyzeAll(ctx, dir)
+	return r.Tests, err
+}
+
+// AnalyzeLanguages returns the language of every package
+// a package analyzed by several languages keeps the first language of the registry
+func (d *dispatcher) AnalyzeLanguages(
+	ctx context.Context,
+	dir fs.FS,
+) (analyzer.PackageLanguages, error) {
+	r, err := d.AnalyzeAll(ctx, dir)
+	return r.Languages, err
+}
+
+// route walks dir once to detect the language of every file
+// a language is only analyzed when at least one of its files was found and its analyzer
+// does not see the files of other languages, files no language claims e.g. go.mod or
+// package.json are seen by every analyzer
+// no analyzer sees the files ignored by .gitignore and .udaignore files, excluded or not included
+// nor the dependencies of vendor and node_modules directories
+func (d *dispatcher) route(ctx context.Context, dir fs.FS) ([]route, error) {
+	languages := d.registry.Languages()
+
+	detected := make(map[string]int)
+	for i, l := range languages {
+		for _, name := range l.Detected {
+			detected[name] = i
+		}
+	}
+
+	ignored := make(map[string]struct{})
+	skipIgnored := files.SkipIgnored(dir)
+	exclude := files.Exclude(d.exclude...)
+	skipVendor := skipVendorDirs()
+
+	filepaths, err := files.ListFiles(
+		ctx,
+		dir,
+		files.SkipHiddenDirs(),
+		files.SkipHiddenFiles(),
+		func(p string, e fs.DirEntry) bool {
+			if skipIgnored(p, e) || exclude(p, e) || skipVendor(p, e) {
+				ignored[p] = struct{}{}
+				return true
+			}
+
+			return false
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make(map[string]int, len(filepaths))
+	found := make([]bool, len(languages))
+
+	for _, p := range filepaths {
+		name, err := detect.Detect(ctx, dir, p)
+		if errors.Is(err, detect.ErrNoLanguageDetected) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		i, ok := detected[name]
+		if !ok {
+			continue
+		}
+
+		if len(d.include) > 0 && !files.MatchAny(d.include, p) {
+			ignored[p] = struct{}{}
+			continue
+		}
+
+		owners[p] = i
+		found[i] = true
+	}
+
+	routes := []route{}
+
+	for i, l := range languages {
+		if !found[i] {
+			continue
+		}
+
+		slog.DebugContext(ctx, "routing files", "language", l.ID)
+
+		routes = append(routes, route{
+			language: l,
+			analyzer: l.New(d.origins...),
+			dir:      view{fsys: dir, owners: owners, owner: i, ignored: ignored},
+		})
+	}
+
+	return routes, nil
+}
+
+// vendorDirs hold copies of dependencies rather than first-party code
+// other generated or third party directories e.g. dist are left to .gitignore and .udaignore files
+var vendorDirs = []string{"vendor", "node_modules"}
+
+// skipVendorDirs skips the directories of dependencies e.g. node_modules
+func skipVendorDirs() files.FileFilter {
+	return func(p string, d fs.DirEntry) bool {
+		return d.IsDir() && slices.Contains(vendorDirs, path.Base(p))
+	}
+}
+
+// view is the directory as seen by the analyzer of a single language
+// the files routed to other languages and the ignored files and directories do not exist
+type view struct {
+	fsys    fs.FS
+	owners  map[string]int
+	owner   int
+	ignored map[string]struct{}
+}
+
+var (
+	_ fs.ReadDirFS = view{}
+	_ fs.StatFS    = view{}
+)
+
+func (v view) hidden(name string) bool {
+	if owner, ok := v.owners[name]; ok && owner != v.owner {
+		return true
+	}
+
+	for p := name; p != "."; p = path.Dir(p) {
+		if _, ok := v.ignored[p]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (v view) Open(name string) (fs.File, error) {
+	if v.hidden(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return v.fsys.Open(name)
+}
+
+func (v view) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(v.fsys, name)
+
+	entries = slices.DeleteFunc(entries, func(e fs.DirEntry) bool {
+		return v.hidden(path.Join(name, e.Name()))
+	})
+
+	return entries, err
+}
+
+func (v view) Stat(name string) (fs.FileInfo, error) {
+	if v.hidden(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return fs.Stat(v.fsys, name)
+}
@@ -0,0 +1,272 @@
+package dispatch_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/dispatch"
+	"github.com/flamingoosesoftwareinc/uda/internal/files"
+	"github.com/flamingoosesoftwareinc/uda/internal/ts"
+	"github.com/stretchr/testify/require"
+)
+
+// filesAnalyzer reports every file it can see as a package, hidden files are skipped as by every analyzer
+type filesAnalyzer struct{}
+
+func (filesAnalyzer) Analyze(ctx context.Context, dir fs.FS) (analyzer.PackageImports, error) {
+	filepaths, err := files.ListFiles(ctx, dir, files.SkipHiddenDirs(), files.SkipHiddenFiles())
+	if err != nil {
+		return nil, err
+	}
+
+	pi := make(analyzer.PackageImports)
+	for _, p := range filepaths {
+		pi[analyzer.Package(p)] = []analyzer.Import{}
+	}
+
+	return pi, nil
+}
+
+func (filesAnalyzer) AnalyzeV2(context.Context, fs.FS) ([]analyzer.Metrics, error) {
+	return nil, nil
+}
+
+func TestDispatcherRoutes(t *testing.T) {
+	dir := fstest.MapFS{
+		"go.mod":         {Data: []byte("module example.com/poly\n")},
+		"api/api.go":     {Data: []byte("package api\n")},
+		"tools/gen.py":   {Data: []byte("print('generated')\n")},
+		"web/index.ts":   {Data: []byte("export const a = 1;\n")},
+		"web/README.txt": {Data: []byte("docs\n")},
+	}
+
+	newFiles := func(...analyzer.Origin) analyzer.Analyzer {
+		return filesAnalyzer{}
+	}
+
+	registry := dispatch.NewRegistry(
+		dispatch.Language{ID: ts.GO, Detected: []string{"Go"}, New: newFiles},
+		dispatch.Language{ID: ts.PYTHON, Detected: []string{"Python"}, New: newFiles},
+		dispatch.Language{ID: ts.RUST, Detected: []string{"Rust"}, New: newFiles},
+	)
+
+	d := dispatch.Dispatcher(dispatch.WithRegistry(registry))
+
+	got, err := d.AnalyzeLanguages(context.Background(), dir)
+	require.NoError(t, err)
+
+	// files no language claims are seen by every language and rust is never analyzed
+	require.Equal(t, analyzer.PackageLanguages{
+		"api/api.go":     "go",
+		"go.mod":         "go",
+		"web/README.txt": "go",
+		"web/index.ts":   "go",
+		"tools/gen.py":   "python",
+	}, got)
+}
+
+func TestDispatcherSkipsVendorDirs(t *testing.T) {
+	dir := fstest.MapFS{
+		"go.mod":                        {Data: []byte("module example.com/poly\n")},
+		"internal/cache/cache.go":       {Data: []byte("package cache\n")},
+		"pkg/external/external.go":      {Data: []byte("package external\n")},
+		"deps/deps.go":                  {Data: []byte("package deps\n")},
+		"test/fixtures/fixtures.go":     {Data: []byte("package fixtures\n")},
+		"vendor/example.com/dep/dep.go": {Data: []byte("package dep\n")},
+		"web/node_modules/left/pad.py":  {Data: []byte("print('pad')\n")},
+	}
+
+	newFiles := func(...analyzer.Origin) analyzer.Analyzer {
+		return filesAnalyzer{}
+	}
+
+	registry := dispatch.NewRegistry(
+		dispatch.Language{ID: ts.GO, Detected: []string{"Go"}, New: newFiles},
+		dispatch.Language{ID: ts.PYTHON, Detected: []string{"Python"}, New: newFiles},
+	)
+
+	got, err := dispatch.Dispatcher(dispatch.WithRegistry(registry)).AnalyzeLanguages(context.Background(), dir)
+	require.NoError(t, err)
+
+	// first-party directories with names of vendored code e.g. internal/cache are analyzed
+	require.Equal(t, analyzer.PackageLanguages{
+		"deps/deps.go":              "go",
+		"go.mod":                    "go",
+		"internal/cache/cache.go":   "go",
+		"pkg/external/external.go":  "go",
+		"test/fixtures/fixtures.go": "go",
+	}, got)
+}
+
+func TestDispatcherIgnores(t *testing.T) {
+	dir := fstest.MapFS{
+		".gitignore":          {Data: []byte("/gen\n")},
+		"go.mod":              {Data: []byte("module example.com/poly\n")},
+		"api/api.go":          {Data: []byte("package api\n")},
+		"api/api.pb.go":       {Data: []byte("package api\n")},
+		"api/.udaignore":      {Data: []byte("legacy/\n")},
+		"api/legacy/old.go":   {Data: []byte("package legacy\n")},
+		"gen/gen.go":          {Data: []byte("package gen\n")},
+		"tools/gen.py":        {Data: []byte("print('generated')\n")},
+		"services/web/web.go": {Data: []byte("package web\n")},
+	}
+
+	newFiles := func(...analyzer.Origin) analyzer.Analyzer {
+		return filesAnalyzer{}
+	}
+
+	registry := dispatch.NewRegistry(
+		dispatch.Language{ID: ts.GO, Detected: []string{"Go"}, New: newFiles},
+		dispatch.Language{ID: ts.PYTHON, Detected: []string{"Python"}, New: newFiles},
+	)
+
+	tests := map[string]struct {
+		opts     []dispatch.Option
+		expected analyzer.PackageLanguages
+	}{
+		"ignore files": {
+			expected: analyzer.PackageLanguages{
+				"api/api.go":          "go",
+				"api/api.pb.go":       "go",
+				"go.mod":              "go",
+				"services/web/web.go": "go",
+				"tools/gen.py":        "python",
+			},
+		},
+		"exclude": {
+			opts: []dispatch.Option{dispatch.WithExclude("**/*.pb.go", "tools")},
+			expected: analyzer.PackageLanguages{
+				"api/api.go":          "go",
+				"go.mod":              "go",
+				"services/web/web.go": "go",
+			},
+		},
+		"include keeps the files no language claims": {
+			opts: []dispatch.Option{dispatch.WithInclude("services/**")},
+			expected: analyzer.PackageLanguages{
+				"go.mod":              "go",
+				"services/web/web.go": "go",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			d := dispatch.Dispatcher(append(tt.opts, dispatch.WithRegistry(registry))...)
+
+			got, err := d.AnalyzeLanguages(context.Background(), dir)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+// countingAnalyzer reports the same package for every language and counts how often it walks the directory
+type countingAnalyzer struct {
+	walks   *atomic.Int32
+	imports []analyzer.Import
+}
+
+func (c countingAnalyzer) Analyze(context.Context, fs.FS) (analyzer.PackageImports, error) {
+	c.walks.Add(1)
+	return analyzer.PackageImports{"shared": c.imports}, nil
+}
+
+func (c countingAnalyzer) AnalyzeV2(context.Context, fs.FS) ([]analyzer.Metrics, error) {
+	c.walks.Add(1)
+	return nil, nil
+}
+
+func (c countingAnalyzer) AnalyzeOrigins(context.Context, fs.FS) (analyzer.ImportOrigins, error) {
+	c.walks.Add(1)
+	return nil, nil
+}
+
+func TestDispatcherAnalyzeAll(t *testing.T) {
+	dir := fstest.MapFS{
+		"api/api.go":   {Data: []byte("package api\n")},
+		"tools/gen.py": {Data: []byte("print('generated')\n")},
+	}
+
+	var walks atomic.Int32
+
+	registry := dispatch.NewRegistry(
+		dispatch.Language{ID: ts.GO, Detected: []string{"Go"}, New: func(...analyzer.Origin) analyzer.Analyzer {
+			return countingAnalyzer{walks: &walks, imports: []analyzer.Import{`"fmt"`, `"os"`}}
+		}},
+		dispatch.Language{ID: ts.PYTHON, Detected: []string{"Python"}, New: func(...analyzer.Origin) analyzer.Analyzer {
+			return countingAnalyzer{walks: &walks, imports: []analyzer.Import{`"os"`, `"json"`}}
+		}},
+	)
+
+	r, err := dispatch.Dispatcher(dispatch.WithRegistry(registry)).AnalyzeAll(context.Background(), dir)
+	require.NoError(t, err)
+
+	// every method of every language runs once
+	require.EqualValues(t, 6, walks.Load())
+
+	// imports of a package analyzed by several languages are merged without duplicates
+	require.Equal(t, analyzer.PackageImports{"shared": {`"fmt"`, `"os"`, `"json"`}}, r.Imports)
+	require.Equal(t, analyzer.PackageLanguages{"shared": "go"}, r.Languages)
+}
+
+func TestDispatcherAnalyze(t *testing.T) {
+	dir := os.DirFS(".testdata/polyglot")
+
+	d := dispatch.Dispatcher()
+
+	pi, err := d.Analyze(context.Background(), dir)
+	require.NoError(t, err)
+	require.Equal(t, analyzer.PackageImports{
+		"example.com/poly/api": {`"fmt"`},
+		"report":               {`"json"`},
+		"web/lib":              {`"path"`},
+		"web/src":              {`"fs"`},
+	}, pi)
+
+	languages, err := d.AnalyzeLanguages(context.Background(), dir)
+	require.NoError(t, err)
+	// javascript and typescript share an analyzer but a package without typescript sources is javascript
+	require.Equal(t, analyzer.PackageLanguages{
+		"example.com/poly/api": "go",
+		"report":               "python",
+		"web/lib":              "javascript",
+		"web/src":              "typescript",
+	}, languages)
+
+	metrics, err := d.AnalyzeV2(context.Background(), dir)
+	require.NoError(t, err)
+
+	packages := make([]analyzer.Package, 0, len(metrics))
+	for _, m := range metrics {
+		packages = append(packages, m.Package)
+	}
+
+	require.Equal(t, []analyzer.Package{"example.com/poly/api", "report", "web/lib", "web/src"}, packages)
+}
+
+func TestDispatcherWithOrigins(t *testing.T) {
+	dir := os.DirFS(".testdata/polyglot")
+
+	d := dispatch.Dispatcher(dispatch.WithOrigins(analyzer.FirstParty, analyzer.Workspace))
+
+	pi, err := d.Analyze(context.Background(), dir)
+	require.NoError(t, err)
+	require.Equal(t, analyzer.PackageImports{
+		"example.com/poly/api": {},
+		"report":               {},
+		"web/lib":              {},
+		"web/src":              {},
+	}, pi)
+
+	origins, err := d.AnalyzeOrigins(context.Background(), dir)
+	require.NoError(t, err)
+	require.Len(t, origins, 4)
+}
@@ -0,0 +1,119 @@
+package dispatch
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer/golang"
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer/javascript"
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer/python"
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer/rust"
+	"github.com/flamingoosesoftwareinc/uda/internal/ts"
+)
+
+// Language routes the files go-enry detects as one of Detected to the analyzer of a language
+type Language struct {
+	// reported in the language column e.g. "go"
+	ID ts.LanguageID
+	// names of the languages as detected by go-enry e.g. "TypeScript" and "TSX"
+	Detected []string
+	// New creates the analyzer, origins restrict the analysis to imports of the given origins when set
+	New func(origins ...analyzer.Origin) analyzer.Analyzer
+}
+
+// Registry is the set of languages a dispatcher routes files to
+type Registry struct {
+	mu        sync.RWMutex
+	languages []Language
+}
+
+func NewRegistry(languages ...Language) *Registry {
+	r := &Registry{}
+	for _, l := range languages {
+		r.Register(l)
+	}
+
+	return r
+}
+
+// Register adds a language, replacing the language of the same ID
+func (r *Registry) Register(l Language) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if i := slices.IndexFunc(r.languages, func(existing Language) bool {
+		return existing.ID == l.ID
+	}); i >= 0 {
+		r.languages[i] = l
+		return
+	}
+
+	r.languages = append(r.languages, l)
+}
+
+// Languages returns the registered languages in the order they were registered
+func (r *Registry) Languages() []Language {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.languages)
+}
+
+var defaultRegistry = NewRegistry(
+	Language{
+		ID:       ts.GO,
+		Detected: []string{"Go"},
+		New: func(origins ...analyzer.Origin) analyzer.Analyzer {
+			opts := []golang.Option{}
+			if len(origins) > 0 {
+				opts = append(opts, golang.WithOrigins(origins...))
+			}
+
+			return golang.GoAnalyzer(opts...)
+		},
+	},
+	Language{
+		ID:       ts.PYTHON,
+		Detected: []string{"Python"},
+		New: func(origins ...analyzer.Origin) analyzer.Analyzer {
+			opts := []python.Option{}
+			if len(origins) > 0 {
+				opts = append(opts, python.WithOrigins(origins...))
+			}
+
+			return python.PythonAnalyzer(opts...)
+		},
+	},
+	Language{
+		// a single analyzer as javascript and typescript packages import each other
+		// packages without typescript sources are reported as javascript by the analyzer
+		ID:       ts.TS,
+		Detected: []string{"TypeScript", "TSX", "JavaScript"},
+		New: func(origins ...analyzer.Origin) analyzer.Analyzer {
+			opts := []javascript.Option{}
+			if len(origins) > 0 {
+				opts = append(opts, javascript.WithOrigins(origins...))
+			}
+
+			return javascript.JavaScriptAnalyzer(opts...)
+		},
+	},
+	Language{
+		ID:       ts.RUST,
+		Detected: []string{"Rust"},
+		New: func(origins ...analyzer.Origin) analyzer.Analyzer {
+			opts := []rust.Option{}
+			if len(origins) > 0 {
+				opts = append(opts, rust.WithOrigins(origins...))
+			}
+
+			return rust.RustAnalyzer(opts...)
+		},
+	},
+)
+
+// Default returns the registry of the languages uda supports out of the box
+func Default() *Registry {
+	return defaultRegistry
+}
@@ -0,0 +1,192 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+var ErrBadPattern = errors.New("invalid glob pattern")
+
+// ignoreFiles are read in every directory, rules of a later file take precedence
+var ignoreFiles = []string{".gitignore", ".udaignore"}
+
+// ignoreRule is a line of an ignore file in the .gitignore syntax
+type ignoreRule struct {
+	// directory of the ignore file the rule is relative to
+	dir     string
+	pattern string
+	// a !pattern re-includes what a previous rule ignored
+	negate bool
+	// a pattern/ only matches directories
+	dirOnly bool
+	// a pattern with a slash matches paths relative to dir, otherwise it matches names at any depth
+	anchored bool
+}
+
+func (r ignoreRule) matches(p string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+
+	name := path.Base(p)
+	if r.anchored {
+		name = p
+		if r.dir != "." {
+			name = strings.TrimPrefix(p, r.dir+"/")
+		}
+	}
+
+	ok, _ := doublestar.Match(r.pattern, name)
+
+	return ok
+}
+
+func parseIgnoreFile(dir string, content []byte) []ignoreRule {
+	rules := []ignoreRule{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		r := ignoreRule{dir: dir}
+
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+			line = line[1:]
+		}
+
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+
+		r.anchored = strings.Contains(line, "/")
+		r.pattern = strings.TrimPrefix(line, "/")
+
+		if r.pattern == "" || !doublestar.ValidatePattern(r.pattern) {
+			continue
+		}
+
+		rules = append(rules, r)
+	}
+
+	return rules
+}
+
+// ignoreMatcher reads the ignore files of every directory once
+type ignoreMatcher struct {
+	dir   fs.FS
+	mu    sync.Mutex
+	rules map[string][]ignoreRule
+}
+
+func (m *ignoreMatcher) load(dir string) []ignoreRule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if rules, ok := m.rules[dir]; ok {
+		return rules
+	}
+
+	rules := []ignoreRule{}
+	for _, name := range ignoreFiles {
+		content, err := fs.ReadFile(m.dir, path.Join(dir, name))
+		if err != nil {
+			continue
+		}
+
+		rules = append(rules, parseIgnoreFile(dir, content)...)
+	}
+
+	m.rules[dir] = rules
+
+	return rules
+}
+
+func (m *ignoreMatcher) ignored(p string, isDir bool) bool {
+	if p == "." {
+		return false
+	}
+
+	dirs := []string{}
+	for d := path.Dir(p); ; d = path.Dir(d) {
+		dirs = append(dirs, d)
+		if d == "." {
+			break
+		}
+	}
+
+	// the last matching rule wins and the rules of nested directories come last
+	ignored := false
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		for _, r := range m.load(dirs[i]) {
+			if r.matches(p, isDir) {
+				ignored = !r.negate
+			}
+		}
+	}
+
+	return ignored
+}
+
+// SkipIgnored skips the files and directories ignored by the .gitignore and .udaignore files of dir
+// the rules of an ignore file apply to its directory, the rules of nested directories take precedence
+// e.g. `!keep.log` in logs/.gitignore lists logs/keep.log even with `*.log` in .gitignore
+func SkipIgnored(dir fs.FS) FileFilter {
+	m := &ignoreMatcher{dir: dir, rules: make(map[string][]ignoreRule)}
+
+	return func(p string, d fs.DirEntry) bool {
+		return m.ignored(p, d.IsDir())
+	}
+}
+
+// ValidatePatterns reports the first pattern which is not a valid doublestar glob
+func ValidatePatterns(patterns ...string) error {
+	for _, pattern := range patterns {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("%w: %q", ErrBadPattern, pattern)
+		}
+	}
+
+	return nil
+}
+
+// MatchAny reports whether p matches any of patterns, doublestar globs relative to the listed directory
+func MatchAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := doublestar.Match(pattern, p); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Include skips the files matching none of patterns e.g. Include("services/**")
+// without patterns nothing is skipped
+func Include(patterns ...string) FileFilter {
+	return func(p string, d fs.DirEntry) bool {
+		return len(patterns) > 0 && !d.IsDir() && !MatchAny(patterns, p)
+	}
+}
+
+// Exclude skips the files and directories matching any of patterns e.g. Exclude("**/*_gen.go", "testdata")
+func Exclude(patterns ...string) FileFilter {
+	return func(p string, _ fs.DirEntry) bool {
+		return p != "." && MatchAny(patterns, p)
+	}
+}
@@ -0,0 +1,179 @@
+package files
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkipIgnored(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		dir  fstest.MapFS
+		want []string
+	}{
+		"no ignore file": {
+			dir: fstest.MapFS{
+				"main.go":     {},
+				"api/api.go":  {},
+				"api/api.log": {},
+			},
+			want: []string{"main.go", "api/api.go", "api/api.log"},
+		},
+		"names match at any depth": {
+			dir: fstest.MapFS{
+				".gitignore":  {Data: []byte("# logs\n*.log\n")},
+				"main.go":     {},
+				"main.log":    {},
+				"api/api.go":  {},
+				"api/api.log": {},
+			},
+			want: []string{"main.go", "api/api.go"},
+		},
+		"directories only": {
+			dir: fstest.MapFS{
+				".gitignore":      {Data: []byte("build/\n")},
+				"build/main.go":   {},
+				"api/build/x.go":  {},
+				"cmd/build.go":    {},
+				"cmd/main/app.go": {},
+			},
+			want: []string{"cmd/build.go", "cmd/main/app.go"},
+		},
+		"anchored": {
+			dir: fstest.MapFS{
+				".gitignore":       {Data: []byte("/gen\napi/*.pb.go\n")},
+				"gen/gen.go":       {},
+				"api/gen/gen.go":   {},
+				"api/api.pb.go":    {},
+				"api/v1/api.pb.go": {},
+			},
+			want: []string{"api/gen/gen.go", "api/v1/api.pb.go"},
+		},
+		"nested ignore file is relative to its directory": {
+			dir: fstest.MapFS{
+				"api/.gitignore":  {Data: []byte("/gen\n")},
+				"gen/gen.go":      {},
+				"api/gen/gen.go":  {},
+				"api/api.go":      {},
+				"web/gen/main.js": {},
+			},
+			want: []string{"gen/gen.go", "api/api.go", "web/gen/main.js"},
+		},
+		"nested negation takes precedence": {
+			dir: fstest.MapFS{
+				".gitignore":      {Data: []byte("*.log\n")},
+				"logs/.gitignore": {Data: []byte("!keep.log\n")},
+				"main.log":        {},
+				"logs/keep.log":   {},
+				"logs/other.log":  {},
+			},
+			want: []string{"logs/keep.log"},
+		},
+		"udaignore takes precedence over gitignore": {
+			dir: fstest.MapFS{
+				".gitignore":        {Data: []byte("generated/\n")},
+				".udaignore":        {Data: []byte("!generated/\n**/testdata/**\n")},
+				"generated/gen.go":  {},
+				"api/testdata/a.go": {},
+				"api/api.go":        {},
+			},
+			want: []string{"generated/gen.go", "api/api.go"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ListFiles(context.Background(), tt.dir, SkipHiddenFiles(), SkipIgnored(tt.dir))
+			require.NoError(t, err)
+			require.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestIncludeExclude(t *testing.T) {
+	t.Parallel()
+
+	dir := fstest.MapFS{
+		"go.mod":                 {},
+		"main.go":                {},
+		"services/api/api.go":    {},
+		"services/api/api.pb.go": {},
+		"services/web/web.go":    {},
+		"third_party/lib/lib.go": {},
+	}
+
+	tests := map[string]struct {
+		filters []FileFilter
+		want    []string
+	}{
+		"no patterns": {
+			filters: []FileFilter{Include(), Exclude()},
+			want: []string{
+				"go.mod",
+				"main.go",
+				"services/api/api.go",
+				"services/api/api.pb.go",
+				"services/web/web.go",
+				"third_party/lib/lib.go",
+			},
+		},
+		"include": {
+			filters: []FileFilter{Include("services/**")},
+			want: []string{
+				"services/api/api.go",
+				"services/api/api.pb.go",
+				"services/web/web.go",
+			},
+		},
+		"exclude files": {
+			filters: []FileFilter{Exclude("**/*.pb.go")},
+			want: []string{
+				"go.mod",
+				"main.go",
+				"services/api/api.go",
+				"services/web/web.go",
+				"third_party/lib/lib.go",
+			},
+		},
+		"exclude directories": {
+			filters: []FileFilter{Exclude("third_party", "services/web")},
+			want: []string{
+				"go.mod",
+				"main.go",
+				"services/api/api.go",
+				"services/api/api.pb.go",
+			},
+		},
+		"include and exclude": {
+			filters: []FileFilter{Include("services/**"), Exclude("**/*.pb.go")},
+			want: []string{
+				"services/api/api.go",
+				"services/web/web.go",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ListFiles(context.Background(), dir, tt.filters...)
+			require.NoError(t, err)
+			require.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidatePatterns(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidatePatterns())
+	require.NoError(t, ValidatePatterns("services/**", "**/*.{pb,gen}.go"))
+	require.ErrorIs(t, ValidatePatterns("services/**", "api/[a-"), ErrBadPattern)
+}
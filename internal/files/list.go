@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 )
 
+// FileFilter reports whether a file or directory is skipped given its path in the listed directory
 type FileFilter func(path string, d fs.DirEntry) bool
 
 func ListFiles(ctx context.Context, dirFS fs.FS, filters ...FileFilter) ([]string, error) {
@@ -14,9 +15,9 @@ func ListFiles(ctx context.Context, dirFS fs.FS, filters ...FileFilter) ([]strin
 
 	if err := fs.WalkDir(dirFS, ".", func(path string, d fs.DirEntry, err error) error {
 		if d.IsDir() {
-			shouldSkip := applyFilters(d.Name(), d, filters)
+			shouldSkip := applyFilters(path, d, filters)
 			if shouldSkip {
-				slog.DebugContext(ctx, "skipping directory", "name", d.Name())
+				slog.DebugContext(ctx, "skipping directory", "path", path)
 				return fs.SkipDir
 			}
 			return nil
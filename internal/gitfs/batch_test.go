@@ -0,0 +1,45 @@
+package gitfs
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRestartsAfterFailedRead(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ok.go"), []byte("package ok\n"), 0o644))
+
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=uda", "-c", "user.email=uda@example.com", "commit", "-q", "-m", "first"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	f, err := New(t.Context(), dir, "HEAD")
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, f.Close()) })
+
+	tree, err := git(t.Context(), dir, "rev-parse", "HEAD^{tree}")
+	require.NoError(t, err)
+
+	// the content of the tree follows its header and is left unread
+	_, err = f.read(strings.TrimSpace(string(tree)))
+	require.ErrorIs(t, err, ErrNotBlob)
+
+	got, err := fs.ReadFile(f, "ok.go")
+	require.NoError(t, err)
+	require.Equal(t, "package ok\n", string(got))
+}
@@ -0,0 +1,385 @@
+package gitfs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os/exec"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNotBlob = errors.New("git object is not a blob")
+
+// FS is a read only view of the tree of a git revision
+// files are read from the local object store with `git cat-file --batch` so no checkout is needed
+// FS must be closed to stop the git process
+type FS struct {
+	entries map[string]*entry
+	repoDir string
+
+	mu    sync.Mutex
+	batch *exec.Cmd
+	stdin io.WriteCloser
+	out   *bufio.Reader
+}
+
+var (
+	_ fs.FS        = &FS{}
+	_ fs.ReadDirFS = &FS{}
+	_ fs.StatFS    = &FS{}
+)
+
+type entry struct {
+	name string
+	// object id of the blob, empty for directories
+	oid      string
+	size     int64
+	children []string
+}
+
+func (e *entry) isDir() bool {
+	return e.oid == ""
+}
+
+// New lists the tree of rev in the repository containing repoDir
+// e.g. New(ctx, ".", "HEAD~1")
+func New(ctx context.Context, repoDir, rev string) (*FS, error) {
+	tree, err := git(ctx, repoDir, "rev-parse", "--verify", "--end-of-options", rev+"^{tree}")
+	if err != nil {
+		return nil, err
+	}
+
+	listing, err := git(
+		ctx,
+		repoDir,
+		"ls-tree",
+		"-r",
+		"-z",
+		"--long",
+		"--full-tree",
+		strings.TrimSpace(string(tree)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &FS{
+		entries: map[string]*entry{".": {name: "."}},
+		repoDir: repoDir,
+	}
+
+	for record := range bytes.SplitSeq(listing, []byte{0}) {
+		if len(record) == 0 {
+			continue
+		}
+
+		if err := f.add(string(record)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, e := range f.entries {
+		slices.Sort(e.children)
+	}
+
+	if err := f.start(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// start runs the `git cat-file --batch` process blobs are read from
+func (f *FS) start() error {
+	// not bound to ctx, the process lives as long as the FS and is stopped by Close
+	f.batch = exec.Command("git", "-C", f.repoDir, "cat-file", "--batch")
+
+	stdin, err := f.batch.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	stdout, err := f.batch.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	f.stdin = stdin
+	f.out = bufio.NewReader(stdout)
+
+	return f.batch.Start()
+}
+
+// restart replaces the git process after a failed read
+// the rest of a partially read response would otherwise be taken as the response to the next request
+func (f *FS) restart() error {
+	_ = f.stdin.Close()
+	// killed rather than waited for as it may be blocked writing the unread rest of a response
+	_ = f.batch.Process.Kill()
+	_ = f.batch.Wait()
+
+	return f.start()
+}
+
+// add records a single `git ls-tree --long` record e.g. "100644 blob <oid>     1234\tpath/to/file.go"
+// submodules and symlinks are skipped as their content is not part of the tree
+func (f *FS) add(record string) error {
+	meta, name, ok := strings.Cut(record, "\t")
+	if !ok {
+		return fmt.Errorf("malformed ls-tree record %q", record)
+	}
+
+	fields := strings.Fields(meta)
+	if len(fields) != 4 {
+		return fmt.Errorf("malformed ls-tree record %q", record)
+	}
+
+	mode, objectType, oid, sizeField := fields[0], fields[1], fields[2], fields[3]
+	if objectType != "blob" || mode == "120000" {
+		return nil
+	}
+
+	size, err := strconv.ParseInt(sizeField, 10, 64)
+	if err != nil {
+		return fmt.Errorf("malformed ls-tree record %q: %w", record, err)
+	}
+
+	f.entries[name] = &entry{name: path.Base(name), oid: oid, size: size}
+
+	// create every parent directory up to the root
+	for child, dir := name, path.Dir(name); ; child, dir = dir, path.Dir(dir) {
+		parent, ok := f.entries[dir]
+		if !ok {
+			parent = &entry{name: path.Base(dir)}
+			f.entries[dir] = parent
+		}
+
+		if slices.Contains(parent.children, path.Base(child)) {
+			break
+		}
+
+		parent.children = append(parent.children, path.Base(child))
+
+		if dir == "." {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (f *FS) Open(name string) (fs.File, error) {
+	e, err := f.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.isDir() {
+		entries, err := f.ReadDir(name)
+		if err != nil {
+			return nil, err
+		}
+
+		return &dir{info: fileInfo{e}, entries: entries}, nil
+	}
+
+	content, err := f.read(e.oid)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &file{info: fileInfo{e}, Reader: bytes.NewReader(content)}, nil
+}
+
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	e, err := f.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.isDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(e.children))
+	for _, child := range e.children {
+		entries = append(entries, fs.FileInfoToDirEntry(fileInfo{f.entries[path.Join(name, child)]}))
+	}
+
+	return entries, nil
+}
+
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	e, err := f.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return fileInfo{e}, nil
+}
+
+// Close stops the git process reading blobs
+func (f *FS) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.stdin.Close(); err != nil {
+		return err
+	}
+
+	return f.batch.Wait()
+}
+
+func (f *FS) lookup(op, name string) (*entry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	e, ok := f.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+
+	return e, nil
+}
+
+// read requests a blob from `git cat-file --batch` and restarts it when the request fails
+func (f *FS) read(oid string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	content, err := f.request(oid)
+	if err != nil {
+		if restartErr := f.restart(); restartErr != nil {
+			return nil, errors.Join(err, restartErr)
+		}
+
+		return nil, err
+	}
+
+	return content, nil
+}
+
+// request writes oid to `git cat-file --batch` and reads the response
+// the response is a header "<oid> <type> <size>\n" followed by the content and a newline
+func (f *FS) request(oid string) ([]byte, error) {
+	if _, err := io.WriteString(f.stdin, oid+"\n"); err != nil {
+		return nil, err
+	}
+
+	header, err := f.out.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("unexpected cat-file header %q", header)
+	}
+
+	if fields[1] != "blob" {
+		return nil, fmt.Errorf("%w: %s is a %s", ErrNotBlob, oid, fields[1])
+	}
+
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, err
+	}
+
+	content := make([]byte, size+1)
+	if _, err := io.ReadFull(f.out, content); err != nil {
+		return nil, err
+	}
+
+	return content[:size], nil
+}
+
+func git(ctx context.Context, repoDir string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", repoDir}, args...)...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
+
+// Prefix returns the path of repoDir relative to the root of its repository
+// e.g. "internal/" when repoDir is the internal directory of the repository
+func Prefix(ctx context.Context, repoDir string) (string, error) {
+	out, err := git(ctx, repoDir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+type fileInfo struct {
+	e *entry
+}
+
+func (i fileInfo) Name() string       { return i.e.name }
+func (i fileInfo) Size() int64        { return i.e.size }
+func (i fileInfo) ModTime() time.Time { return time.Time{} }
+func (i fileInfo) IsDir() bool        { return i.e.isDir() }
+func (i fileInfo) Sys() any           { return nil }
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.e.isDir() {
+		return fs.ModeDir | 0o555
+	}
+
+	return 0o444
+}
+
+type file struct {
+	*bytes.Reader
+	info fileInfo
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *file) Close() error               { return nil }
+
+type dir struct {
+	info    fileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dir) Close() error               { return nil }
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: fs.ErrInvalid}
+}
+
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	n = min(n, len(remaining))
+	d.offset += n
+
+	return remaining[:n], nil
+}
@@ -0,0 +1,101 @@
+package gitfs_test
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/gitfs"
+	"github.com/stretchr/testify/require"
+)
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command(
+		"git",
+		append([]string{"-C", dir, "-c", "user.name=uda", "-c", "user.email=uda@example.com"}, args...)...,
+	)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+	require.NoError(t, os.WriteFile(name, []byte(content), 0o644))
+}
+
+func TestFS(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/x\n")
+	writeFile(t, filepath.Join(dir, "a", "a.go"), "package a\n")
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "first")
+
+	writeFile(t, filepath.Join(dir, "a", "b", "b.go"), "package b\n")
+	require.NoError(t, os.Remove(filepath.Join(dir, "a", "a.go")))
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "second")
+
+	// uncommitted changes are not visible
+	writeFile(t, filepath.Join(dir, "c", "c.go"), "package c\n")
+
+	tests := map[string]struct {
+		rev   string
+		files map[string]string
+	}{
+		"should read the previous revision": {
+			rev: "HEAD~1",
+			files: map[string]string{
+				"go.mod": "module example.com/x\n",
+				"a/a.go": "package a\n",
+			},
+		},
+		"should read the current revision": {
+			rev: "HEAD",
+			files: map[string]string{
+				"go.mod":   "module example.com/x\n",
+				"a/b/b.go": "package b\n",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			gfs, err := gitfs.New(t.Context(), dir, tt.rev)
+			require.NoError(t, err)
+
+			t.Cleanup(func() { require.NoError(t, gfs.Close()) })
+
+			expected := make([]string, 0, len(tt.files))
+			for name, content := range tt.files {
+				expected = append(expected, name)
+
+				got, err := fs.ReadFile(gfs, name)
+				require.NoError(t, err)
+				require.Equal(t, content, string(got))
+			}
+
+			require.NoError(t, fstest.TestFS(gfs, expected...))
+
+			_, err = gfs.Open("c/c.go")
+			require.ErrorIs(t, err, fs.ErrNotExist)
+		})
+	}
+
+	_, err := gitfs.New(t.Context(), dir, "does-not-exist")
+	require.Error(t, err)
+}
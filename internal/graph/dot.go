@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// writeDOT renders the graph in the graphviz DOT language
+// clusters are subgraphs and edge pen width grows logarithmically with the number of symbol uses
+func (e export) writeDOT(w io.Writer) error {
+	if _, err := fmt.Fprint(
+		w,
+		"digraph uda {\n\trankdir=LR;\n\tnode [shape=box, style=filled];\n",
+	); err != nil {
+		return err
+	}
+
+	for n, c := range e.clusters {
+		if _, err := fmt.Fprintf(w, "\tsubgraph cluster_%d {\n\t\tlabel=%q;\n", n, c.name); err != nil {
+			return err
+		}
+
+		for _, pkg := range c.packages {
+			instability := e.instability[pkg]
+			if _, err := fmt.Fprintf(
+				w,
+				"\t\t%s [label=%q, fillcolor=%q, tooltip=\"I: %.2f\"];\n",
+				e.ids[pkg],
+				pkg,
+				color(instability),
+				instability,
+			); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprint(w, "\t}\n"); err != nil {
+			return err
+		}
+	}
+
+	for _, edge := range e.edges() {
+		weight := e.weights[edge]
+		if _, err := fmt.Fprintf(
+			w,
+			"\t%s -> %s [label=\"%d\", weight=%d, penwidth=%.2f];\n",
+			e.ids[edge[0]],
+			e.ids[edge[1]],
+			weight,
+			weight,
+			1+math.Log2(float64(weight)+1),
+		); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprint(w, "}\n")
+
+	return err
+}
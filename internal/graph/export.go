@@ -0,0 +1,150 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+)
+
+type Format string
+
+const (
+	DOT     Format = "dot"
+	Mermaid Format = "mermaid"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// ParseFormat validates a format given by the user
+func ParseFormat(format string) (Format, error) {
+	switch f := Format(format); f {
+	case DOT, Mermaid:
+		return f, nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// Write renders the graph in the given format
+// nodes are colored by the instability of the package, green is stable and red is unstable
+// nodes are clustered by their parent package in the language of languages and edges are weighted by
+// the number of symbol uses
+func (g Graph) Write(
+	w io.Writer,
+	format Format,
+	metrics []analyzer.Metrics,
+	languages analyzer.PackageLanguages,
+) error {
+	e := newExport(g, metrics, languages)
+
+	switch format {
+	case DOT:
+		return e.writeDOT(w)
+	case Mermaid:
+		return e.writeMermaid(w)
+	}
+
+	return ErrUnsupportedFormat
+}
+
+type export struct {
+	graph Graph
+	// stable identifier of every node usable by any format e.g. n0
+	ids         map[analyzer.Package]string
+	instability map[analyzer.Package]float64
+	// symbol uses of every edge
+	weights  map[[2]analyzer.Package]uint
+	clusters []cluster
+}
+
+type cluster struct {
+	name     string
+	packages []analyzer.Package
+}
+
+// separators of the package names of the languages not separating them with a slash
+// e.g. shop.models in python and acme_core::store in rust
+var separators = map[string]string{
+	"python": ".",
+	"rust":   "::",
+}
+
+// parent returns the package pkg is nested in given the separator of its language
+// e.g. "example.com/internal" for "example.com/internal/a" and "shop" for the python "shop.models"
+// a top level package has the parent "."
+func parent(pkg analyzer.Package, language string) string {
+	separator, ok := separators[language]
+	if !ok {
+		return path.Dir(string(pkg))
+	}
+
+	i := strings.LastIndex(string(pkg), separator)
+	if i < 0 {
+		return "."
+	}
+
+	return string(pkg[:i])
+}
+
+func newExport(g Graph, metrics []analyzer.Metrics, languages analyzer.PackageLanguages) export {
+	e := export{
+		graph:       g,
+		ids:         make(map[analyzer.Package]string, len(g)),
+		instability: make(map[analyzer.Package]float64, len(metrics)),
+		weights:     make(map[[2]analyzer.Package]uint),
+	}
+
+	for _, m := range metrics {
+		e.instability[m.Package] = m.Instability()
+
+		for dep, stats := range m.Outward {
+			for _, s := range stats {
+				e.weights[[2]analyzer.Package{m.Package, dep}] += s.Count
+			}
+		}
+	}
+
+	byParent := make(map[string][]analyzer.Package)
+	for n, pkg := range g.Nodes() {
+		e.ids[pkg] = fmt.Sprintf("n%d", n)
+
+		p := parent(pkg, languages[pkg])
+		byParent[p] = append(byParent[p], pkg)
+	}
+
+	for p, packages := range byParent {
+		e.clusters = append(e.clusters, cluster{name: p, packages: packages})
+	}
+
+	slices.SortFunc(e.clusters, func(a, b cluster) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return e
+}
+
+// edges returns every edge of the graph in a stable order
+func (e export) edges() [][2]analyzer.Package {
+	edges := [][2]analyzer.Package{}
+	for _, pkg := range e.graph.Nodes() {
+		for _, dep := range e.graph[pkg] {
+			edges = append(edges, [2]analyzer.Package{pkg, dep})
+		}
+	}
+
+	return edges
+}
+
+// color interpolates from green for stable packages to red for unstable ones
+func color(instability float64) string {
+	instability = min(max(instability, 0), 1)
+	red := uint8(255 * instability)
+	green := uint8(255 * (1 - instability))
+
+	return fmt.Sprintf("#%02x%02x%02x", red, green, 0x40)
+}
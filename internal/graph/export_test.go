@@ -0,0 +1,130 @@
+package graph_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func exportInput() (graph.Graph, []analyzer.Metrics) {
+	pi := analyzer.PackageImports{
+		"example.com/cmd":        {`"fmt"`, `"example.com/internal/a"`, `"example.com/internal/b"`},
+		"example.com/internal/a": {`"example.com/internal/b"`},
+		"example.com/internal/b": {},
+	}
+
+	metrics := analyzer.BuildMetrics(map[analyzer.Package]analyzer.PackageCouplingStats{
+		"example.com/cmd": {
+			"fmt":                    {"fmt.Println": {Count: 1}},
+			"example.com/internal/a": {"a.Do": {Count: 3}},
+			"example.com/internal/b": {"b.Do": {Count: 1}},
+		},
+		"example.com/internal/a": {
+			"example.com/internal/b": {"b.Do": {Count: 1}, "b.Type": {Count: 1}},
+		},
+		"example.com/internal/b": {},
+	})
+
+	return graph.New(pi), metrics
+}
+
+func TestWriteDOT(t *testing.T) {
+	g, metrics := exportInput()
+
+	var buf bytes.Buffer
+	require.NoError(t, g.Write(&buf, graph.DOT, metrics, nil))
+
+	require.Equal(t, `digraph uda {
+	rankdir=LR;
+	node [shape=box, style=filled];
+	subgraph cluster_0 {
+		label="example.com";
+		n0 [label="example.com/cmd", fillcolor="#ff0040", tooltip="I: 1.00"];
+	}
+	subgraph cluster_1 {
+		label="example.com/internal";
+		n1 [label="example.com/internal/a", fillcolor="#aa5540", tooltip="I: 0.67"];
+		n2 [label="example.com/internal/b", fillcolor="#00ff40", tooltip="I: 0.00"];
+	}
+	n0 -> n1 [label="3", weight=3, penwidth=3.00];
+	n0 -> n2 [label="1", weight=1, penwidth=2.00];
+	n1 -> n2 [label="2", weight=2, penwidth=2.58];
+}
+`, buf.String())
+}
+
+func TestWriteMermaid(t *testing.T) {
+	g, metrics := exportInput()
+
+	var buf bytes.Buffer
+	require.NoError(t, g.Write(&buf, graph.Mermaid, metrics, nil))
+
+	require.Equal(t, `flowchart LR
+	subgraph c0 ["example.com"]
+		n0["example.com/cmd"]
+	end
+	subgraph c1 ["example.com/internal"]
+		n1["example.com/internal/a"]
+		n2["example.com/internal/b"]
+	end
+	n0 -->|3| n1
+	n0 -->|1| n2
+	n1 -->|2| n2
+	style n0 fill:#ff0040
+	style n1 fill:#aa5540
+	style n2 fill:#00ff40
+`, buf.String())
+}
+
+func TestWriteClustersByLanguage(t *testing.T) {
+	g := graph.New(analyzer.PackageImports{
+		"shop":                 {`"shop.models"`},
+		"shop.models":          {},
+		"tests":                {`"shop"`},
+		"acme_core::store":     {`"acme_core::model"`},
+		"acme_core::model":     {},
+		"example.com/poly/api": {},
+	})
+
+	languages := analyzer.PackageLanguages{
+		"shop":                 "python",
+		"shop.models":          "python",
+		"tests":                "python",
+		"acme_core::store":     "rust",
+		"acme_core::model":     "rust",
+		"example.com/poly/api": "go",
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, g.Write(&buf, graph.Mermaid, nil, languages))
+
+	// python and rust packages are nested by their own separator rather than by slashes
+	require.Equal(t, `flowchart LR
+	subgraph c0 ["."]
+		n3["shop"]
+		n5["tests"]
+	end
+	subgraph c1 ["acme_core"]
+		n0["acme_core::model"]
+		n1["acme_core::store"]
+	end
+	subgraph c2 ["example.com/poly"]
+		n2["example.com/poly/api"]
+	end
+	subgraph c3 ["shop"]
+		n4["shop.models"]
+	end
+	n1 -->|0| n0
+	n3 -->|0| n4
+	n5 -->|0| n3
+	style n0 fill:#00ff40
+	style n1 fill:#00ff40
+	style n2 fill:#00ff40
+	style n3 fill:#00ff40
+	style n4 fill:#00ff40
+	style n5 fill:#00ff40
+`, buf.String())
+}
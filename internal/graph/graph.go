@@ -0,0 +1,166 @@
+package graph
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+)
+
+// Graph is a directed graph of packages and the packages they import
+// only imports of analyzed packages are edges, so std and third-party packages are not part of the graph
+// e.g. {"cmd":["internal/analyzer"],"internal/analyzer":[]}
+type Graph map[analyzer.Package][]analyzer.Package
+
+// New builds the first-party dependency graph of pi
+// the edges of every node are sorted so traversals are deterministic
+func New(pi analyzer.PackageImports) Graph {
+	g := make(Graph, len(pi))
+
+	for pkg, imports := range pi {
+		edges := make([]analyzer.Package, 0, len(imports))
+
+		for _, i := range imports {
+			dep := i.Package()
+			if _, ok := pi[dep]; !ok {
+				continue
+			}
+
+			if !slices.Contains(edges, dep) {
+				edges = append(edges, dep)
+			}
+		}
+
+		slices.Sort(edges)
+		g[pkg] = edges
+	}
+
+	return g
+}
+
+// Nodes returns every package of the graph sorted
+func (g Graph) Nodes() []analyzer.Package {
+	nodes := make([]analyzer.Package, 0, len(g))
+	for pkg := range g {
+		nodes = append(nodes, pkg)
+	}
+
+	slices.Sort(nodes)
+
+	return nodes
+}
+
+// HasEdge reports whether from imports to
+func (g Graph) HasEdge(from, to analyzer.Package) bool {
+	return slices.Contains(g[from], to)
+}
+
+// Cycle is a chain of packages where the last package imports the first
+// e.g. [a b c] is a -> b -> c -> a
+type Cycle []analyzer.Package
+
+func (c Cycle) String() string {
+	if len(c) == 0 {
+		return ""
+	}
+
+	chain := make([]string, 0, len(c)+1)
+	for _, pkg := range c {
+		chain = append(chain, string(pkg))
+	}
+
+	chain = append(chain, string(c[0]))
+
+	return strings.Join(chain, " -> ")
+}
+
+// Edges returns every edge of the cycle in order e.g. [a b c] is [[a b] [b c] [c a]]
+func (c Cycle) Edges() [][2]analyzer.Package {
+	edges := make([][2]analyzer.Package, 0, len(c))
+	for i, pkg := range c {
+		edges = append(edges, [2]analyzer.Package{pkg, c[(i+1)%len(c)]})
+	}
+
+	return edges
+}
+
+// ShortestPath returns the shortest chain of imports from from to to, both included
+// nil when from does not depend on to e.g. [a b c] when a imports b and b imports c
+func (g Graph) ShortestPath(from, to analyzer.Package) []analyzer.Package {
+	if _, ok := g[from]; !ok {
+		return nil
+	}
+
+	parent := map[analyzer.Package]analyzer.Package{from: from}
+	queue := []analyzer.Package{from}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if current == to {
+			path := []analyzer.Package{}
+			for n := to; n != from; n = parent[n] {
+				path = append(path, n)
+			}
+
+			path = append(path, from)
+			slices.Reverse(path)
+
+			return path
+		}
+
+		for _, dep := range g[current] {
+			if _, visited := parent[dep]; visited {
+				continue
+			}
+
+			parent[dep] = current
+			queue = append(queue, dep)
+		}
+	}
+
+	return nil
+}
+
+// Dependents returns the packages importing pkg directly and the packages importing it through other packages
+// both sorted, a package importing pkg directly is not repeated in transitive
+func (g Graph) Dependents(pkg analyzer.Package) (direct, transitive []analyzer.Package) {
+	importers := make(Graph, len(g))
+	for from, deps := range g {
+		for _, dep := range deps {
+			importers[dep] = append(importers[dep], from)
+		}
+	}
+
+	direct = []analyzer.Package{}
+	transitive = []analyzer.Package{}
+
+	visited := map[analyzer.Package]bool{pkg: true}
+	queue := []analyzer.Package{pkg}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, importer := range importers[current] {
+			if visited[importer] {
+				continue
+			}
+
+			visited[importer] = true
+			queue = append(queue, importer)
+
+			if current == pkg {
+				direct = append(direct, importer)
+			} else {
+				transitive = append(transitive, importer)
+			}
+		}
+	}
+
+	slices.Sort(direct)
+	slices.Sort(transitive)
+
+	return direct, transitive
+}
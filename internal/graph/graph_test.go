@@ -0,0 +1,136 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	got := graph.New(analyzer.PackageImports{
+		"a": {`"fmt"`, `"c"`, `"b"`, `"b"`},
+		"b": {`"context"`},
+		"c": {},
+	})
+
+	require.Equal(t, graph.Graph{
+		"a": {"b", "c"},
+		"b": {},
+		"c": {},
+	}, got)
+}
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	tests := map[string]struct {
+		graph graph.Graph
+		want  [][]analyzer.Package
+	}{
+		"should not find components in acyclic graph": {
+			graph: graph.Graph{
+				"a": {"b", "c"},
+				"b": {"c"},
+				"c": {},
+			},
+			want: nil,
+		},
+		"should find self import": {
+			graph: graph.Graph{
+				"a": {"a"},
+				"b": {"a"},
+			},
+			want: [][]analyzer.Package{{"a"}},
+		},
+		"should find separate components": {
+			graph: graph.Graph{
+				"a": {"b"},
+				"b": {"c"},
+				"c": {"a", "d"},
+				"d": {"e"},
+				"e": {"d"},
+				"f": {"a"},
+			},
+			want: [][]analyzer.Package{{"a", "b", "c"}, {"d", "e"}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, tt.graph.StronglyConnectedComponents())
+		})
+	}
+}
+
+func TestCycles(t *testing.T) {
+	g := graph.Graph{
+		"a": {"b", "c"},
+		"b": {"a", "c"},
+		"c": {"a"},
+	}
+
+	components := g.StronglyConnectedComponents()
+	require.Len(t, components, 1)
+
+	got := g.Cycles(components[0], 0)
+	require.Equal(t, []graph.Cycle{
+		{"a", "b"},
+		{"a", "b", "c"},
+		{"a", "c"},
+	}, got)
+	require.Equal(t, "a -> b -> c -> a", got[1].String())
+	require.Equal(t, [][2]analyzer.Package{{"a", "b"}, {"b", "c"}, {"c", "a"}}, got[1].Edges())
+
+	require.Len(t, g.Cycles(components[0], 2), 2)
+}
+
+func TestShortestCycle(t *testing.T) {
+	g := graph.Graph{
+		"a": {"b"},
+		"b": {"c", "d"},
+		"c": {"a"},
+		"d": {"b"},
+		"e": {"a"},
+	}
+
+	require.Equal(t, graph.Cycle{"a", "b", "c"}, g.ShortestCycle("a"))
+	require.Equal(t, graph.Cycle{"b", "d"}, g.ShortestCycle("b"))
+	require.Equal(t, graph.Cycle{"d", "b"}, g.ShortestCycle("d"))
+	require.Nil(t, g.ShortestCycle("e"))
+}
+
+func TestShortestPath(t *testing.T) {
+	g := graph.Graph{
+		"a": {"b", "d"},
+		"b": {"c"},
+		"c": {},
+		"d": {"e"},
+		"e": {"c"},
+	}
+
+	require.Equal(t, []analyzer.Package{"a", "b", "c"}, g.ShortestPath("a", "c"))
+	require.Equal(t, []analyzer.Package{"d", "e", "c"}, g.ShortestPath("d", "c"))
+	require.Equal(t, []analyzer.Package{"a"}, g.ShortestPath("a", "a"))
+	require.Nil(t, g.ShortestPath("c", "a"))
+	require.Nil(t, g.ShortestPath("z", "a"))
+}
+
+func TestDependents(t *testing.T) {
+	g := graph.Graph{
+		"a": {"b"},
+		"b": {"c"},
+		"c": {"b"},
+		"d": {"c"},
+		"e": {},
+	}
+
+	direct, transitive := g.Dependents("c")
+	require.Equal(t, []analyzer.Package{"b", "d"}, direct)
+	require.Equal(t, []analyzer.Package{"a"}, transitive)
+
+	direct, transitive = g.Dependents("e")
+	require.Empty(t, direct)
+	require.Empty(t, transitive)
+}
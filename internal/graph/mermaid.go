@@ -0,0 +1,71 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeMermaid renders the graph as a mermaid flowchart
+// clusters are subgraphs and edges are labelled with the number of symbol uses
+func (e export) writeMermaid(w io.Writer) error {
+	if _, err := fmt.Fprint(w, "flowchart LR\n"); err != nil {
+		return err
+	}
+
+	for n, c := range e.clusters {
+		if _, err := fmt.Fprintf(
+			w,
+			"\tsubgraph c%d [\"%s\"]\n",
+			n,
+			mermaidEscape(c.name),
+		); err != nil {
+			return err
+		}
+
+		for _, pkg := range c.packages {
+			if _, err := fmt.Fprintf(
+				w,
+				"\t\t%s[\"%s\"]\n",
+				e.ids[pkg],
+				mermaidEscape(string(pkg)),
+			); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprint(w, "\tend\n"); err != nil {
+			return err
+		}
+	}
+
+	for _, edge := range e.edges() {
+		if _, err := fmt.Fprintf(
+			w,
+			"\t%s -->|%d| %s\n",
+			e.ids[edge[0]],
+			e.weights[edge],
+			e.ids[edge[1]],
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, pkg := range e.graph.Nodes() {
+		if _, err := fmt.Fprintf(
+			w,
+			"\tstyle %s fill:%s\n",
+			e.ids[pkg],
+			color(e.instability[pkg]),
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mermaidEscape escapes quotes which would otherwise end a mermaid label
+func mermaidEscape(label string) string {
+	return strings.ReplaceAll(label, `"`, "#quot;")
+}
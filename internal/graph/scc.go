@@ -0,0 +1,180 @@
+package graph
+
+import (
+	"slices"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+)
+
+// StronglyConnectedComponents returns every component of the graph that contains a cycle
+// a component is either several packages that can all reach each other or a single package importing itself
+// uses Tarjan's algorithm, packages of a component and the components are sorted
+func (g Graph) StronglyConnectedComponents() [][]analyzer.Package {
+	t := tarjan{
+		graph:   g,
+		index:   make(map[analyzer.Package]int, len(g)),
+		lowlink: make(map[analyzer.Package]int, len(g)),
+		onStack: make(map[analyzer.Package]bool, len(g)),
+	}
+
+	for _, pkg := range g.Nodes() {
+		if _, visited := t.index[pkg]; !visited {
+			t.strongConnect(pkg)
+		}
+	}
+
+	slices.SortFunc(t.components, func(a, b []analyzer.Package) int {
+		return slices.Compare(a, b)
+	})
+
+	return t.components
+}
+
+type tarjan struct {
+	graph      Graph
+	next       int
+	index      map[analyzer.Package]int
+	lowlink    map[analyzer.Package]int
+	onStack    map[analyzer.Package]bool
+	stack      []analyzer.Package
+	components [][]analyzer.Package
+}
+
+func (t *tarjan) strongConnect(pkg analyzer.Package) {
+	t.index[pkg] = t.next
+	t.lowlink[pkg] = t.next
+	t.next++
+
+	t.stack = append(t.stack, pkg)
+	t.onStack[pkg] = true
+
+	for _, dep := range t.graph[pkg] {
+		if _, visited := t.index[dep]; !visited {
+			t.strongConnect(dep)
+			t.lowlink[pkg] = min(t.lowlink[pkg], t.lowlink[dep])
+		} else if t.onStack[dep] {
+			t.lowlink[pkg] = min(t.lowlink[pkg], t.index[dep])
+		}
+	}
+
+	if t.lowlink[pkg] != t.index[pkg] {
+		return
+	}
+
+	// pkg is the root of a component, everything above it on the stack belongs to the component
+	component := []analyzer.Package{}
+	for {
+		last := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		t.onStack[last] = false
+		component = append(component, last)
+
+		if last == pkg {
+			break
+		}
+	}
+
+	if len(component) == 1 && !t.graph.HasEdge(pkg, pkg) {
+		return
+	}
+
+	slices.Sort(component)
+	t.components = append(t.components, component)
+}
+
+// Cycles returns the elementary cycles within a strongly connected component
+// every cycle starts at its smallest package so each cycle is only reported once
+// enumerating cycles is exponential in the worst case so at most limit cycles are returned, limit <= 0 is unlimited
+func (g Graph) Cycles(component []analyzer.Package, limit int) []Cycle {
+	sorted := slices.Clone(component)
+	slices.Sort(sorted)
+
+	c := cycleFinder{
+		graph:   g,
+		limit:   limit,
+		allowed: make(map[analyzer.Package]bool, len(sorted)),
+		onPath:  make(map[analyzer.Package]bool, len(sorted)),
+	}
+
+	for _, pkg := range sorted {
+		c.allowed[pkg] = true
+	}
+
+	for _, start := range sorted {
+		if c.full() {
+			break
+		}
+
+		c.find(start, []analyzer.Package{start})
+
+		// every cycle through start has been found, later starts must not revisit it
+		c.allowed[start] = false
+	}
+
+	return c.cycles
+}
+
+type cycleFinder struct {
+	graph   Graph
+	limit   int
+	allowed map[analyzer.Package]bool
+	onPath  map[analyzer.Package]bool
+	cycles  []Cycle
+}
+
+func (c *cycleFinder) full() bool {
+	return c.limit > 0 && len(c.cycles) >= c.limit
+}
+
+func (c *cycleFinder) find(start analyzer.Package, path []analyzer.Package) {
+	pkg := path[len(path)-1]
+	c.onPath[pkg] = true
+	defer func() { c.onPath[pkg] = false }()
+
+	for _, dep := range c.graph[pkg] {
+		if c.full() {
+			return
+		}
+
+		switch {
+		case dep == start:
+			c.cycles = append(c.cycles, Cycle(slices.Clone(path)))
+		case c.allowed[dep] && !c.onPath[dep]:
+			c.find(start, append(path, dep))
+		}
+	}
+}
+
+// ShortestCycle returns the shortest cycle starting at pkg, nil when pkg is not part of a cycle
+func (g Graph) ShortestCycle(pkg analyzer.Package) Cycle {
+	parent := map[analyzer.Package]analyzer.Package{pkg: pkg}
+	queue := []analyzer.Package{pkg}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, dep := range g[current] {
+			if dep == pkg {
+				cycle := Cycle{}
+				for n := current; n != pkg; n = parent[n] {
+					cycle = append(cycle, n)
+				}
+
+				cycle = append(cycle, pkg)
+				slices.Reverse(cycle)
+
+				return cycle
+			}
+
+			if _, visited := parent[dep]; visited {
+				continue
+			}
+
+			parent[dep] = current
+			queue = append(queue, dep)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,269 @@
+// Package mcp serves the analyses of uda as tools of a Model Context Protocol server
+// so coding agents can query the coupling of a package before they change it
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime/debug"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/dispatch"
+	"github.com/flamingoosesoftwareinc/uda/internal/graph"
+	"github.com/flamingoosesoftwareinc/uda/internal/report"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var ErrUnknownPackage = errors.New("unknown package")
+
+// defaultMaxCycles bounds the cycles reported per component when the client does not
+const defaultMaxCycles = 20
+
+// server analyzes dir again on every call, unchanged files are read from the parse cache of the context
+type server struct {
+	dir  fs.FS
+	opts []dispatch.Option
+}
+
+// NewServer returns the server exposing the analyses of dir as tools
+// opts configure the dispatcher of every analysis e.g. dispatch.WithExclude("**/*.pb.go")
+func NewServer(dir fs.FS, opts ...dispatch.Option) *sdk.Server {
+	s := &server{dir: dir, opts: opts}
+
+	srv := sdk.NewServer(&sdk.Implementation{Name: "uda", Version: version()}, nil)
+
+	sdk.AddTool(srv, &sdk.Tool{
+		Name: "package_metrics",
+		Description: "Coupling metrics of the analyzed packages: afferent and efferent coupling, instability, " +
+			"abstractness, distance from the main sequence, imports and the symbols used across packages.",
+	}, s.packageMetrics)
+
+	sdk.AddTool(srv, &sdk.Tool{
+		Name: "dependency_path",
+		Description: "Shortest chain of imports through which a package depends on another " +
+			"with the files and lines of every import of the chain.",
+	}, s.dependencyPath)
+
+	sdk.AddTool(srv, &sdk.Tool{
+		Name:        "cycles",
+		Description: "Import cycles between the analyzed packages grouped by strongly connected component.",
+	}, s.cycles)
+
+	sdk.AddTool(srv, &sdk.Tool{
+		Name: "impact_of_change",
+		Description: "Packages affected by a change of a package: the packages importing it directly " +
+			"and through other packages, and the shortest import cycle it belongs to.",
+	}, s.impactOfChange)
+
+	return srv
+}
+
+// version of the binary serving the tools
+func version() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+
+	return "unknown"
+}
+
+// graph is the first-party dependency graph of dir and the analysis it was built from
+func (s *server) graph(ctx context.Context) (graph.Graph, analyzer.Result, error) {
+	r, err := dispatch.Dispatcher(s.opts...).AnalyzeAll(ctx, s.dir)
+	if err != nil {
+		return nil, analyzer.Result{}, err
+	}
+
+	return graph.New(r.Imports), r, nil
+}
+
+func knownPackage(pi analyzer.PackageImports, pkg string) error {
+	if _, ok := pi[analyzer.Package(pkg)]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownPackage, pkg)
+	}
+
+	return nil
+}
+
+type packageMetricsInput struct {
+	Package string `json:"package,omitempty" jsonschema:"only report this package, every package when empty"`
+}
+
+type packageMetricsOutput struct {
+	Packages []report.Package `json:"packages"`
+}
+
+func (s *server) packageMetrics(
+	ctx context.Context,
+	_ *sdk.CallToolRequest,
+	in packageMetricsInput,
+) (*sdk.CallToolResult, packageMetricsOutput, error) {
+	r, err := dispatch.Dispatcher(s.opts...).AnalyzeAll(ctx, s.dir)
+	if err != nil {
+		return nil, packageMetricsOutput{}, err
+	}
+
+	if in.Package != "" {
+		if err := knownPackage(r.Imports, in.Package); err != nil {
+			return nil, packageMetricsOutput{}, err
+		}
+	}
+
+	out := packageMetricsOutput{Packages: []report.Package{}}
+
+	for _, p := range report.New(r.Imports, r.Metrics, r.Origins, nil, r.Languages, r.Platforms, r.Tests).Packages {
+		if in.Package == "" || string(p.Package) == in.Package {
+			out.Packages = append(out.Packages, p)
+		}
+	}
+
+	return nil, out, nil
+}
+
+type dependencyPathInput struct {
+	From string `json:"from" jsonschema:"the importing package"`
+	To   string `json:"to"   jsonschema:"the imported package"`
+}
+
+type edge struct {
+	From analyzer.Package `json:"from"`
+	To   analyzer.Package `json:"to"`
+	// files and lines of the imports of To in From
+	Sources []analyzer.Location `json:"sources"`
+}
+
+type dependencyPathOutput struct {
+	// whether From depends on To
+	Found bool `json:"found"`
+	// e.g. [a b c] when a imports b and b imports c
+	Path  []analyzer.Package `json:"path"`
+	Edges []edge             `json:"edges"`
+}
+
+func (s *server) dependencyPath(
+	ctx context.Context,
+	_ *sdk.CallToolRequest,
+	in dependencyPathInput,
+) (*sdk.CallToolResult, dependencyPathOutput, error) {
+	g, r, err := s.graph(ctx)
+	if err != nil {
+		return nil, dependencyPathOutput{}, err
+	}
+
+	for _, pkg := range []string{in.From, in.To} {
+		if err := knownPackage(r.Imports, pkg); err != nil {
+			return nil, dependencyPathOutput{}, err
+		}
+	}
+
+	out := dependencyPathOutput{Path: []analyzer.Package{}, Edges: []edge{}}
+
+	path := g.ShortestPath(analyzer.Package(in.From), analyzer.Package(in.To))
+	if path == nil {
+		return nil, out, nil
+	}
+
+	out.Found = true
+	out.Path = path
+
+	for i := range len(path) - 1 {
+		e := edge{From: path[i], To: path[i+1], Sources: []analyzer.Location{}}
+
+		for imp, locations := range r.Sources[e.From] {
+			if imp.Package() == e.To {
+				e.Sources = append(e.Sources, locations...)
+			}
+		}
+
+		out.Edges = append(out.Edges, e)
+	}
+
+	return nil, out, nil
+}
+
+type cyclesInput struct {
+	MaxCycles int `json:"max_cycles,omitempty" jsonschema:"maximum number of cycles reported per component, 20 when unset"`
+}
+
+type component struct {
+	Packages []analyzer.Package `json:"packages"`
+	// every cycle is a chain of packages where the last package imports the first
+	Cycles []graph.Cycle `json:"cycles"`
+}
+
+type cyclesOutput struct {
+	Components []component `json:"components"`
+}
+
+func (s *server) cycles(
+	ctx context.Context,
+	_ *sdk.CallToolRequest,
+	in cyclesInput,
+) (*sdk.CallToolResult, cyclesOutput, error) {
+	g, _, err := s.graph(ctx)
+	if err != nil {
+		return nil, cyclesOutput{}, err
+	}
+
+	maxCycles := in.MaxCycles
+	if maxCycles <= 0 {
+		maxCycles = defaultMaxCycles
+	}
+
+	out := cyclesOutput{Components: []component{}}
+
+	for _, packages := range g.StronglyConnectedComponents() {
+		out.Components = append(out.Components, component{
+			Packages: packages,
+			Cycles:   g.Cycles(packages, maxCycles),
+		})
+	}
+
+	return nil, out, nil
+}
+
+type impactOfChangeInput struct {
+	Package string `json:"package" jsonschema:"the package about to change"`
+}
+
+type impactOfChangeOutput struct {
+	Package analyzer.Package `json:"package"`
+	// packages importing Package
+	Direct []analyzer.Package `json:"direct"`
+	// packages importing Package through other packages
+	Transitive []analyzer.Package `json:"transitive"`
+	// shortest import cycle through Package, empty when it is not part of a cycle
+	Cycle graph.Cycle `json:"cycle"`
+}
+
+func (s *server) impactOfChange(
+	ctx context.Context,
+	_ *sdk.CallToolRequest,
+	in impactOfChangeInput,
+) (*sdk.CallToolResult, impactOfChangeOutput, error) {
+	g, r, err := s.graph(ctx)
+	if err != nil {
+		return nil, impactOfChangeOutput{}, err
+	}
+
+	if err := knownPackage(r.Imports, in.Package); err != nil {
+		return nil, impactOfChangeOutput{}, err
+	}
+
+	pkg := analyzer.Package(in.Package)
+	direct, transitive := g.Dependents(pkg)
+
+	cycle := g.ShortestCycle(pkg)
+	if cycle == nil {
+		cycle = graph.Cycle{}
+	}
+
+	return nil, impactOfChangeOutput{
+		Package:    pkg,
+		Direct:     direct,
+		Transitive: transitive,
+		Cycle:      cycle,
+	}, nil
+}
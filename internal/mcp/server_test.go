@@ -0,0 +1,194 @@
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/mcp"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// project is a go module where api and store import each other
+var project = fstest.MapFS{
+	"go.mod":              {Data: []byte("module example.com/app\n\ngo 1.25\n")},
+	"cmd/main.go":         {Data: []byte("package main\n\nimport \"example.com/app/api\"\n\nfunc main() { api.Serve() }\n")},
+	"api/api.go":          {Data: []byte("package api\n\nimport \"example.com/app/store\"\n\nfunc Serve() { store.Open() }\n")},
+	"store/store.go":      {Data: []byte("package store\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/app/api\"\n)\n\nfunc Open() { fmt.Println(api.Serve) }\n")},
+	"internal/log/log.go": {Data: []byte("package log\n")},
+}
+
+// connect serves dir in process and returns the session of a client connected to it
+func connect(t *testing.T) *sdk.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := sdk.NewInMemoryTransports()
+
+	ss, err := mcp.NewServer(project).Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ss.Close() })
+
+	client := sdk.NewClient(&sdk.Implementation{Name: "test", Version: "v0.0.0"}, nil)
+
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cs.Close() })
+
+	return cs
+}
+
+func TestListTools(t *testing.T) {
+	t.Parallel()
+
+	cs := connect(t)
+
+	res, err := cs.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, tool := range res.Tools {
+		names = append(names, tool.Name)
+		require.NotNil(t, tool.OutputSchema, tool.Name)
+	}
+
+	require.ElementsMatch(t, []string{"package_metrics", "dependency_path", "cycles", "impact_of_change"}, names)
+}
+
+func TestTools(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		tool      string
+		arguments map[string]any
+		expected  string
+		expectErr string
+	}{
+		"dependency path": {
+			tool:      "dependency_path",
+			arguments: map[string]any{"from": "example.com/app/cmd/main", "to": "example.com/app/store"},
+			expected: `{
+				"found": true,
+				"path": ["example.com/app/cmd/main", "example.com/app/api", "example.com/app/store"],
+				"edges": [
+					{"from": "example.com/app/cmd/main", "to": "example.com/app/api", "sources": [{"file": "cmd/main.go", "line": 3}]},
+					{"from": "example.com/app/api", "to": "example.com/app/store", "sources": [{"file": "api/api.go", "line": 3}]}
+				]
+			}`,
+		},
+		"no dependency path": {
+			tool:      "dependency_path",
+			arguments: map[string]any{"from": "example.com/app/store", "to": "example.com/app/cmd/main"},
+			expected:  `{"found": false, "path": [], "edges": []}`,
+		},
+		"unknown package": {
+			tool:      "dependency_path",
+			arguments: map[string]any{"from": "example.com/app/cmd/main", "to": "example.com/app/web"},
+			expectErr: `unknown package: "example.com/app/web"`,
+		},
+		"cycles": {
+			tool:      "cycles",
+			arguments: map[string]any{},
+			expected: `{
+				"components": [{
+					"packages": ["example.com/app/api", "example.com/app/store"],
+					"cycles": [["example.com/app/api", "example.com/app/store"]]
+				}]
+			}`,
+		},
+		"impact of change": {
+			tool:      "impact_of_change",
+			arguments: map[string]any{"package": "example.com/app/store"},
+			expected: `{
+				"package": "example.com/app/store",
+				"direct": ["example.com/app/api"],
+				"transitive": ["example.com/app/cmd/main"],
+				"cycle": ["example.com/app/store", "example.com/app/api"]
+			}`,
+		},
+		"impact of change on a leaf": {
+			tool:      "impact_of_change",
+			arguments: map[string]any{"package": "example.com/app/internal/log"},
+			expected: `{
+				"package": "example.com/app/internal/log",
+				"direct": [],
+				"transitive": [],
+				"cycle": []
+			}`,
+		},
+	}
+
+	cs := connect(t)
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := cs.CallTool(context.Background(), &sdk.CallToolParams{
+				Name:      tt.tool,
+				Arguments: tt.arguments,
+			})
+			require.NoError(t, err)
+
+			if tt.expectErr != "" {
+				require.True(t, res.IsError)
+				require.Contains(t, res.Content[0].(*sdk.TextContent).Text, tt.expectErr)
+				return
+			}
+
+			require.False(t, res.IsError, res.Content)
+
+			got, err := json.Marshal(res.StructuredContent)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expected, string(got))
+		})
+	}
+}
+
+func TestPackageMetrics(t *testing.T) {
+	t.Parallel()
+
+	cs := connect(t)
+
+	res, err := cs.CallTool(context.Background(), &sdk.CallToolParams{
+		Name:      "package_metrics",
+		Arguments: map[string]any{"package": "example.com/app/store"},
+	})
+	require.NoError(t, err)
+	require.False(t, res.IsError, res.Content)
+
+	data, err := json.Marshal(res.StructuredContent)
+	require.NoError(t, err)
+
+	var got struct {
+		Packages []struct {
+			Package  string `json:"package"`
+			Language string `json:"language"`
+			Imports  []struct {
+				Path   string `json:"path"`
+				Origin string `json:"origin"`
+			} `json:"imports"`
+			Metrics struct {
+				Ca float64 `json:"ca"`
+				Ce float64 `json:"ce"`
+			} `json:"metrics"`
+		} `json:"packages"`
+	}
+	require.NoError(t, json.Unmarshal(data, &got))
+
+	require.Len(t, got.Packages, 1)
+	require.Equal(t, "example.com/app/store", got.Packages[0].Package)
+	require.Equal(t, "go", got.Packages[0].Language)
+	require.Len(t, got.Packages[0].Imports, 2)
+	require.Positive(t, got.Packages[0].Metrics.Ca)
+	require.Positive(t, got.Packages[0].Metrics.Ce)
+
+	res, err = cs.CallTool(context.Background(), &sdk.CallToolParams{
+		Name:      "package_metrics",
+		Arguments: map[string]any{},
+	})
+	require.NoError(t, err)
+	require.False(t, res.IsError, res.Content)
+}
@@ -0,0 +1,17 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// writeJSON encodes the report as indented JSON
+// maps are encoded with sorted keys by encoding/json so the output is stable
+// build constraints are kept readable e.g. "linux && amd64" rather than "linux \u0026\u0026 amd64"
+func (r Report) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+
+	return enc.Encode(r)
+}
@@ -0,0 +1,223 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+)
+
+// SchemaVersion is the version of the report schema
+// it is incremented whenever a field is removed or its meaning changes
+const SchemaVersion = 1
+
+type Format string
+
+const (
+	Text Format = "text"
+	JSON Format = "json"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported format")
+	ErrFormatRegistered  = errors.New("format already registered")
+)
+
+// Writer encodes a report in a format added with RegisterFormat
+type Writer func(w io.Writer, r Report) error
+
+var (
+	formatsMu sync.RWMutex
+	formats   = map[Format]Writer{}
+)
+
+// RegisterFormat adds an output format e.g. "sarif" which is then accepted by ParseFormat and Write
+// a format can only be registered once and text and json cannot be replaced
+func RegisterFormat(format Format, write Writer) error {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	if _, ok := formats[format]; ok || format == Text || format == JSON {
+		return fmt.Errorf("%w: %q", ErrFormatRegistered, format)
+	}
+
+	formats[format] = write
+
+	return nil
+}
+
+func registeredFormat(format Format) (Writer, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	write, ok := formats[format]
+
+	return write, ok
+}
+
+// ParseFormat validates a format given by the user
+func ParseFormat(format string) (Format, error) {
+	switch f := Format(format); f {
+	case Text, JSON:
+		return f, nil
+	}
+
+	if _, ok := registeredFormat(Format(format)); ok {
+		return Format(format), nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// Report is the stable representation of an analysis
+// packages and imports are sorted so the same input always produces the same output
+type Report struct {
+	Version  int       `json:"version"`
+	Packages []Package `json:"packages"`
+}
+
+type Package struct {
+	Package analyzer.Package `json:"package"`
+	// language of the package e.g. "go", empty when the analyzer does not tell
+	Language string   `json:"language,omitempty"`
+	Imports  []Import `json:"imports"`
+	Metrics  *Metrics `json:"metrics,omitempty"`
+}
+
+type Import struct {
+	Path    analyzer.Package    `json:"path"`
+	Origin  analyzer.Origin     `json:"origin,omitempty"`
+	Sources []analyzer.Location `json:"sources,omitempty"`
+	// build constraints of the files introducing the import when it is not introduced everywhere e.g. ["windows"]
+	Platforms []string `json:"platforms,omitempty"`
+	// whether the import is only introduced by the tests of the package e.g. testify
+	Test bool `json:"test,omitempty"`
+
+	// the import as reported by the analyzer e.g. `"fmt"`
+	raw analyzer.Import
+}
+
+type Metrics struct {
+	// Afferent coupling
+	Ca float64 `json:"ca"`
+	// Efferent coupling
+	Ce            float64 `json:"ce"`
+	Instability   float64 `json:"instability"`
+	Abstractness  float64 `json:"abstractness"`
+	Distance      float64 `json:"distance"`
+	AbstractTypes uint    `json:"abstractTypes"`
+	TotalTypes    uint    `json:"totalTypes"`
+	// symbol use counts per package e.g. {"fmt":{"fmt.Println":2}}
+	Inward  map[analyzer.Package]map[string]uint `json:"inward"`
+	Outward map[analyzer.Package]map[string]uint `json:"outward"`
+	// symbol use counts only introduced by tests, counted in no coupling
+	Tests map[analyzer.Package]map[string]uint `json:"tests,omitempty"`
+}
+
+// New builds a report out of the results of an analyzer
+// origins, sources, languages, platforms and tests are optional and may be nil
+func New(
+	pi analyzer.PackageImports,
+	metrics []analyzer.Metrics,
+	origins analyzer.ImportOrigins,
+	sources analyzer.ImportSources,
+	languages analyzer.PackageLanguages,
+	platforms analyzer.ImportPlatforms,
+	tests analyzer.TestImports,
+) Report {
+	packages := make(map[analyzer.Package]*Package, len(pi))
+
+	for pkg, imports := range pi {
+		p := &Package{
+			Package: pkg,
+			Imports: make([]Import, 0, len(imports)),
+		}
+
+		for _, i := range imports {
+			p.Imports = append(p.Imports, Import{
+				Path:      i.Package(),
+				Origin:    origins[pkg][i],
+				Sources:   sources[pkg][i],
+				Platforms: platforms[pkg][i],
+				Test:      slices.Contains(tests[pkg], i),
+				raw:       i,
+			})
+		}
+
+		slices.SortFunc(p.Imports, func(a, b Import) int {
+			return strings.Compare(string(a.Path), string(b.Path))
+		})
+
+		packages[pkg] = p
+	}
+
+	for _, m := range metrics {
+		p, ok := packages[m.Package]
+		if !ok {
+			p = &Package{Package: m.Package, Imports: []Import{}}
+			packages[m.Package] = p
+		}
+
+		p.Metrics = &Metrics{
+			Ca:            m.InwardCoupling(),
+			Ce:            m.OutwardCoupling(),
+			Instability:   m.Instability(),
+			Abstractness:  m.Abstractness(),
+			Distance:      m.Distance(),
+			AbstractTypes: m.AbstractTypes,
+			TotalTypes:    m.TotalTypes,
+			Inward:        symbolCounts(m.Inward),
+			Outward:       symbolCounts(m.Outward),
+			Tests:         symbolCounts(m.Tests),
+		}
+	}
+
+	r := Report{
+		Version:  SchemaVersion,
+		Packages: make([]Package, 0, len(packages)),
+	}
+
+	for _, p := range packages {
+		p.Language = languages[p.Package]
+		r.Packages = append(r.Packages, *p)
+	}
+
+	slices.SortFunc(r.Packages, func(a, b Package) int {
+		return strings.Compare(string(a.Package), string(b.Package))
+	})
+
+	return r
+}
+
+func symbolCounts(stats analyzer.PackageCouplingStats) map[analyzer.Package]map[string]uint {
+	counts := make(map[analyzer.Package]map[string]uint, len(stats))
+
+	for pkg, symbols := range stats {
+		counts[pkg] = make(map[string]uint, len(symbols))
+		for symbol, s := range symbols {
+			counts[pkg][symbol] = s.Count
+		}
+	}
+
+	return counts
+}
+
+// Write encodes the report in the given format
+func (r Report) Write(w io.Writer, format Format) error {
+	switch format {
+	case Text:
+		return r.writeText(w)
+	case JSON:
+		return r.writeJSON(w)
+	}
+
+	if write, ok := registeredFormat(format); ok {
+		return write(w, r)
+	}
+
+	return ErrUnsupportedFormat
+}
@@ -0,0 +1,225 @@
+package report_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/internal/report"
+	"github.com/stretchr/testify/require"
+)
+
+func testReport() report.Report {
+	pi := analyzer.PackageImports{
+		"example.com/b": {`"fmt"`},
+		"example.com/a": {`"fmt"`, `"example.com/b"`},
+	}
+
+	metrics := analyzer.BuildMetrics(map[analyzer.Package]analyzer.PackageCouplingStats{
+		"example.com/a": {
+			"fmt":           {"fmt.Println": {Count: 1}},
+			"example.com/b": {"b.Do": {Count: 2}},
+		},
+		"example.com/b": {
+			"fmt": {"fmt.Sprintf": {Count: 1}},
+		},
+	})
+
+	origins := analyzer.ImportOrigins{
+		"example.com/a": {`"fmt"`: analyzer.Std, `"example.com/b"`: analyzer.FirstParty},
+		"example.com/b": {`"fmt"`: analyzer.Std},
+	}
+
+	sources := analyzer.ImportSources{
+		"example.com/a": {
+			`"fmt"`:           {{File: "a/a.go", Line: 4}},
+			`"example.com/b"`: {{File: "a/a.go", Line: 6}},
+		},
+	}
+
+	return report.New(pi, metrics, origins, sources, nil, nil, nil)
+}
+
+func TestReportText(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testReport().Write(&buf, report.Text))
+
+	require.Equal(t, `Package: example.com/a imports
+	"example.com/b"	a/a.go:6
+	"fmt"	a/a.go:4
+Package: example.com/b imports
+	"fmt"
+Package: example.com/a	Ca: 0	Ce: 2	I: 1.00	A: 0.00	D: 0.00
+Package: example.com/b	Ca: 1	Ce: 1	I: 0.50	A: 0.00	D: 0.50
+`, buf.String())
+}
+
+func TestReportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testReport().Write(&buf, report.JSON))
+
+	require.JSONEq(t, `{
+  "version": 1,
+  "packages": [
+    {
+      "package": "example.com/a",
+      "imports": [
+        {"path": "example.com/b", "origin": "first-party", "sources": [{"file": "a/a.go", "line": 6}]},
+        {"path": "fmt", "origin": "std", "sources": [{"file": "a/a.go", "line": 4}]}
+      ],
+      "metrics": {
+        "ca": 0,
+        "ce": 2,
+        "instability": 1,
+        "abstractness": 0,
+        "distance": 0,
+        "abstractTypes": 0,
+        "totalTypes": 0,
+        "inward": {},
+        "outward": {"example.com/b": {"b.Do": 2}, "fmt": {"fmt.Println": 1}}
+      }
+    },
+    {
+      "package": "example.com/b",
+      "imports": [
+        {"path": "fmt", "origin": "std"}
+      ],
+      "metrics": {
+        "ca": 1,
+        "ce": 1,
+        "instability": 0.5,
+        "abstractness": 0,
+        "distance": 0.5,
+        "abstractTypes": 0,
+        "totalTypes": 0,
+        "inward": {"example.com/a": {"b.Do": 2}},
+        "outward": {"fmt": {"fmt.Sprintf": 1}}
+      }
+    }
+  ]
+}`, buf.String())
+}
+
+func TestReportLanguages(t *testing.T) {
+	pi := analyzer.PackageImports{
+		"example.com/a": {},
+		"shop.api":      {},
+	}
+
+	metrics := analyzer.BuildMetrics(map[analyzer.Package]analyzer.PackageCouplingStats{
+		"example.com/a": {},
+		"shop.api":      {},
+	})
+
+	languages := analyzer.PackageLanguages{
+		"example.com/a": "go",
+		"shop.api":      "python",
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, report.New(pi, metrics, nil, nil, languages, nil, nil).Write(&buf, report.Text))
+
+	require.Equal(t, `Package: example.com/a imports
+Package: shop.api imports
+Package: example.com/a	Language: go	Ca: 0	Ce: 0	I: 0.00	A: 0.00	D: 1.00
+Package: shop.api	Language: python	Ca: 0	Ce: 0	I: 0.00	A: 0.00	D: 1.00
+`, buf.String())
+}
+
+func TestReportPlatforms(t *testing.T) {
+	pi := analyzer.PackageImports{
+		"example.com/fs": {`"io/fs"`, `"syscall"`, `"golang.org/x/sys/unix"`},
+	}
+
+	platforms := analyzer.ImportPlatforms{
+		"example.com/fs": {
+			`"syscall"`:               {"windows"},
+			`"golang.org/x/sys/unix"`: {"linux && amd64", "unix"},
+		},
+	}
+
+	r := report.New(pi, nil, nil, nil, nil, platforms, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.Write(&buf, report.Text))
+
+	require.Equal(t, `Package: example.com/fs imports
+	"golang.org/x/sys/unix" [linux && amd64 | unix]
+	"io/fs"
+	"syscall" [windows]
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, r.Write(&buf, report.JSON))
+	require.Contains(t, buf.String(), `"platforms": [
+            "linux && amd64",
+            "unix"
+          ]`)
+}
+
+func TestReportTests(t *testing.T) {
+	pi := analyzer.PackageImports{
+		"example.com/store":      {`"database/sql"`, `"testing"`},
+		"example.com/store_test": {`"example.com/store"`, `"testing"`},
+	}
+
+	tests := analyzer.TestImports{
+		"example.com/store":      {`"testing"`},
+		"example.com/store_test": {`"example.com/store"`, `"testing"`},
+	}
+
+	r := report.New(pi, nil, nil, nil, nil, nil, tests)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.Write(&buf, report.Text))
+
+	require.Equal(t, `Package: example.com/store imports
+	"database/sql"
+	"testing" (test)
+Package: example.com/store_test imports
+	"example.com/store" (test)
+	"testing" (test)
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, r.Write(&buf, report.JSON))
+	require.Contains(t, buf.String(), `"path": "testing",
+          "test": true`)
+	require.NotContains(t, buf.String(), `"path": "database/sql",
+          "test"`)
+}
+
+func TestRegisterFormat(t *testing.T) {
+	csv := report.Format("csv")
+
+	require.NoError(t, report.RegisterFormat(csv, func(w io.Writer, r report.Report) error {
+		for _, p := range r.Packages {
+			if _, err := fmt.Fprintf(w, "%s,%d\n", p.Package, len(p.Imports)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}))
+
+	require.ErrorIs(t, report.RegisterFormat(csv, nil), report.ErrFormatRegistered)
+	require.ErrorIs(t, report.RegisterFormat(report.JSON, nil), report.ErrFormatRegistered)
+
+	f, err := report.ParseFormat("csv")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, testReport().Write(&buf, f))
+	require.Equal(t, "example.com/a,2\nexample.com/b,1\n", buf.String())
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := report.ParseFormat("json")
+	require.NoError(t, err)
+	require.Equal(t, report.JSON, f)
+
+	_, err = report.ParseFormat("yaml")
+	require.ErrorIs(t, err, report.ErrUnsupportedFormat)
+}
@@ -0,0 +1,69 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func (r Report) writeText(w io.Writer) error {
+	for _, p := range r.Packages {
+		if _, err := fmt.Fprintf(w, "Package: %v imports\n", p.Package); err != nil {
+			return err
+		}
+
+		for _, i := range p.Imports {
+			line := "\t" + string(i.raw)
+
+			if len(i.Platforms) > 0 {
+				line += " [" + strings.Join(i.Platforms, " | ") + "]"
+			}
+
+			if i.Test {
+				line += " (test)"
+			}
+
+			if len(i.Sources) > 0 {
+				files := make([]string, 0, len(i.Sources))
+				for _, l := range i.Sources {
+					files = append(files, l.String())
+				}
+
+				line += "\t" + strings.Join(files, ", ")
+			}
+
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, p := range r.Packages {
+		m := p.Metrics
+		if m == nil {
+			continue
+		}
+
+		// the language column is only shown when the analyzer tells the language
+		language := ""
+		if p.Language != "" {
+			language = "\tLanguage: " + p.Language
+		}
+
+		if _, err := fmt.Fprintf(
+			w,
+			"Package: %v%s\tCa: %v\tCe: %v\tI: %.2f\tA: %.2f\tD: %.2f\n",
+			p.Package,
+			language,
+			m.Ca,
+			m.Ce,
+			m.Instability,
+			m.Abstractness,
+			m.Distance,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,417 @@
+package ts
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// DefaultMaxAge is how long an entry no run read is kept by AutoPrune
+const DefaultMaxAge = 30 * 24 * time.Hour
+
+// pruneInterval is how often AutoPrune walks the cache directory
+const pruneInterval = 24 * time.Hour
+
+// Cache stores what the analyzers extracted from every file on disk so unchanged files are not parsed again
+// the modification time of an entry is the last time a run read it, removing the directory is always safe
+type Cache struct {
+	dir string
+	// version of the binary, entries of other builds are never read
+	build string
+}
+
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir, build: buildVersion()}
+}
+
+// buildVersion identifies the code extracting the entries
+// a release or a clean checkout is identified by its version and revision, any other build by the hash
+// of its executable so that a change of the extraction code never reads the entries of the previous code
+var buildVersion = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return executableHash()
+	}
+
+	version := info.Main.Version
+	revision, modified := "", false
+
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+
+	switch {
+	case revision != "" && !modified:
+		return version + "+" + revision
+	case version != "" && version != "(devel)":
+		return version
+	}
+
+	return executableHash()
+})
+
+// executableHash is the hash of the running binary, "unknown" disables nothing but reuse across builds
+func executableHash() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "unknown"
+	}
+
+	f, err := os.Open(exe)
+	if err != nil {
+		return "unknown"
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "unknown"
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DefaultCacheDir is uda in the user cache directory e.g. $XDG_CACHE_HOME/uda or ~/Library/Caches/uda
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "uda"), nil
+}
+
+type cacheKey struct{}
+
+// WithCache makes the analyzers run with ctx read and store what they extract in c
+func WithCache(ctx context.Context, c *Cache) context.Context {
+	return context.WithValue(ctx, cacheKey{}, c)
+}
+
+func cacheOf(ctx context.Context) *Cache {
+	c, _ := ctx.Value(cacheKey{}).(*Cache)
+	return c
+}
+
+// key identifies what was extracted from the text of a file with a grammar and a query
+// schema is the shape of the extracted value so a change of the value never decodes a previous entry
+func (c *Cache) key(language *treesitter.Language, query, path, salt, schema string, text []byte) string {
+	h := sha256.New()
+
+	for _, field := range []string{
+		schema,
+		c.build,
+		grammarVersion(language),
+		query,
+		path,
+		salt,
+		string(text),
+	} {
+		// length prefixed so that no two different sets of fields hash the same
+		_ = binary.Write(h, binary.LittleEndian, uint64(len(field)))
+		h.Write([]byte(field))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// grammarVersion identifies a grammar, tree-sitter grammars do not all report their version
+// but a change of a grammar changes its node kinds or parse states
+func grammarVersion(language *treesitter.Language) string {
+	return fmt.Sprintf(
+		"abi%d-kinds%d-fields%d-states%d",
+		language.AbiVersion(),
+		language.NodeKindCount(),
+		language.FieldCount(),
+		language.ParseStateCount(),
+	)
+}
+
+// schemaOf describes the fields of t and of the types it is made of
+// e.g. "struct{path string;imports []string}" so that adding, removing or retyping a field changes it
+func schemaOf(t reflect.Type) string {
+	if s, ok := schemas.Load(t); ok {
+		return s.(string)
+	}
+
+	var b strings.Builder
+	writeSchema(&b, t, map[reflect.Type]bool{})
+
+	s, _ := schemas.LoadOrStore(t, b.String())
+
+	return s.(string)
+}
+
+// schemas are described once per type rather than once per file
+var schemas sync.Map
+
+func writeSchema(b *strings.Builder, t reflect.Type, seen map[reflect.Type]bool) {
+	// recursive types e.g. a tree of nodes are described once
+	if seen[t] {
+		b.WriteString(t.String())
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		seen[t] = true
+
+		b.WriteString("struct{")
+		for i := range t.NumField() {
+			f := t.Field(i)
+			b.WriteString(f.Name + " ")
+			writeSchema(b, f.Type, seen)
+			b.WriteString(";")
+		}
+		b.WriteString("}")
+	case reflect.Pointer:
+		b.WriteString("*")
+		writeSchema(b, t.Elem(), seen)
+	case reflect.Slice:
+		b.WriteString("[]")
+		writeSchema(b, t.Elem(), seen)
+	case reflect.Array:
+		fmt.Fprintf(b, "[%d]", t.Len())
+		writeSchema(b, t.Elem(), seen)
+	case reflect.Map:
+		b.WriteString("map[")
+		writeSchema(b, t.Key(), seen)
+		b.WriteString("]")
+		writeSchema(b, t.Elem(), seen)
+	default:
+		b.WriteString(t.Kind().String())
+	}
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+// get decodes the entry of key into v, a missing or unreadable entry is a miss
+func (c *Cache) get(ctx context.Context, key string, v any) bool {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return false
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(v); err != nil {
+		slog.DebugContext(ctx, "ignoring cache entry", "key", key, "error", err)
+		return false
+	}
+
+	// the entry was used by this run so it is not pruned
+	now := time.Now()
+	_ = os.Chtimes(c.path(key), now, now)
+
+	return true
+}
+
+// put stores v as the entry of key, a failure only costs parsing the file again on the next run
+func (c *Cache) put(ctx context.Context, key string, v any) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		slog.WarnContext(ctx, "failed to encode cache entry", "error", err)
+		return
+	}
+
+	p := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		slog.WarnContext(ctx, "failed to create cache directory", "error", err)
+		return
+	}
+
+	// written next to the entry then renamed so a concurrent run never reads a partial entry
+	f, err := os.CreateTemp(filepath.Dir(p), key+".*")
+	if err != nil {
+		slog.WarnContext(ctx, "failed to store cache entry", "error", err)
+		return
+	}
+
+	_, err = f.Write(buf.Bytes())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), p)
+	}
+
+	if err != nil {
+		_ = os.Remove(f.Name())
+		slog.WarnContext(ctx, "failed to store cache entry", "error", err)
+	}
+}
+
+// Prune removes the entries no run read for longer than maxAge and returns how many were removed
+func (c *Cache) Prune(maxAge time.Duration) (int, error) {
+	deadline := time.Now().Add(-maxAge)
+	removed := 0
+
+	err := filepath.WalkDir(c.dir, func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		if err != nil || d.IsDir() || d.Name() == pruneMarker {
+			return err
+		}
+
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if info.ModTime().After(deadline) {
+			return nil
+		}
+
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		removed++
+
+		return nil
+	})
+
+	return removed, err
+}
+
+// pruneMarker is the file whose modification time is the last time AutoPrune walked the cache directory
+const pruneMarker = "pruned"
+
+// AutoPrune removes the entries no run read for DefaultMaxAge at most once per day
+// so that a cache filled on every commit does not grow without bound
+func (c *Cache) AutoPrune(ctx context.Context) {
+	marker := filepath.Join(c.dir, pruneMarker)
+
+	if info, err := os.Stat(marker); err == nil && time.Since(info.ModTime()) < pruneInterval {
+		return
+	}
+
+	removed, err := c.Prune(DefaultMaxAge)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to prune the parse cache", "dir", c.dir, "error", err)
+		return
+	}
+
+	slog.DebugContext(ctx, "pruned the parse cache", "dir", c.dir, "removed", removed)
+
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return
+	}
+
+	_ = os.WriteFile(marker, nil, 0o644)
+}
+
+// Clean removes every entry of the cache
+func (c *Cache) Clean() error {
+	return os.RemoveAll(c.dir)
+}
+
+// Digest summarizes the values a result of Extract depends on as its salt
+// maps are printed sorted so equal values always have the same digest
+func Digest(values ...any) string {
+	h := sha256.New()
+	for _, v := range values {
+		fmt.Fprintf(h, "%v\n", v)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Extract parses a file of dir, runs query on its tree and returns what extract extracted from the matches
+// with a cache in ctx the file is only parsed when its content, the grammar, the query or salt changed
+// salt is everything else the result depends on e.g. the module path of a go file
+// R is stored with encoding/gob
+func Extract[R any](
+	ctx context.Context,
+	w *Worker,
+	dir fs.FS,
+	path string,
+	query string,
+	salt string,
+	extract func(q *treesitter.Query, matches treesitter.QueryMatches, text []byte) (R, error),
+) (R, error) {
+	return ExtractValid(ctx, w, dir, path, query, salt, nil, extract)
+}
+
+// ExtractValid is Extract for results depending on more than salt can tell up front
+// e.g. the modules a python file resolved its imports against, a cached result is only used when valid accepts it
+func ExtractValid[R any](
+	ctx context.Context,
+	w *Worker,
+	dir fs.FS,
+	path string,
+	query string,
+	salt string,
+	valid func(R) bool,
+	extract func(q *treesitter.Query, matches treesitter.QueryMatches, text []byte) (R, error),
+) (R, error) {
+	var zero R
+
+	text, err := fs.ReadFile(dir, path)
+	if err != nil {
+		return zero, err
+	}
+
+	c := cacheOf(ctx)
+
+	var key string
+	if c != nil {
+		key = c.key(w.language, query, path, salt, schemaOf(reflect.TypeFor[R]()), text)
+
+		var r R
+		if c.get(ctx, key, &r) && (valid == nil || valid(r)) {
+			return r, nil
+		}
+	}
+
+	tree, err := parseText(ctx, w.parser, path, text)
+	if err != nil {
+		return zero, err
+	}
+	defer tree.Close()
+
+	q, matches, err := w.Query(ctx, tree, text, query)
+	if err != nil {
+		return zero, err
+	}
+
+	r, err := extract(q, matches, text)
+	if err != nil {
+		return zero, err
+	}
+
+	if c != nil {
+		c.put(ctx, key, r)
+	}
+
+	return r, nil
+}
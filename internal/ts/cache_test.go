@@ -0,0 +1,191 @@
+package ts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+	tsgo "github.com/tree-sitter/tree-sitter-go/bindings/go"
+)
+
+func TestExtract(t *testing.T) {
+	t.Parallel()
+
+	const (
+		packageQuery = "(package_clause (package_identifier) @package)"
+		importQuery  = "(import_spec path: (interpreted_string_literal) @import)"
+	)
+
+	type run struct {
+		content string
+		query   string
+		salt    string
+		// whether a cached result is rejected
+		invalid bool
+		// whether the file was parsed rather than read from the cache
+		parsed bool
+	}
+
+	tests := map[string]struct {
+		cache bool
+		runs  []run
+	}{
+		"unchanged": {
+			cache: true,
+			runs: []run{
+				{content: "package api\n", query: packageQuery, parsed: true},
+				{content: "package api\n", query: packageQuery, parsed: false},
+			},
+		},
+		"content changed": {
+			cache: true,
+			runs: []run{
+				{content: "package api\n", query: packageQuery, parsed: true},
+				{content: "package web\n", query: packageQuery, parsed: true},
+				{content: "package api\n", query: packageQuery, parsed: false},
+			},
+		},
+		"query changed": {
+			cache: true,
+			runs: []run{
+				{content: "package api\n", query: packageQuery, parsed: true},
+				{content: "package api\n", query: importQuery, parsed: true},
+			},
+		},
+		"salt changed": {
+			cache: true,
+			runs: []run{
+				{content: "package api\n", query: packageQuery, salt: "a", parsed: true},
+				{content: "package api\n", query: packageQuery, salt: "b", parsed: true},
+			},
+		},
+		"invalid": {
+			cache: true,
+			runs: []run{
+				{content: "package api\n", query: packageQuery, parsed: true},
+				{content: "package api\n", query: packageQuery, invalid: true, parsed: true},
+			},
+		},
+		"no cache": {
+			runs: []run{
+				{content: "package api\n", query: packageQuery, parsed: true},
+				{content: "package api\n", query: packageQuery, parsed: true},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			if tt.cache {
+				ctx = WithCache(ctx, NewCache(t.TempDir()))
+			}
+
+			w, err := NewWorker(treesitter.NewLanguage(tsgo.Language()))
+			require.NoError(t, err)
+			defer w.Close()
+
+			for i, r := range tt.runs {
+				dir := fstest.MapFS{"api/api.go": {Data: []byte(r.content)}}
+				parsed := false
+
+				got, err := ExtractValid(
+					ctx,
+					w,
+					dir,
+					"api/api.go",
+					r.query,
+					r.salt,
+					func([]string) bool { return !r.invalid },
+					func(q *treesitter.Query, matches treesitter.QueryMatches, text []byte) ([]string, error) {
+						parsed = true
+						captures := []string{}
+
+						for match := matches.Next(); match != nil; match = matches.Next() {
+							for _, capture := range match.Captures {
+								captures = append(captures, capture.Node.Utf8Text(text))
+							}
+						}
+
+						return captures, nil
+					},
+				)
+				require.NoError(t, err)
+				require.Equal(t, r.parsed, parsed, "run %d", i)
+
+				if r.query == packageQuery {
+					require.Equal(t, []string{r.content[len("package ") : len(r.content)-1]}, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaOf(t *testing.T) {
+	t.Parallel()
+
+	type entry struct {
+		Path    string
+		Imports []string
+	}
+
+	type renamed struct {
+		Path  string
+		Names []string
+	}
+
+	type retyped struct {
+		Path    string
+		Imports map[string]uint
+	}
+
+	type node struct {
+		Children []*node
+	}
+
+	require.Equal(t, "struct{Path string;Imports []string;}", schemaOf(reflect.TypeFor[entry]()))
+	require.NotEqual(t, schemaOf(reflect.TypeFor[entry]()), schemaOf(reflect.TypeFor[renamed]()))
+	require.NotEqual(t, schemaOf(reflect.TypeFor[entry]()), schemaOf(reflect.TypeFor[retyped]()))
+	require.NotEmpty(t, schemaOf(reflect.TypeFor[node]()))
+}
+
+func TestPrune(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	c := NewCache(dir)
+
+	for _, name := range []string{"ab/used", "ab/stale", "cd/stale"} {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, nil, 0o644))
+	}
+
+	old := time.Now().Add(-2 * DefaultMaxAge)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "ab/stale"), old, old))
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "cd/stale"), old, old))
+
+	removed, err := c.Prune(DefaultMaxAge)
+	require.NoError(t, err)
+	require.Equal(t, 2, removed)
+	require.FileExists(t, filepath.Join(dir, "ab/used"))
+	require.NoFileExists(t, filepath.Join(dir, "ab/stale"))
+
+	c.AutoPrune(context.Background())
+	require.FileExists(t, filepath.Join(dir, pruneMarker))
+
+	require.NoError(t, c.Clean())
+	require.NoDirExists(t, dir)
+
+	removed, err = c.Prune(DefaultMaxAge)
+	require.NoError(t, err)
+	require.Zero(t, removed)
+}
@@ -1,7 +1,5 @@
 package ts
 
-import "errors"
-
 type LanguageID string
 
 const (
@@ -13,20 +11,3 @@ const (
 	TS     LanguageID = "typescript"
 	TSX    LanguageID = "tsx"
 )
-
-var langs = map[LanguageID]struct{}{
-	GO:     {},
-	JS:     {},
-	JSX:    {},
-	PYTHON: {},
-	RUST:   {},
-	TS:     {},
-	TSX:    {},
-}
-
-func isLangSupported(lang LanguageID) bool {
-	_, ok := langs[lang]
-	return ok
-}
-
-var ErrLangNotSupported = errors.New("language not supported")
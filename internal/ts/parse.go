@@ -2,12 +2,16 @@ package ts
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 
 	treesitter "github.com/tree-sitter/go-tree-sitter"
 )
 
+var ErrParse = errors.New("failed to parse")
+
 func Parse(
 	ctx context.Context,
 	psr *treesitter.Parser,
@@ -18,14 +22,29 @@ func Parse(
 	if err != nil {
 		return nil, nil, err
 	}
+	defer f.Close()
 
 	text, err := io.ReadAll(f)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	tree, err := parseText(ctx, psr, path, text)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tree, text, nil
+}
+
+func parseText(
+	ctx context.Context,
+	psr *treesitter.Parser,
+	path string,
+	text []byte,
+) (*treesitter.Tree, error) {
 	length := len(text)
-	return psr.ParseWithOptions(
+	tree := psr.ParseWithOptions(
 		func(i int, _ treesitter.Point) []byte {
 			if i < length {
 				return text[i:]
@@ -43,5 +62,15 @@ func Parse(
 				}
 			},
 		},
-	), text, nil
+	)
+	if tree == nil {
+		// the progress callback stopped the parser
+		if err := context.Cause(ctx); err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("%w: %s", ErrParse, path)
+	}
+
+	return tree, nil
 }
@@ -0,0 +1,153 @@
+package ts
+
+import (
+	"context"
+	"io/fs"
+	"runtime"
+	"sync"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+type jobsKey struct{}
+
+// WithJobs sets how many files the analyzers run with ctx parse at once, GOMAXPROCS when jobs < 1
+func WithJobs(ctx context.Context, jobs int) context.Context {
+	return context.WithValue(ctx, jobsKey{}, jobs)
+}
+
+// Jobs returns how many files are parsed at once, GOMAXPROCS unless set with WithJobs
+func Jobs(ctx context.Context) int {
+	if jobs, ok := ctx.Value(jobsKey{}).(int); ok && jobs > 0 {
+		return jobs
+	}
+
+	return runtime.GOMAXPROCS(0)
+}
+
+// Worker parses and queries files of a single grammar
+// a worker is owned by a single goroutine as neither parsers nor query cursors are safe for concurrent use
+type Worker struct {
+	language *treesitter.Language
+	parser   *treesitter.Parser
+	cursor   *treesitter.QueryCursor
+	// compiled queries by source
+	queries map[string]*treesitter.Query
+}
+
+func NewWorker(language *treesitter.Language) (*Worker, error) {
+	parser := treesitter.NewParser()
+	if err := parser.SetLanguage(language); err != nil {
+		parser.Close()
+		return nil, err
+	}
+
+	return &Worker{
+		language: language,
+		parser:   parser,
+		cursor:   treesitter.NewQueryCursor(),
+		queries:  make(map[string]*treesitter.Query),
+	}, nil
+}
+
+// Parse parses a file of dir, the tree is closed by the caller
+func (w *Worker) Parse(
+	ctx context.Context,
+	dir fs.FS,
+	path string,
+) (*treesitter.Tree, []byte, error) {
+	return Parse(ctx, w.parser, dir, path)
+}
+
+// Query runs query on tree with the cursor of the worker, the query is compiled once per worker
+// the matches are valid until the next call to Query
+func (w *Worker) Query(
+	ctx context.Context,
+	tree *treesitter.Tree,
+	text []byte,
+	query string,
+) (*treesitter.Query, treesitter.QueryMatches, error) {
+	q, ok := w.queries[query]
+	if !ok {
+		var err error
+
+		q, err = Query(ctx, w.language, query)
+		if err != nil {
+			return nil, treesitter.QueryMatches{}, err
+		}
+
+		w.queries[query] = q
+	}
+
+	return q, w.cursor.Matches(q, tree.RootNode(), text), nil
+}
+
+func (w *Worker) Close() {
+	for _, q := range w.queries {
+		q.Close()
+	}
+
+	w.cursor.Close()
+	w.parser.Close()
+}
+
+// Map calls fn for every item with Jobs(ctx) workers, each worker owns the W created by newWorker
+// results are in the order of items whatever the order they were processed in
+// the first error cancels the remaining items and is returned
+func Map[T any, W interface{ Close() }, R any](
+	ctx context.Context,
+	items []T,
+	newWorker func() (W, error),
+	fn func(ctx context.Context, w W, item T) (R, error),
+) ([]R, error) {
+	results := make([]R, len(items))
+	if len(items) == 0 {
+		return results, nil
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for range min(Jobs(ctx), len(items)) {
+		wg.Go(func() {
+			w, err := newWorker()
+			if err != nil {
+				cancel(err)
+				return
+			}
+			defer w.Close()
+
+			for i := range indexes {
+				r, err := fn(ctx, w, items[i])
+				if err != nil {
+					cancel(err)
+					return
+				}
+
+				results[i] = r
+			}
+		})
+	}
+
+feed:
+	for i := range items {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,117 @@
+package ts
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// counter counts the workers that are still open
+type counter struct {
+	open *atomic.Int32
+}
+
+func (c counter) Close() {
+	c.open.Add(-1)
+}
+
+func TestMap(t *testing.T) {
+	t.Parallel()
+
+	errOdd := errors.New("odd")
+
+	tests := map[string]struct {
+		jobs      int
+		items     []int
+		fail      bool
+		expected  []string
+		expectErr error
+	}{
+		"no items": {
+			jobs:     4,
+			items:    []int{},
+			expected: []string{},
+		},
+		"single worker": {
+			jobs:     1,
+			items:    []int{2, 4, 6},
+			expected: []string{"2", "4", "6"},
+		},
+		"results keep the order of items": {
+			jobs:     3,
+			items:    []int{8, 6, 4, 2, 0, 10, 12, 14, 16, 18},
+			expected: []string{"8", "6", "4", "2", "0", "10", "12", "14", "16", "18"},
+		},
+		"first error": {
+			jobs:      3,
+			items:     []int{2, 4, 5, 6},
+			fail:      true,
+			expectErr: errOdd,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := WithJobs(context.Background(), tt.jobs)
+			open := &atomic.Int32{}
+
+			got, err := Map(
+				ctx,
+				tt.items,
+				func() (counter, error) {
+					open.Add(1)
+					return counter{open: open}, nil
+				},
+				func(_ context.Context, _ counter, item int) (string, error) {
+					if tt.fail && item%2 == 1 {
+						return "", errOdd
+					}
+
+					return strconv.Itoa(item), nil
+				},
+			)
+
+			require.Zero(t, open.Load(), "every worker is closed")
+
+			if tt.expectErr != nil {
+				require.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestMapCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Map(
+		ctx,
+		[]int{1, 2, 3},
+		func() (counter, error) { return counter{open: &atomic.Int32{}}, nil },
+		func(ctx context.Context, _ counter, item int) (int, error) {
+			return item, ctx.Err()
+		},
+	)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestJobs(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, runtime.GOMAXPROCS(0), Jobs(context.Background()))
+	require.Equal(t, runtime.GOMAXPROCS(0), Jobs(WithJobs(context.Background(), 0)))
+	require.Equal(t, 3, Jobs(WithJobs(context.Background(), 3)))
+}
@@ -0,0 +1,173 @@
+package ts
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// GOMOD is the grammar of go.mod files, it is not an analyzed language
+const GOMOD LanguageID = "gomod"
+
+// QueryName names a query of a language, stored as queries/<language>/<name>.scm
+type QueryName string
+
+const (
+	// AnalyzeQuery extracts the package, imports, qualified uses and declarations of a file
+	AnalyzeQuery QueryName = "analyze"
+	// ModuleQuery extracts the module path of a go.mod file
+	ModuleQuery QueryName = "module"
+	// ModulesQuery extracts the module declarations of a rust file
+	ModulesQuery QueryName = "modules"
+)
+
+//go:embed queries
+var embedded embed.FS
+
+// contract is the capture-name contract of every query, analyzers ignore any other capture
+// the meaning of each capture is documented in the header of the embedded .scm file
+var contract = map[LanguageID]map[QueryName][]string{
+	GO: {
+		AnalyzeQuery: {
+			"package",
+			"import",
+			"alias",
+			"import_type_use",
+			"import_func_use",
+			"type_declaration",
+			"abstract_type_declaration",
+		},
+	},
+	GOMOD: {
+		ModuleQuery: {"module_path"},
+	},
+	PYTHON: {
+		AnalyzeQuery: {"import", "import_from", "attribute_use", "type_declaration"},
+	},
+	JS: {
+		AnalyzeQuery: {
+			"import",
+			"export_from",
+			"dynamic_import",
+			"require_call",
+			"member_use",
+			"type_declaration",
+		},
+	},
+	TS: {
+		AnalyzeQuery: {
+			"import",
+			"export_from",
+			"dynamic_import",
+			"require_call",
+			"member_use",
+			"type_use",
+			"type_declaration",
+			"abstract_type_declaration",
+		},
+	},
+	RUST: {
+		AnalyzeQuery: {
+			"use",
+			"extern_crate",
+			"path_use",
+			"type_declaration",
+			"abstract_type_declaration",
+		},
+		ModulesQuery: {"module"},
+	},
+}
+
+var (
+	ErrQueryNotFound  = errors.New("query not found")
+	ErrUnknownCapture = errors.New("capture not part of the query contract")
+)
+
+var (
+	queryDirMu sync.RWMutex
+	queryDir   fs.FS
+)
+
+// SetQueryDir overrides the embedded queries with the .scm files of dir laid out as <language>/<name>.scm
+// e.g. with os.DirFS("/home/me/.uda/queries") go/analyze.scm replaces the embedded go analyze query
+// queries missing from dir keep their embedded version, a nil dir restores every embedded query
+func SetQueryDir(dir fs.FS) {
+	queryDirMu.Lock()
+	defer queryDirMu.Unlock()
+
+	queryDir = dir
+}
+
+// LoadQuery returns the query of a language, from the query directory when it overrides it
+// an overriding query may only use the captures of the contract of the embedded query
+func LoadQuery(lang LanguageID, name QueryName) (string, error) {
+	captures, ok := contract[lang][name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s/%s", ErrQueryNotFound, lang, name)
+	}
+
+	p := path.Join(string(lang), string(name)+".scm")
+
+	queryDirMu.RLock()
+	dir := queryDir
+	queryDirMu.RUnlock()
+
+	if dir != nil {
+		query, err := fs.ReadFile(dir, p)
+		switch {
+		case err == nil:
+			if err := checkCaptures(string(query), captures); err != nil {
+				return "", fmt.Errorf("%s: %w", p, err)
+			}
+
+			slog.Debug("query overridden", "query", p)
+			return string(query), nil
+		case !errors.Is(err, fs.ErrNotExist):
+			return "", err
+		}
+	}
+
+	query, err := embedded.ReadFile(path.Join("queries", p))
+	if err != nil {
+		return "", err
+	}
+
+	return string(query), nil
+}
+
+var (
+	// captureRe matches a capture e.g. @import
+	captureRe = regexp.MustCompile(`@[A-Za-z_][A-Za-z0-9_.]*`)
+	// stringRe matches a string of a predicate e.g. "^test_" of (#match? @_name "^test_")
+	stringRe = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+)
+
+// checkCaptures rejects the captures of query outside of captures
+// captures starting with _ are private to the query e.g. the operand of a #eq? predicate
+func checkCaptures(query string, captures []string) error {
+	for line := range strings.Lines(query) {
+		// strings and comments may contain @ e.g. ; @import is an import path
+		line = stringRe.ReplaceAllString(line, `""`)
+		if i := strings.Index(line, ";"); i >= 0 {
+			line = line[:i]
+		}
+
+		for _, capture := range captureRe.FindAllString(line, -1) {
+			name := strings.TrimPrefix(capture, "@")
+			if strings.HasPrefix(name, "_") || slices.Contains(captures, name) {
+				continue
+			}
+
+			return fmt.Errorf("%w: @%s", ErrUnknownCapture, name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+; packages, imports and qualified identifiers of a .go file
+;
+; @package                    name of the package clause
+; @import                     import path including its quotes e.g. "fmt"
+; @alias                      name of an aliased import e.g. f of import f "fmt", _ or . of blank and dot imports
+; @import_type_use            qualified type e.g. analyzer.Package
+; @import_func_use            selector on an identifier e.g. fmt.Println
+; @type_declaration           top level type declaration
+; @abstract_type_declaration  top level interface declaration
+
+(package_clause (package_identifier) @package)
+(import_spec
+  path: (interpreted_string_literal) @import)
+(import_spec
+  name: [(package_identifier) (blank_identifier) (dot)] @alias
+  path: (interpreted_string_literal))
+(qualified_type
+  package: (package_identifier)) @import_type_use
+(selector_expression
+  operand: (identifier)
+  field: (field_identifier)) @import_func_use
+(source_file
+  (type_declaration
+    (type_spec) @type_declaration))
+(source_file
+  (type_declaration
+    (type_spec
+      type: (interface_type)) @abstract_type_declaration))
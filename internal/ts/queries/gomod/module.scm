@@ -0,0 +1,5 @@
+; module path of a go.mod file
+;
+; @module_path  path of the module directive e.g. github.com/flamingoosesoftwareinc/uda
+
+(module_directive (module_path) @module_path)
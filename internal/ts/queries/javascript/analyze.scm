@@ -0,0 +1,25 @@
+; imports and member references of a .js, .jsx, .mjs or .cjs file
+;
+; @import            import statement e.g. import { Button } from "@acme/ui"
+; @export_from       re-export e.g. export * from "./db"
+; @dynamic_import    import() call with a literal specifier
+; @require_call      call of an identifier with a literal first argument e.g. require("./db")
+; @member_use        member of an identifier e.g. ui.Button
+; @type_declaration  top level class declaration, exported or not
+
+(import_statement) @import
+(export_statement
+  source: (string)) @export_from
+(call_expression
+  function: (import)
+  arguments: (arguments . (string))) @dynamic_import
+(call_expression
+  function: (identifier)
+  arguments: (arguments . (string))) @require_call
+(member_expression
+  object: (identifier)) @member_use
+(program
+  (class_declaration) @type_declaration)
+(program
+  (export_statement
+    declaration: (class_declaration) @type_declaration))
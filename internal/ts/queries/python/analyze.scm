@@ -0,0 +1,15 @@
+; imports and attribute references of a .py file
+;
+; @import            import statement e.g. import os.path as p
+; @import_from       from import statement e.g. from .models import User
+; @attribute_use     attribute reference e.g. os.path or models.User
+; @type_declaration  top level class definition, decorated or not
+
+(import_statement) @import
+(import_from_statement) @import_from
+(attribute) @attribute_use
+(module
+  (class_definition) @type_declaration)
+(module
+  (decorated_definition
+    definition: (class_definition) @type_declaration))
@@ -0,0 +1,22 @@
+; use declarations and paths of a .rs file
+;
+; @use                        use declaration e.g. use crate::db::{Pool, Config as Cfg};
+; @extern_crate               extern crate declaration e.g. extern crate clap as args;
+; @path_use                   qualified path e.g. db::Pool or serde::Serialize
+; @type_declaration           struct, enum or union at the top level of a module
+; @abstract_type_declaration  trait at the top level of a module
+
+(use_declaration) @use
+(extern_crate_declaration) @extern_crate
+(scoped_identifier) @path_use
+(scoped_type_identifier) @path_use
+(source_file
+  [(struct_item) (enum_item) (union_item)] @type_declaration)
+(mod_item
+  body: (declaration_list
+    [(struct_item) (enum_item) (union_item)] @type_declaration))
+(source_file
+  (trait_item) @abstract_type_declaration)
+(mod_item
+  body: (declaration_list
+    (trait_item) @abstract_type_declaration))
@@ -0,0 +1,5 @@
+; module declarations of a .rs file, followed to build the module tree of a crate
+;
+; @module  mod item, inline e.g. mod tests { } or declared e.g. mod db;
+
+(mod_item) @module
@@ -0,0 +1,39 @@
+; imports, member and qualified type references of a .ts, .mts, .cts or .tsx file
+; the javascript captures and the typescript only declarations and type references
+;
+; @import                     import statement e.g. import type { User } from "./models"
+; @export_from                re-export e.g. export * from "./db"
+; @dynamic_import             import() call with a literal specifier
+; @require_call               call of an identifier with a literal first argument e.g. require("./db")
+; @member_use                 member of an identifier e.g. ui.Button
+; @type_use                   qualified type e.g. models.User
+; @type_declaration           top level class declaration, exported or not
+; @abstract_type_declaration  top level abstract class or interface declaration, exported or not
+
+(import_statement) @import
+(export_statement
+  source: (string)) @export_from
+(call_expression
+  function: (import)
+  arguments: (arguments . (string))) @dynamic_import
+(call_expression
+  function: (identifier)
+  arguments: (arguments . (string))) @require_call
+(member_expression
+  object: (identifier)) @member_use
+(program
+  (class_declaration) @type_declaration)
+(program
+  (export_statement
+    declaration: (class_declaration) @type_declaration))
+(nested_type_identifier) @type_use
+(program
+  (abstract_class_declaration) @abstract_type_declaration)
+(program
+  (export_statement
+    declaration: (abstract_class_declaration) @abstract_type_declaration))
+(program
+  (interface_declaration) @abstract_type_declaration)
+(program
+  (export_statement
+    declaration: (interface_declaration) @abstract_type_declaration))
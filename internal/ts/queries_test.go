@@ -0,0 +1,121 @@
+package ts
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+	tsgo "github.com/tree-sitter/tree-sitter-go/bindings/go"
+	tsgomod "github.com/tree-sitter/tree-sitter-gomod/bindings/go"
+	tsjavascript "github.com/tree-sitter/tree-sitter-javascript/bindings/go"
+	tspython "github.com/tree-sitter/tree-sitter-python/bindings/go"
+	tsrust "github.com/tree-sitter/tree-sitter-rust/bindings/go"
+	tstypescript "github.com/tree-sitter/tree-sitter-typescript/bindings/go"
+)
+
+func TestEmbeddedQueries(t *testing.T) {
+	t.Parallel()
+
+	grammars := map[LanguageID]*treesitter.Language{
+		GO:     treesitter.NewLanguage(tsgo.Language()),
+		GOMOD:  treesitter.NewLanguage(tsgomod.Language()),
+		PYTHON: treesitter.NewLanguage(tspython.Language()),
+		JS:     treesitter.NewLanguage(tsjavascript.Language()),
+		TS:     treesitter.NewLanguage(tstypescript.LanguageTypescript()),
+		RUST:   treesitter.NewLanguage(tsrust.Language()),
+	}
+
+	for lang, queries := range contract {
+		for name, captures := range queries {
+			t.Run(string(lang)+"/"+string(name), func(t *testing.T) {
+				t.Parallel()
+
+				data, err := embedded.ReadFile("queries/" + string(lang) + "/" + string(name) + ".scm")
+				require.NoError(t, err)
+
+				q, qerr := treesitter.NewQuery(grammars[lang], string(data))
+				require.Nil(t, qerr)
+				defer q.Close()
+
+				// every capture of the contract is used and nothing else
+				require.ElementsMatch(t, captures, q.CaptureNames())
+			})
+		}
+	}
+}
+
+func TestLoadQuery(t *testing.T) {
+	tests := map[string]struct {
+		dir       fstest.MapFS
+		lang      LanguageID
+		name      QueryName
+		expected  string
+		expectErr error
+	}{
+		"embedded": {
+			lang:     GOMOD,
+			name:     ModuleQuery,
+			expected: "(module_directive (module_path) @module_path)\n",
+		},
+		"overridden": {
+			dir: fstest.MapFS{
+				"gomod/module.scm": {Data: []byte("(source_file (module_directive (module_path) @module_path))\n")},
+			},
+			lang:     GOMOD,
+			name:     ModuleQuery,
+			expected: "(source_file (module_directive (module_path) @module_path))\n",
+		},
+		"not overridden": {
+			dir: fstest.MapFS{
+				"go/analyze.scm": {Data: []byte("(package_identifier) @package\n")},
+			},
+			lang:     GOMOD,
+			name:     ModuleQuery,
+			expected: "(module_directive (module_path) @module_path)\n",
+		},
+		"private captures, comments and strings": {
+			dir: fstest.MapFS{
+				"rust/modules.scm": {Data: []byte(
+					"; @module is a mod item\n" +
+						"((mod_item name: (identifier) @_name) @module (#not-eq? @_name \"@tests\"))\n",
+				)},
+			},
+			lang:     RUST,
+			name:     ModulesQuery,
+			expected: "; @module is a mod item\n((mod_item name: (identifier) @_name) @module (#not-eq? @_name \"@tests\"))\n",
+		},
+		"unknown capture": {
+			dir: fstest.MapFS{
+				"rust/modules.scm": {Data: []byte("(mod_item) @mod\n")},
+			},
+			lang:      RUST,
+			name:      ModulesQuery,
+			expectErr: ErrUnknownCapture,
+		},
+		"unknown query": {
+			lang:      JSX,
+			name:      AnalyzeQuery,
+			expectErr: ErrQueryNotFound,
+		},
+	}
+
+	// the query directory is global so the cases cannot run in parallel
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tt.dir != nil {
+				SetQueryDir(tt.dir)
+				defer SetQueryDir(nil)
+			}
+
+			query, err := LoadQuery(tt.lang, tt.name)
+			if tt.expectErr != nil {
+				require.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Contains(t, query, tt.expected)
+		})
+	}
+}
@@ -6,19 +6,16 @@ import (
 	treesitter "github.com/tree-sitter/go-tree-sitter"
 )
 
+// Query compiles query for language, the query is closed by its caller
 func Query(
 	ctx context.Context,
-	parser *treesitter.Parser,
 	language *treesitter.Language,
-	tree *treesitter.Tree,
-	text []byte,
 	query string,
-) (*treesitter.Query, *treesitter.QueryCursor, error) {
+) (*treesitter.Query, error) {
 	q, err := treesitter.NewQuery(language, query)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	qc := treesitter.NewQueryCursor()
-	return q, qc, nil
+	return q, nil
 }
@@ -0,0 +1,77 @@
+// Package analyzer exposes the types shared by every analyzer of uda
+// so other modules can implement an analyzer for their own language or reuse the metrics of uda
+package analyzer
+
+import (
+	"context"
+	"io/fs"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer"
+)
+
+type (
+	// Package is a node of the analysis e.g. "github.com/f/uda/internal/analyzer" or "shop.api"
+	Package = analyzer.Package
+	// Import is a package as imported e.g. `"io/fs"`
+	Import = analyzer.Import
+	// PackageImports maps every package to its imports e.g. {"analyzer":["context","io/fs"]}
+	PackageImports = analyzer.PackageImports
+	// ImportSources maps every import of a package to the locations introducing it
+	ImportSources = analyzer.ImportSources
+	// Location is a line within a file relative to the analyzed directory
+	Location = analyzer.Location
+	// Origin classifies where an imported package comes from
+	Origin = analyzer.Origin
+	// ImportOrigins maps every import of a package to its origin
+	ImportOrigins = analyzer.ImportOrigins
+	// ImportPlatforms maps the imports introduced on some platforms only to the build constraints introducing them
+	ImportPlatforms = analyzer.ImportPlatforms
+	// TestImports maps every package to the imports only its tests introduce
+	TestImports = analyzer.TestImports
+	// Result is every analysis of a directory, the optional analyses are nil when the analyzer cannot tell
+	Result = analyzer.Result
+	// PackageLanguages maps every package to its language e.g. {"shop.api":"python"}
+	PackageLanguages = analyzer.PackageLanguages
+	// Metrics are the coupling metrics of a package
+	Metrics = analyzer.Metrics
+	// PackageCouplingStats counts the symbol uses of every package e.g. {"io/fs":{"fs.FS":{Count:1}}}
+	PackageCouplingStats = analyzer.PackageCouplingStats
+	// CouplingStats counts the uses of every symbol of a package
+	CouplingStats = analyzer.CouplingStats
+)
+
+type (
+	// Analyzer walks a directory and extracts its packages, imports and metrics
+	Analyzer = analyzer.Analyzer
+	// SourceAnalyzer is implemented by analyzers that can attribute imports to the files introducing them
+	SourceAnalyzer = analyzer.SourceAnalyzer
+	// OriginAnalyzer is implemented by analyzers that can classify the origin of imports
+	OriginAnalyzer = analyzer.OriginAnalyzer
+	// PlatformAnalyzer is implemented by analyzers that can tell on which platforms an import is introduced
+	PlatformAnalyzer = analyzer.PlatformAnalyzer
+	// TestAnalyzer is implemented by analyzers that can tell which imports are only introduced by tests
+	TestAnalyzer = analyzer.TestAnalyzer
+	// AllAnalyzer is implemented by analyzers that derive every analysis from a single walk of a directory
+	AllAnalyzer = analyzer.AllAnalyzer
+	// LanguageAnalyzer is implemented by analyzers that can tell the language of every package
+	LanguageAnalyzer = analyzer.LanguageAnalyzer
+)
+
+const (
+	Std        = analyzer.Std
+	FirstParty = analyzer.FirstParty
+	Workspace  = analyzer.Workspace
+	ThirdParty = analyzer.ThirdParty
+)
+
+// BuildMetrics derives the Metrics of every package from the symbols each package uses of the others
+// inward coupling is the inverse of the outward coupling so only packages present in outward can have any
+// e.g. BuildMetrics({"a":{"b":{"b.Do":{Count:2}}},"b":{}}) gives b a Ca of 1 and a a Ce of 1
+func BuildMetrics(outward map[Package]PackageCouplingStats) []Metrics {
+	return analyzer.BuildMetrics(outward)
+}
+
+// AnalyzeAll runs every analysis a implements on dir, in a single walk when a is an AllAnalyzer
+func AnalyzeAll(ctx context.Context, a Analyzer, dir fs.FS) (Result, error) {
+	return analyzer.AnalyzeAll(ctx, a, dir)
+}
@@ -0,0 +1,47 @@
+// Package files exposes the file listing used by the analyzers of uda
+package files
+
+import (
+	"context"
+	"io/fs"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/files"
+)
+
+// FileFilter reports whether a file or directory is skipped, both are given by their path in the listed directory
+type FileFilter = files.FileFilter
+
+// ListFiles walks dir and returns the path of every file no filter skips
+func ListFiles(ctx context.Context, dir fs.FS, filters ...FileFilter) ([]string, error) {
+	return files.ListFiles(ctx, dir, filters...)
+}
+
+// SkipHiddenDirs skips directories such as .git
+func SkipHiddenDirs() FileFilter {
+	return files.SkipHiddenDirs()
+}
+
+// SkipHiddenFiles skips files such as .env
+func SkipHiddenFiles() FileFilter {
+	return files.SkipHiddenFiles()
+}
+
+// SkipIgnored skips the files and directories ignored by the .gitignore and .udaignore files of dir
+func SkipIgnored(dir fs.FS) FileFilter {
+	return files.SkipIgnored(dir)
+}
+
+// Include skips the files matching none of patterns, doublestar globs e.g. "services/**"
+func Include(patterns ...string) FileFilter {
+	return files.Include(patterns...)
+}
+
+// Exclude skips the files and directories matching any of patterns, doublestar globs e.g. "**/*.pb.go"
+func Exclude(patterns ...string) FileFilter {
+	return files.Exclude(patterns...)
+}
+
+// ValidatePatterns reports the first pattern which is not a valid doublestar glob
+func ValidatePatterns(patterns ...string) error {
+	return files.ValidatePatterns(patterns...)
+}
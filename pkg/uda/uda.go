@@ -0,0 +1,131 @@
+// Package uda embeds the universal dependency analyzer in other tools
+// it analyzes polyglot directories and lets languages and output formats be added without forking
+package uda
+
+import (
+	"context"
+	"io/fs"
+
+	"github.com/flamingoosesoftwareinc/uda/internal/analyzer/golang"
+	"github.com/flamingoosesoftwareinc/uda/internal/dispatch"
+	"github.com/flamingoosesoftwareinc/uda/internal/report"
+	"github.com/flamingoosesoftwareinc/uda/internal/ts"
+	"github.com/flamingoosesoftwareinc/uda/pkg/analyzer"
+)
+
+type (
+	// LanguageID names a language in reports e.g. "go"
+	LanguageID = ts.LanguageID
+	// Language routes the files go-enry detects as one of its Detected languages to its analyzer
+	Language = dispatch.Language
+	// Option configures the analyzer of NewAnalyzer and Analyze
+	// the set of options is closed, options are only made by the With functions of this package
+	// taking a context rather than an Option e.g. WithJobs configure a single analysis
+	Option = dispatch.Option
+	// GoBuildContext selects the .go files of a platform from their build constraints and file name suffixes
+	GoBuildContext = golang.BuildContext
+)
+
+type (
+	// Report is the stable representation of an analysis, the same input always produces the same report
+	Report = report.Report
+	// Format is an output format of a report e.g. "json"
+	Format = report.Format
+	// Writer encodes a report in a format added with RegisterFormat
+	Writer = report.Writer
+)
+
+const (
+	Text = report.Text
+	JSON = report.JSON
+)
+
+var (
+	ErrUnsupportedFormat = report.ErrUnsupportedFormat
+	ErrFormatRegistered  = report.ErrFormatRegistered
+)
+
+// Analyzer is the analyzer of a polyglot directory, the results of every language are merged
+type Analyzer interface {
+	analyzer.Analyzer
+	analyzer.SourceAnalyzer
+	analyzer.OriginAnalyzer
+	analyzer.PlatformAnalyzer
+	analyzer.TestAnalyzer
+	analyzer.LanguageAnalyzer
+	analyzer.AllAnalyzer
+}
+
+// RegisterFormat adds an output format accepted by ParseFormat and Report.Write
+// e.g. RegisterFormat("csv", writeCSV) makes `uda metrics --format csv` available to a binary built on top of the cmd package
+func RegisterFormat(format Format, write Writer) error {
+	return report.RegisterFormat(format, write)
+}
+
+// ParseFormat validates a format given by a user
+func ParseFormat(format string) (Format, error) {
+	return report.ParseFormat(format)
+}
+
+// WithLanguages makes the analyzer analyze languages on top of the built in languages
+// a language replaces the built in language of the same ID for this analyzer only
+// e.g. WithLanguages(Language{ID: "dsl", Detected: []string{"HCL"}, New: newDSLAnalyzer})
+func WithLanguages(languages ...Language) Option {
+	return dispatch.WithLanguages(languages...)
+}
+
+// WithOrigins restricts the analysis of every language to imports of the given origins
+// e.g. WithOrigins(analyzer.FirstParty, analyzer.Workspace) ignores coupling to standard and third-party libraries
+func WithOrigins(origins ...analyzer.Origin) Option {
+	return dispatch.WithOrigins(origins...)
+}
+
+// WithInclude restricts the analysis to the source files matching any of patterns, doublestar globs
+// relative to the analyzed directory e.g. WithInclude("services/**")
+func WithInclude(patterns ...string) Option {
+	return dispatch.WithInclude(patterns...)
+}
+
+// WithExclude hides the files and directories matching any of patterns from the analysis
+// e.g. WithExclude("**/*.pb.go"), files ignored by .gitignore and .udaignore files are always hidden
+func WithExclude(patterns ...string) Option {
+	return dispatch.WithExclude(patterns...)
+}
+
+// WithGoBuildContext makes the analyses run with ctx only analyze the .go files built for a platform
+// e.g. WithGoBuildContext(ctx, GoBuildContext{GOOS: "windows"}), the platform uda runs on by default
+// _test.go files are only analyzed with GoBuildContext{Tests: true} and cgo files with CgoEnabled
+func WithGoBuildContext(ctx context.Context, b GoBuildContext) context.Context {
+	return golang.WithBuildContext(ctx, b)
+}
+
+// WithJobs sets how many files are parsed at once by the analyses run with ctx, GOMAXPROCS when jobs < 1
+func WithJobs(ctx context.Context, jobs int) context.Context {
+	return ts.WithJobs(ctx, jobs)
+}
+
+// WithCacheDir makes the analyses run with ctx keep what they extract from every file in dir
+// so that the next analysis only parses the files that changed, e.g. the directory of DefaultCacheDir
+func WithCacheDir(ctx context.Context, dir string) context.Context {
+	return ts.WithCache(ctx, ts.NewCache(dir))
+}
+
+// DefaultCacheDir is the cache directory of the uda command e.g. $XDG_CACHE_HOME/uda
+func DefaultCacheDir() (string, error) {
+	return ts.DefaultCacheDir()
+}
+
+// NewAnalyzer returns the analyzer routing every file of a directory to the analyzer of its language
+func NewAnalyzer(opts ...Option) Analyzer {
+	return dispatch.Dispatcher(opts...)
+}
+
+// Analyze analyzes dir and reports the imports, origins, platforms, tests, languages and metrics of every package
+func Analyze(ctx context.Context, dir fs.FS, opts ...Option) (Report, error) {
+	r, err := NewAnalyzer(opts...).AnalyzeAll(ctx, dir)
+	if err != nil {
+		return Report{}, err
+	}
+
+	return report.New(r.Imports, r.Metrics, r.Origins, nil, r.Languages, r.Platforms, r.Tests), nil
+}
@@ -0,0 +1,131 @@
+package uda_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/flamingoosesoftwareinc/uda/pkg/analyzer"
+	"github.com/flamingoosesoftwareinc/uda/pkg/files"
+	"github.com/flamingoosesoftwareinc/uda/pkg/uda"
+	"github.com/stretchr/testify/require"
+)
+
+// iniAnalyzer is an analyzer of an in-house language where every .ini file is a package
+// importing the packages of its `import = name` lines
+type iniAnalyzer struct{}
+
+func (iniAnalyzer) Analyze(ctx context.Context, dir fs.FS) (analyzer.PackageImports, error) {
+	outward, err := iniAnalyzer{}.outward(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pi := make(analyzer.PackageImports)
+	for pkg, deps := range outward {
+		pi[pkg] = []analyzer.Import{}
+		for dep := range deps {
+			pi[pkg] = append(pi[pkg], analyzer.Import(`"`+dep+`"`))
+		}
+	}
+
+	return pi, nil
+}
+
+func (iniAnalyzer) AnalyzeV2(ctx context.Context, dir fs.FS) ([]analyzer.Metrics, error) {
+	outward, err := iniAnalyzer{}.outward(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return analyzer.BuildMetrics(outward), nil
+}
+
+func (iniAnalyzer) outward(
+	ctx context.Context,
+	dir fs.FS,
+) (map[analyzer.Package]analyzer.PackageCouplingStats, error) {
+	filepaths, err := files.ListFiles(ctx, dir, files.SkipHiddenDirs(), files.SkipHiddenFiles())
+	if err != nil {
+		return nil, err
+	}
+
+	outward := make(map[analyzer.Package]analyzer.PackageCouplingStats)
+
+	for _, p := range filepaths {
+		if path.Ext(p) != ".ini" {
+			continue
+		}
+
+		content, err := fs.ReadFile(dir, p)
+		if err != nil {
+			return nil, err
+		}
+
+		pkg := analyzer.Package(strings.TrimSuffix(path.Base(p), ".ini"))
+		stats := make(analyzer.PackageCouplingStats)
+
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		for scanner.Scan() {
+			if dep, ok := strings.CutPrefix(scanner.Text(), "import = "); ok {
+				stats.Add(analyzer.Package(dep), dep+".config")
+			}
+		}
+
+		outward[pkg] = stats
+	}
+
+	return outward, nil
+}
+
+func TestWithLanguagesAndRegisterFormat(t *testing.T) {
+	ini := uda.WithLanguages(uda.Language{
+		ID:       "ini",
+		Detected: []string{"INI"},
+		New: func(...analyzer.Origin) analyzer.Analyzer {
+			return iniAnalyzer{}
+		},
+	})
+
+	require.NoError(t, uda.RegisterFormat("csv", func(w io.Writer, r uda.Report) error {
+		for _, p := range r.Packages {
+			if _, err := fmt.Fprintf(w, "%s,%s,%v\n", p.Package, p.Language, p.Metrics.Ce); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}))
+
+	dir := fstest.MapFS{
+		"services/billing.ini":  {Data: []byte("[service]\nimport = payments\n")},
+		"services/payments.ini": {Data: []byte("[service]\n")},
+	}
+
+	r, err := uda.Analyze(context.Background(), dir, ini)
+	require.NoError(t, err)
+
+	format, err := uda.ParseFormat("csv")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.Write(&buf, format))
+	require.Equal(t, "billing,ini,1\npayments,ini,0\n", buf.String())
+
+	// the language is only analyzed by the analyzer it was given to
+	r, err = uda.Analyze(context.Background(), dir)
+	require.NoError(t, err)
+	require.Empty(t, r.Packages)
+}
+
+func TestParseFormat(t *testing.T) {
+	_, err := uda.ParseFormat("yaml")
+	require.ErrorIs(t, err, uda.ErrUnsupportedFormat)
+}